@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/services/city"
+	"github.com/eat-only-in-season/backend/pkg/config"
+)
+
+const cacheUsage = `用法: server cache <命令> [选项]
+
+直接操作持久化缓存（CACHE_BACKEND，默认 SQLite 缓存文件 CACHE_SQLITE_PATH）。
+使用 SQLite 时，服务运行时清除的条目可能仍留在该进程的内存缓存中，直到内存 TTL
+过期，需要立即生效请使用管理接口；使用 Redis 时运行中的服务会收到失效通知。
+
+命令:
+  list  [-prefix 前缀] [-pattern 模式] [-city 城市] [-limit 数量]  列出缓存键、大小、变旧和过期时间
+  show  <键>                                                      查看缓存条目的值
+  purge [-prefix 前缀] [-pattern 模式] [-city 城市] [-dry-run]     清除符合条件的条目
+
+模式中 * 匹配任意字符，? 匹配单个字符，例如:
+  server cache purge -pattern '*:city=39.90/116.41:*'
+-city 按城市选择条目，城市先经地理编码换算为键中的坐标（也匹配以名称为键的条目），例如:
+  server cache purge -city 北京 -dry-run
+所有命令都支持 -backend 指定后端（sqlite 或 redis）、-db 指定缓存文件路径、
+-redis 指定 Redis 地址。
+`
+
+// runCacheCommand runs the cache subcommand and returns the process exit code
+func runCacheCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, cacheUsage)
+		return 2
+	}
+
+	cfg := config.Load().Cache
+	fs := flag.NewFlagSet("cache "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.Backend, "backend", cfg.Backend, "持久化缓存后端：sqlite 或 redis")
+	fs.StringVar(&cfg.SQLitePath, "db", cfg.SQLitePath, "SQLite 缓存文件路径")
+	fs.StringVar(&cfg.RedisURL, "redis", cfg.RedisURL, "Redis 地址")
+	prefix := fs.String("prefix", "", "缓存键前缀")
+	pattern := fs.String("pattern", "", "缓存键通配模式")
+	cityName := fs.String("city", "", "城市名称，选择该城市的条目")
+	limit := fs.Int("limit", 100, "最多列出的条目数，0 表示不限")
+	dryRun := fs.Bool("dry-run", false, "只列出将被清除的条目")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	// 不存在的文件不创建，避免路径写错时生成空的缓存库
+	if cfg.Backend == "" || cfg.Backend == cache.BackendSQLite {
+		if _, err := os.Stat(cfg.SQLitePath); err != nil {
+			fmt.Fprintf(stderr, "无法打开缓存文件 %s: %v\n", cfg.SQLitePath, err)
+			return 1
+		}
+	}
+	backend, err := cache.NewBackend(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "无法打开持久化缓存: %v\n", err)
+		return 1
+	}
+	defer backend.Close()
+
+	filter := cache.NewKeyFilter(*prefix, *pattern)
+	if *cityName != "" {
+		filter = filter.WithCities(city.KeyForms(context.Background(), *cityName)...)
+		if len(filter.Cities) == 0 {
+			fmt.Fprintln(stderr, "城市名称无效")
+			return 2
+		}
+		fmt.Fprintf(stdout, "城市在缓存键中的形式: %v\n", filter.Cities)
+	}
+	switch args[0] {
+	case "list":
+		entries, total, err := backend.List(filter, *limit, 0)
+		if err != nil {
+			fmt.Fprintf(stderr, "读取缓存失败: %v\n", err)
+			return 1
+		}
+		printEntries(stdout, entries)
+		fmt.Fprintf(stdout, "共 %d 条，显示 %d 条\n", total, len(entries))
+	case "show":
+		if fs.NArg() != 1 {
+			fmt.Fprintln(stderr, "用法: server cache show [-backend 后端] [-db 路径] [-redis 地址] <键>")
+			return 2
+		}
+		entry, ok := backend.Entry(fs.Arg(0))
+		if !ok {
+			fmt.Fprintln(stderr, "缓存条目不存在或已过期")
+			return 1
+		}
+		fmt.Fprintf(stdout, "键: %s\n大小: %d 字节\n创建: %s\n变旧: %s\n过期: %s\n\n",
+			entry.Key, entry.Size, entry.CreatedAt.Format(time.DateTime),
+			entry.StaleAt.Format(time.DateTime), entry.ExpiresAt.Format(time.DateTime))
+		printValue(stdout, entry.Value)
+	case "purge":
+		if filter.Empty() {
+			fmt.Fprintln(stderr, "请提供 -prefix、-pattern 或 -city，避免误删全部缓存")
+			return 2
+		}
+		if *dryRun {
+			entries, total, err := backend.List(filter, 0, 0)
+			if err != nil {
+				fmt.Fprintf(stderr, "读取缓存失败: %v\n", err)
+				return 1
+			}
+			printEntries(stdout, entries)
+			fmt.Fprintf(stdout, "将清除 %d 条未过期的条目\n", total)
+			return 0
+		}
+		keys, err := backend.Purge(filter)
+		if err != nil {
+			fmt.Fprintf(stderr, "清除缓存失败: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "已清除 %d 条\n", len(keys))
+	default:
+		fmt.Fprintf(stderr, "未知命令: %s\n\n%s", args[0], cacheUsage)
+		return 2
+	}
+	return 0
+}
+
+// printEntries prints cache entries as a table
+func printEntries(w io.Writer, entries []cache.EntryInfo) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tSIZE\tSTALE\tEXPIRES")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", e.Key, e.Size, e.StaleAt.Format(time.DateTime), e.ExpiresAt.Format(time.DateTime))
+	}
+	tw.Flush()
+}
+
+// printValue prints a cached value: JSON indented, other text as is, binary data by size only
+func printValue(w io.Writer, value []byte) {
+	var indented bytes.Buffer
+	switch {
+	case json.Indent(&indented, value, "", "  ") == nil:
+		fmt.Fprintln(w, indented.String())
+	case utf8.Valid(value):
+		fmt.Fprintln(w, string(value))
+	default:
+		fmt.Fprintf(w, "<%d 字节二进制数据>\n", len(value))
+	}
+}
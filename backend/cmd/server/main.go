@@ -11,12 +11,19 @@ import (
 	"github.com/eat-only-in-season/backend/internal/api"
 	"github.com/eat-only-in-season/backend/internal/cache"
 	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/store"
 	"github.com/eat-only-in-season/backend/pkg/config"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	// 缓存管理子命令：直接操作 SQLite 缓存文件，不启动服务
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		_ = godotenv.Load()
+		os.Exit(runCacheCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// 加载 .env 文件（如果存在）
 	if err := godotenv.Load(); err != nil {
 		log.Println("未找到 .env 文件，使用系统环境变量")
@@ -43,23 +50,30 @@ func main() {
 	if err := cache.InitDefaultManager(cfg.Cache); err != nil {
 		log.Fatalf("初始化缓存管理器失败: %v", err)
 	}
-	log.Printf("缓存管理器已初始化: 内存TTL=%v, SQLiteTTL=%v, 路径=%s",
-		cfg.Cache.MemoryTTL, cfg.Cache.SQLiteTTL, cfg.Cache.SQLitePath)
+	log.Printf("缓存管理器已初始化: 后端=%s, 内存TTL=%v, SQLiteTTL=%v, 路径=%s",
+		cache.DefaultManager.Stats().Backend, cfg.Cache.MemoryTTL, cfg.Cache.SQLiteTTL, cfg.Cache.SQLitePath)
+
+	// 初始化持久化存储（膳食计划等用户数据）
+	if err := store.InitDefault(cfg.StorePath); err != nil {
+		log.Fatalf("初始化持久化存储失败: %v", err)
+	}
+	log.Printf("持久化存储已初始化: 路径=%s", cfg.StorePath)
 
 	// 创建上下文用于优雅关闭
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 启动缓存预热 (从SQLite加载到内存)
+	// 启动缓存预热 (从持久化缓存加载到内存)
 	if err := cache.DefaultManager.WarmUp(); err != nil {
 		log.Printf("缓存预热警告: %v", err)
 	}
 
 	// 启动 SQLite 清理协程
 	go cache.DefaultManager.StartCleanupRoutine(ctx)
+	go store.Default.StartCleanupRoutine(ctx, cfg.RecommendationTTL)
 
 	// 创建路由
-	router := api.SetupRouter()
+	router, stopRouter := api.SetupRouter()
 
 	// 设置优雅关闭
 	go func() {
@@ -70,10 +84,18 @@ func main() {
 		log.Println("收到关闭信号，正在关闭...")
 		cancel()
 
+		// 先停止后台任务，避免其在缓存和存储关闭后写入
+		stopRouter()
+		log.Println("后台任务已停止")
+
 		if err := cache.DefaultManager.Close(); err != nil {
 			log.Printf("关闭缓存管理器失败: %v", err)
 		}
 		log.Println("缓存管理器已关闭")
+
+		if err := store.Default.Close(); err != nil {
+			log.Printf("关闭持久化存储失败: %v", err)
+		}
 		os.Exit(0)
 	}()
 
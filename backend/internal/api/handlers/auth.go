@@ -0,0 +1,188 @@
+// Package handlers provides HTTP handlers for user account and API key API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthHandler handles registration, login and API key requests
+type AuthHandler struct {
+	service *auth.Service
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(service *auth.Service) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// Register handles POST /api/v1/auth/register
+func (h *AuthHandler) Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	resp, err := h.service.Register(&req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// Login handles POST /api/v1/auth/login
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	resp, err := h.service.Login(&req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Me handles GET /api/v1/auth/me
+func (h *AuthHandler) Me(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	user, err := h.service.User(middleware.UserID(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ListAPIKeys handles GET /api/v1/auth/api-keys
+func (h *AuthHandler) ListAPIKeys(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(middleware.UserID(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListAPIKeysResponse{Keys: keys})
+}
+
+// CreateAPIKey handles POST /api/v1/auth/api-keys
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if !h.bind(c, &req) {
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(middleware.UserID(c), req.Name)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RevokeAPIKey handles DELETE /api/v1/auth/api-keys/:keyId
+func (h *AuthHandler) RevokeAPIKey(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(middleware.UserID(c), c.Param("keyId")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bind checks the service and parses the JSON request body; ok is false when an error
+// response has been written
+func (h *AuthHandler) bind(c *gin.Context, req any) bool {
+	if !h.available(c) {
+		return false
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// available writes an error response when the account service is not configured
+func (h *AuthHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "账号服务暂不可用，请稍后重试",
+		})
+		return false
+	}
+	return true
+}
+
+// writeError maps auth errors to HTTP responses
+func (h *AuthHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidUsername):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_USERNAME",
+			Message: "用户名须为 3-32 位字母、数字、下划线、点或连字符",
+		})
+	case errors.Is(err, auth.ErrPasswordTooLong):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "PASSWORD_TOO_LONG",
+			Message: "密码不能超过 72 字节（中文每字占 3 字节）",
+		})
+	case errors.Is(err, auth.ErrUsernameTaken):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "USERNAME_TAKEN",
+			Message: "用户名已被注册",
+		})
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "INVALID_CREDENTIALS",
+			Message: "用户名或密码错误",
+		})
+	case errors.Is(err, auth.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "USER_NOT_FOUND",
+			Message: "用户不存在",
+		})
+	case errors.Is(err, auth.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "API_KEY_NOT_FOUND",
+			Message: "API 密钥不存在",
+		})
+	case errors.Is(err, auth.ErrAPIKeysFull):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "API_KEYS_FULL",
+			Message: "API 密钥数量已达上限，请先吊销不再使用的密钥",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "AUTH_ERROR",
+			Message: "账号操作失败：" + err.Error(),
+		})
+	}
+}
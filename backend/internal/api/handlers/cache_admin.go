@@ -0,0 +1,166 @@
+// Package handlers provides HTTP handlers for cache administration API
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/city"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCacheKeysLimit 默认列出的缓存条目数
+const defaultCacheKeysLimit = 100
+
+// cacheKeysResponse 缓存条目列表
+type cacheKeysResponse struct {
+	Total   int               `json:"total"`
+	Entries []cache.EntryInfo `json:"entries"`
+}
+
+// cacheEntryResponse 缓存条目及其值，Encoding 为 json、text 或 base64
+type cacheEntryResponse struct {
+	cache.EntryInfo
+	Encoding string `json:"encoding"`
+	Value    any    `json:"value"`
+}
+
+// purgeCacheResponse 清除的缓存条目数，按城市清除时附带城市在键中的形式
+type purgeCacheResponse struct {
+	Purged int      `json:"purged"`
+	Cities []string `json:"cities,omitempty"`
+}
+
+// CacheAdminHandler handles cache administration requests (admin only)
+type CacheAdminHandler struct {
+	manager *cache.CacheManager
+}
+
+// NewCacheAdminHandler creates a new cache administration handler
+func NewCacheAdminHandler(manager *cache.CacheManager) *CacheAdminHandler {
+	return &CacheAdminHandler{manager: manager}
+}
+
+// ListKeys handles GET /api/v1/admin/cache/keys?prefix=&pattern=&city=&limit=&offset=
+// 按前缀、通配模式和/或城市列出缓存条目的大小和过期时间
+func (h *CacheAdminHandler) ListKeys(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	limit, ok := queryInt(c, "limit", 1)
+	if !ok {
+		return
+	}
+	offset, ok := queryInt(c, "offset", 0)
+	if !ok {
+		return
+	}
+	if limit == 0 {
+		limit = defaultCacheKeysLimit
+	}
+
+	filter := h.filter(c)
+	entries, total, err := h.manager.List(filter, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "CACHE_ERROR",
+			Message: "读取缓存失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, cacheKeysResponse{Total: total, Entries: entries})
+}
+
+// InspectEntry handles GET /api/v1/admin/cache/entry?key=
+// JSON 值原样返回，其他文本以字符串返回，二进制数据以 base64 返回
+func (h *CacheAdminHandler) InspectEntry(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	key := c.Query("key")
+	if key == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "MISSING_KEY",
+			Message: "请提供缓存键（key）",
+		})
+		return
+	}
+
+	entry, ok := h.manager.Inspect(key)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "CACHE_ENTRY_NOT_FOUND",
+			Message: "缓存条目不存在或已过期",
+		})
+		return
+	}
+
+	resp := cacheEntryResponse{EntryInfo: entry.EntryInfo}
+	switch {
+	case json.Valid(entry.Value):
+		resp.Encoding = "json"
+		resp.Value = json.RawMessage(entry.Value)
+	case utf8.Valid(entry.Value):
+		resp.Encoding = "text"
+		resp.Value = string(entry.Value)
+	default:
+		resp.Encoding = "base64"
+		resp.Value = base64.StdEncoding.EncodeToString(entry.Value)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// PurgeKeys handles DELETE /api/v1/admin/cache/keys?prefix=&pattern=&city=
+// 从内存和 SQLite 缓存中删除符合条件的条目，前缀、模式和城市至少提供一个
+func (h *CacheAdminHandler) PurgeKeys(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+	filter := h.filter(c)
+	if filter.Empty() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "MISSING_FILTER",
+			Message: "请提供要清除的缓存键前缀（prefix）、通配模式（pattern）或城市（city）",
+		})
+		return
+	}
+
+	purged, err := h.manager.Purge(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "CACHE_ERROR",
+			Message: "清除缓存失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, purgeCacheResponse{Purged: purged, Cities: filter.Cities})
+}
+
+// filter builds the key filter of a request; a city is looked up as in cache keys
+// (geocoded coordinates, or its name), so "北京" and "Beijing" select the same entries
+func (h *CacheAdminHandler) filter(c *gin.Context) cache.KeyFilter {
+	filter := cache.NewKeyFilter(c.Query("prefix"), c.Query("pattern"))
+	if name := c.Query("city"); name != "" {
+		filter = filter.WithCities(city.KeyForms(c.Request.Context(), name)...)
+	}
+	return filter
+}
+
+// available reports whether the cache manager is initialized;
+// it is false when an error response has been written
+func (h *CacheAdminHandler) available(c *gin.Context) bool {
+	if h.manager == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "缓存管理器未初始化",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+// Package handlers provides HTTP handlers for recipe ratings and feedback API
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/feedback"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// FeedbackHandler handles recipe rating and feedback requests
+type FeedbackHandler struct {
+	cache   *cache.Cache
+	store   *store.Store
+	service *feedback.Service
+}
+
+// NewFeedbackHandler creates a new feedback handler
+func NewFeedbackHandler(c *cache.Cache, st *store.Store, service *feedback.Service) *FeedbackHandler {
+	return &FeedbackHandler{cache: c, store: st, service: service}
+}
+
+// SubmitFeedback handles PUT /api/v1/recipes/:recipeId/feedback
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	var req models.RecipeFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	subject, ok := h.subject(c, c.Param("recipeId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在，请先获取菜谱推荐或详情",
+		})
+		return
+	}
+
+	result, err := h.service.Submit(userID, subject, &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetFeedback handles GET /api/v1/recipes/:recipeId/feedback
+// 返回汇总评分，识别到用户时附带该用户的评价
+func (h *FeedbackHandler) GetFeedback(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	result, err := h.service.Get(middleware.UserID(c), c.Param("recipeId"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DeleteFeedback handles DELETE /api/v1/recipes/:recipeId/feedback
+func (h *FeedbackHandler) DeleteFeedback(c *gin.Context) {
+	userID, ok := h.authenticatedUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(userID, c.Param("recipeId")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRatings handles GET /api/v1/recipes/:recipeId/ratings
+func (h *FeedbackHandler) GetRatings(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	ratings, err := h.service.Ratings(c.Param("recipeId"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ratings)
+}
+
+// ListFeedback handles GET /api/v1/feedback
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.List(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// subject resolves the recipe feedback is given on from its detail (cache or recipe
+// library), or from the context it was recommended in when no detail was generated
+func (h *FeedbackHandler) subject(c *gin.Context, recipeID string) (feedback.Subject, bool) {
+	if detail, ok := recipe.FindCachedDetail(h.cache, recipeID, i18n.GetLang(c)); ok {
+		return feedback.FromDetail(detail), true
+	}
+	if h.store == nil {
+		return feedback.Subject{}, false
+	}
+	rc, err := h.store.GetRecommendation(recipeID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[FeedbackHandler] 推荐上下文读取失败: %v", err)
+		}
+		return feedback.Subject{}, false
+	}
+	return feedback.FromContext(rc), true
+}
+
+// available writes an error response when the feedback service is not configured
+func (h *FeedbackHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "评价服务暂不可用，请稍后重试",
+		})
+		return false
+	}
+	return true
+}
+
+// user returns the identified user; ok is false when an error response has been written
+func (h *FeedbackHandler) user(c *gin.Context) (string, bool) {
+	if !h.available(c) {
+		return "", false
+	}
+
+	userID := middleware.UserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "MISSING_USER",
+			Message: "请提供用户标识（" + middleware.UserIDHeader + " 请求头）",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// authenticatedUser returns the logged-in user; feedback counts toward public ratings,
+// so it is keyed by registered users only. ok is false when an error response has been written
+func (h *FeedbackHandler) authenticatedUser(c *gin.Context) (string, bool) {
+	if !h.available(c) {
+		return "", false
+	}
+
+	if !middleware.Authenticated(c) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "UNAUTHENTICATED",
+			Message: "请先登录或提供 API 密钥（" + middleware.APIKeyHeader + " 请求头）",
+		})
+		return "", false
+	}
+	return middleware.UserID(c), true
+}
+
+// writeError maps feedback errors to HTTP responses
+func (h *FeedbackHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, feedback.ErrEmptyFeedback):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "EMPTY_FEEDBACK",
+			Message: "请至少提供评分、做过标记、不再推荐或备注中的一项",
+		})
+	case errors.Is(err, feedback.ErrFeedbackNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "FEEDBACK_NOT_FOUND",
+			Message: "尚未评价该菜谱",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "FEEDBACK_ERROR",
+			Message: "评价操作失败：" + err.Error(),
+		})
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
 	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/models"
 	"github.com/eat-only-in-season/backend/internal/services/ai/imagegen"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,13 +20,16 @@ import (
 type ImageHandler struct {
 	cache        *cache.Cache
 	imageService *imagegen.Service
+	// requireAuth 为 true 时仅登录用户可触发图片生成，已缓存的图片仍可匿名读取
+	requireAuth bool
 }
 
 // NewImageHandler creates a new image handler
-func NewImageHandler(c *cache.Cache, imageSvc *imagegen.Service) *ImageHandler {
+func NewImageHandler(c *cache.Cache, imageSvc *imagegen.Service, requireAuth bool) *ImageHandler {
 	return &ImageHandler{
 		cache:        c,
 		imageService: imageSvc,
+		requireAuth:  requireAuth,
 	}
 }
 
@@ -188,6 +194,15 @@ func (h *ImageHandler) GetRecipeImageUrl(c *gin.Context) {
 		return
 	}
 
+	// Generating a new image may be restricted to authenticated users
+	if h.requireAuth && !middleware.Authenticated(c) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "UNAUTHENTICATED",
+			Message: "图片生成仅限登录用户，请先登录或提供 API 密钥",
+		})
+		return
+	}
+
 	// Check if image service is available
 	if !h.imageService.IsAvailable() {
 		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
@@ -197,8 +212,8 @@ func (h *ImageHandler) GetRecipeImageUrl(c *gin.Context) {
 		return
 	}
 
-	// Get recipe detail from cache
-	recipeDetail, ok := h.cache.GetNewRecipeDetail(recipeID)
+	// Get recipe detail from cache or the recipe library
+	recipeDetail, ok := recipe.FindCachedDetail(h.cache, recipeID, i18n.GetLang(c))
 	if !ok {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Code:    "RECIPE_NOT_FOUND",
@@ -2,28 +2,46 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eat-only-in-season/backend/internal/cache"
 	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/city"
 	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/season"
+	"github.com/eat-only-in-season/backend/internal/services/seasonality"
 	"github.com/gin-gonic/gin"
 )
 
+// Season transition window limits (weeks)
+const (
+	defaultTransitionWeeks = 4
+	maxTransitionWeeks     = 26
+)
+
 // IngredientHandler handles ingredient-related requests
 type IngredientHandler struct {
-	cache   *cache.Cache
-	service *ingredient.Service
+	cache      *cache.Cache
+	service    *ingredient.Service
+	geocoder   *city.Geocoder
+	calculator *season.Calculator
 }
 
 // NewIngredientHandler creates a new ingredient handler
 func NewIngredientHandler(c *cache.Cache, service *ingredient.Service) *IngredientHandler {
 	return &IngredientHandler{
-		cache:   c,
-		service: service,
+		cache:      c,
+		service:    service,
+		geocoder:   city.NewGeocoder(c),
+		calculator: season.NewCalculator(),
 	}
 }
 
@@ -49,31 +67,14 @@ func (h *IngredientHandler) GetSeasonalIngredients(c *gin.Context) {
 	// Get language from context (set by i18n middleware)
 	lang := i18n.GetLang(c)
 
-	// 生成缓存键：语言+城市+月份
-	month := time.Now().Month()
-	cacheKey := cache.IngredientsKey(req.City, lang, int(month))
-
-	// 尝试从双层缓存获取
-	if cache.DefaultManager != nil {
-		var cached models.GetIngredientsResponse
-		if cache.DefaultManager.GetJSON(cacheKey, &cached) {
-			log.Printf("[IngredientHandler] 缓存命中: %s", cacheKey)
-			c.JSON(http.StatusOK, &cached)
-			return
-		}
-		log.Printf("[IngredientHandler] 缓存未命中: %s", cacheKey)
-	}
-
-	// Check if service is available
-	if h.service == nil {
+	result, err := ingredient.LoadSeasonalIngredients(c.Request.Context(), h.service, req.City, lang)
+	if errors.Is(err, ingredient.ErrServiceUnavailable) {
 		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
 			Code:    "SERVICE_UNAVAILABLE",
 			Message: "食材服务暂不可用，请稍后重试",
 		})
 		return
 	}
-
-	result, err := h.service.GetSeasonalIngredients(c.Request.Context(), req.City, lang)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    "GENERATION_FAILED",
@@ -82,14 +83,10 @@ func (h *IngredientHandler) GetSeasonalIngredients(c *gin.Context) {
 		return
 	}
 
-	// 存入双层缓存
-	if cache.DefaultManager != nil {
-		if err := cache.DefaultManager.SetJSON(cacheKey, result); err != nil {
-			log.Printf("[IngredientHandler] 缓存写入失败: %v", err)
-		} else {
-			log.Printf("[IngredientHandler] 缓存写入成功: %s", cacheKey)
-		}
-	}
+	// 按城市当地日期标注食材所处的应季阶段（缓存中保存的是未标注的数据）
+	local := h.localTime(c, req.City)
+	result.Location.LocalDate = local.Format("2006-01-02")
+	seasonality.AnnotatePhases(result.Categories, local)
 
 	c.JSON(http.StatusOK, result)
 }
@@ -119,12 +116,18 @@ func (h *IngredientHandler) GetIngredientDetail(c *gin.Context) {
 	lang := i18n.GetLang(c)
 
 	// 生成缓存键（包含语言）
-	cacheKey := cache.IngredientDetailKey(ingredientID) + ":" + lang
+	cacheKey := cache.IngredientDetailKey(ingredientID, lang)
 
-	// 尝试从双层缓存获取
+	// 尝试从双层缓存获取，变旧的详情在后台重新生成
 	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if h.service != nil {
+			refresh = func(ctx context.Context) (any, error) {
+				return h.service.GetIngredientDetail(ctx, ingredientID, ingredientName, lang)
+			}
+		}
 		var cached models.SeasonalIngredient
-		if cache.DefaultManager.GetJSON(cacheKey, &cached) {
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
 			log.Printf("[IngredientHandler] 食材详情缓存命中: %s", cacheKey)
 			c.JSON(http.StatusOK, models.GetIngredientDetailResponse{
 				Ingredient: cached,
@@ -165,3 +168,124 @@ func (h *IngredientHandler) GetIngredientDetail(c *gin.Context) {
 		Ingredient: *result,
 	})
 }
+
+// GetSeasonalCalendar handles GET /api/v1/calendar?city=
+func (h *IngredientHandler) GetSeasonalCalendar(c *gin.Context) {
+	cityName := strings.TrimSpace(c.Query("city"))
+	if cityName == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "MISSING_CITY",
+			Message: "请输入城市名称",
+		})
+		return
+	}
+
+	result, err := ingredient.LoadSeasonalCalendar(c.Request.Context(), h.service, cityName, i18n.GetLang(c))
+	if err != nil {
+		h.writeCalendarError(c, err, "获取应季日历失败：")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetIngredientCalendar handles GET /api/v1/ingredients/:id/calendar?city=&name=
+func (h *IngredientHandler) GetIngredientCalendar(c *gin.Context) {
+	ingredientID := c.Param("id")
+	cityName := strings.TrimSpace(c.Query("city"))
+	if cityName == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "MISSING_CITY",
+			Message: "请输入城市名称",
+		})
+		return
+	}
+
+	// 名称可选：未提供时根据 ID 在该城市当月的应季食材中查找
+	name := strings.TrimSpace(c.Query("name"))
+
+	result, err := ingredient.LoadIngredientCalendar(c.Request.Context(), h.service, ingredientID, name, cityName, i18n.GetLang(c))
+	if err != nil {
+		h.writeCalendarError(c, err, "获取食材应季日历失败：")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// writeCalendarError maps calendar errors to HTTP responses
+func (h *IngredientHandler) writeCalendarError(c *gin.Context, err error, prefix string) {
+	switch {
+	case errors.Is(err, ingredient.ErrIngredientNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "INGREDIENT_NOT_FOUND",
+			Message: "未找到该食材，请提供食材名称",
+		})
+	case errors.Is(err, ingredient.ErrServiceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "食材服务暂不可用，请稍后重试",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "GENERATION_FAILED",
+			Message: prefix + err.Error(),
+		})
+	}
+}
+
+// GetSeasonTransitions handles GET /api/v1/ingredients/transitions?city=&weeks=
+func (h *IngredientHandler) GetSeasonTransitions(c *gin.Context) {
+	cityName := strings.TrimSpace(c.Query("city"))
+	if cityName == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "MISSING_CITY",
+			Message: "请输入城市名称",
+		})
+		return
+	}
+
+	weeks := defaultTransitionWeeks
+	if v := c.Query("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTransitionWeeks {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_WEEKS",
+				Message: fmt.Sprintf("weeks 必须是 1-%d 之间的整数", maxTransitionWeeks),
+			})
+			return
+		}
+		weeks = n
+	}
+
+	calendar, err := ingredient.LoadSeasonalCalendar(c.Request.Context(), h.service, cityName, i18n.GetLang(c))
+	if err != nil {
+		h.writeCalendarError(c, err, "获取应季变化失败：")
+		return
+	}
+
+	local := h.localTime(c, cityName)
+	location := calendar.Location
+	location.Month = int(local.Month())
+	location.LocalDate = local.Format("2006-01-02")
+
+	arriving, leaving := seasonality.Transitions(calendar.Ingredients, local, weeks)
+	c.JSON(http.StatusOK, models.GetSeasonTransitionsResponse{
+		Location: location,
+		Weeks:    weeks,
+		Arriving: arriving,
+		Leaving:  leaving,
+	})
+}
+
+// localTime returns the current local time of a city, falling back to the server time
+// when the city cannot be geocoded
+func (h *IngredientHandler) localTime(c *gin.Context, cityName string) time.Time {
+	now := time.Now()
+	info, err := h.geocoder.SearchCity(c.Request.Context(), cityName)
+	if err != nil {
+		log.Printf("[IngredientHandler] 无法定位城市 %s，使用服务器时间: %v", cityName, err)
+		return now
+	}
+	return h.calculator.LocalTime(info, now)
+}
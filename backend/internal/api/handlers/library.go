@@ -0,0 +1,200 @@
+// Package handlers provides HTTP handlers for favourites and viewing history API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/library"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/gin-gonic/gin"
+)
+
+// LibraryHandler handles favourite recipe and viewing history requests
+type LibraryHandler struct {
+	cache   *cache.Cache
+	service *library.Service
+}
+
+// NewLibraryHandler creates a new library handler
+func NewLibraryHandler(c *cache.Cache, service *library.Service) *LibraryHandler {
+	return &LibraryHandler{cache: c, service: service}
+}
+
+// ListFavourites handles GET /api/v1/favourites
+func (h *LibraryHandler) ListFavourites(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	favourites, err := h.service.ListFavourites(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListFavouritesResponse{Favourites: favourites})
+}
+
+// AddFavourite handles POST /api/v1/favourites
+func (h *LibraryHandler) AddFavourite(c *gin.Context) {
+	var req models.AddFavouriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	lang := i18n.GetLang(c)
+	detail, ok := recipe.FindCachedDetail(h.cache, req.RecipeID, lang)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在或已过期，请先获取菜谱详情",
+		})
+		return
+	}
+
+	favourite, err := h.service.AddFavourite(userID, detail, req.Note, lang)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, favourite)
+}
+
+// GetFavourite handles GET /api/v1/favourites/:recipeId
+func (h *LibraryHandler) GetFavourite(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.GetFavourite(userID, c.Param("recipeId"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveFavourite handles DELETE /api/v1/favourites/:recipeId
+func (h *LibraryHandler) RemoveFavourite(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveFavourite(userID, c.Param("recipeId")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListHistory handles GET /api/v1/history?limit=
+func (h *LibraryHandler) ListHistory(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_LIMIT",
+				Message: "limit 必须为正整数",
+			})
+			return
+		}
+		limit = n
+	}
+
+	history, err := h.service.History(userID, limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListHistoryResponse{History: history})
+}
+
+// ClearHistory handles DELETE /api/v1/history
+func (h *LibraryHandler) ClearHistory(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ClearHistory(userID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// user returns the identified user; ok is false when an error response has been written
+func (h *LibraryHandler) user(c *gin.Context) (string, bool) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "菜谱库服务暂不可用，请稍后重试",
+		})
+		return "", false
+	}
+
+	userID := middleware.UserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "MISSING_USER",
+			Message: "请提供用户标识（" + middleware.UserIDHeader + " 请求头）",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps library errors to HTTP responses
+func (h *LibraryHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, library.ErrFavouriteNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "FAVOURITE_NOT_FOUND",
+			Message: "该菜谱不在收藏中",
+		})
+	case errors.Is(err, library.ErrRecipeNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在",
+		})
+	case errors.Is(err, library.ErrFavouritesFull):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "FAVOURITES_FULL",
+			Message: "收藏数量已达上限",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "LIBRARY_ERROR",
+			Message: "菜谱库操作失败：" + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package handlers provides HTTP handlers for meal plan API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/planner"
+	"github.com/gin-gonic/gin"
+)
+
+// MealPlanHandler handles meal plan requests
+type MealPlanHandler struct {
+	service  *planner.Service
+	shopping *ShoppingListHandler
+}
+
+// NewMealPlanHandler creates a new meal plan handler
+func NewMealPlanHandler(service *planner.Service, shopping *ShoppingListHandler) *MealPlanHandler {
+	return &MealPlanHandler{
+		service:  service,
+		shopping: shopping,
+	}
+}
+
+// CreateMealPlan handles POST /api/v1/meal-plans
+func (h *MealPlanHandler) CreateMealPlan(c *gin.Context) {
+	var req models.CreateMealPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	if !h.available(c) {
+		return
+	}
+
+	plan, err := h.service.Generate(c.Request.Context(), middleware.UserID(c), &req, i18n.GetLang(c))
+	if err != nil {
+		h.writeError(c, err, "生成膳食计划失败：")
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// GetMealPlan handles GET /api/v1/meal-plans/:planId
+func (h *MealPlanHandler) GetMealPlan(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	plan, err := h.service.Get(middleware.UserID(c), c.Param("planId"))
+	if err != nil {
+		h.writeError(c, err, "获取膳食计划失败：")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// DeleteMealPlan handles DELETE /api/v1/meal-plans/:planId
+func (h *MealPlanHandler) DeleteMealPlan(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	if err := h.service.Delete(middleware.UserID(c), c.Param("planId")); err != nil {
+		h.writeError(c, err, "删除膳食计划失败：")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SwapMeal handles POST /api/v1/meal-plans/:planId/swap
+func (h *MealPlanHandler) SwapMeal(c *gin.Context) {
+	var req models.SwapMealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	if !h.available(c) {
+		return
+	}
+
+	plan, err := h.service.SwapMeal(c.Request.Context(), middleware.UserID(c), c.Param("planId"), req.Date, req.Slot, i18n.GetLang(c))
+	if err != nil {
+		h.writeError(c, err, "替换菜谱失败：")
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// GetShoppingList handles GET /api/v1/meal-plans/:planId/shopping-list
+func (h *MealPlanHandler) GetShoppingList(c *gin.Context) {
+	format, ok := parseShoppingListFormat(c, "")
+	if !ok {
+		return
+	}
+
+	if !h.available(c) {
+		return
+	}
+
+	lang := i18n.GetLang(c)
+	list, err := h.service.ShoppingList(c.Request.Context(), middleware.UserID(c), c.Param("planId"), lang)
+	if err != nil {
+		h.writeError(c, err, "生成购物清单失败：")
+		return
+	}
+
+	h.shopping.writeShoppingList(c, list, format, lang)
+}
+
+// available writes a 503 when the planner is not configured
+func (h *MealPlanHandler) available(c *gin.Context) bool {
+	if h.service != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Code:    "SERVICE_UNAVAILABLE",
+		Message: "膳食计划服务暂不可用，请稍后重试",
+	})
+	return false
+}
+
+// writeError maps planner errors to HTTP responses
+func (h *MealPlanHandler) writeError(c *gin.Context, err error, prefix string) {
+	switch {
+	case errors.Is(err, planner.ErrInvalidRequest):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+	case errors.Is(err, planner.ErrPlanNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "PLAN_NOT_FOUND",
+			Message: "膳食计划不存在",
+		})
+	case errors.Is(err, planner.ErrMealNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "MEAL_NOT_FOUND",
+			Message: "计划中不存在该日期的这一餐",
+		})
+	case errors.Is(err, planner.ErrPlanConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "PLAN_CONFLICT",
+			Message: "膳食计划已被修改，请刷新后重试",
+		})
+	case errors.Is(err, planner.ErrNoCandidates):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Code:    "NO_CANDIDATES",
+			Message: "没有可替换的菜谱，请调整偏好后重试",
+		})
+	case errors.Is(err, planner.ErrServiceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "菜谱服务暂不可用，请稍后重试",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "GENERATION_FAILED",
+			Message: prefix + err.Error(),
+		})
+	}
+}
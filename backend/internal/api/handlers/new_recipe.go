@@ -2,29 +2,67 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"sort"
 	"strings"
+	"time"
 
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
 	"github.com/eat-only-in-season/backend/internal/cache"
 	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/city"
+	"github.com/eat-only-in-season/backend/internal/services/cuisine"
+	"github.com/eat-only-in-season/backend/internal/services/embedding"
+	"github.com/eat-only-in-season/backend/internal/services/feedback"
+	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/leftover"
+	"github.com/eat-only-in-season/backend/internal/services/library"
+	"github.com/eat-only-in-season/backend/internal/services/pantry"
 	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/timing"
+	"github.com/eat-only-in-season/backend/internal/store"
 	"github.com/gin-gonic/gin"
 )
 
+// dedupeTimeout 推荐去重时嵌入推荐菜谱的超时时间，超时后保留全部推荐
+const dedupeTimeout = 5 * time.Second
+
 // NewFlowRecipeHandler handles new recipe-related requests (003-flow-redesign)
 type NewFlowRecipeHandler struct {
-	cache   *cache.Cache
-	service *recipe.Service
+	cache             *cache.Cache
+	service           *recipe.Service
+	pantry            *pantry.Service
+	ingredientService *ingredient.Service
+	store             *store.Store
+	prefetcher        *recipe.Prefetcher
+	library           *library.Service
+	feedback          *feedback.Service
+	embedding         *embedding.Service
 }
 
-// NewNewFlowRecipeHandler creates a new recipe handler for the new flow
-func NewNewFlowRecipeHandler(c *cache.Cache, service *recipe.Service) *NewFlowRecipeHandler {
+// NewNewFlowRecipeHandler creates a new recipe handler for the new flow.
+// The store, prefetcher, library, feedback and embedding services are optional; without
+// them recommendation contexts are not persisted, details are not prefetched, viewed
+// recipes are not kept, recommendations are not personalized and near-duplicate
+// recommendations are not removed.
+func NewNewFlowRecipeHandler(c *cache.Cache, service *recipe.Service, pantrySvc *pantry.Service, ingredientService *ingredient.Service, st *store.Store, prefetcher *recipe.Prefetcher, librarySvc *library.Service, feedbackSvc *feedback.Service, embeddingSvc *embedding.Service) *NewFlowRecipeHandler {
 	return &NewFlowRecipeHandler{
-		cache:   c,
-		service: service,
+		cache:             c,
+		service:           service,
+		pantry:            pantrySvc,
+		ingredientService: ingredientService,
+		store:             st,
+		prefetcher:        prefetcher,
+		library:           librarySvc,
+		feedback:          feedbackSvc,
+		embedding:         embeddingSvc,
 	}
 }
 
@@ -38,18 +76,51 @@ func (h *NewFlowRecipeHandler) GetRecipesByIngredients(c *gin.Context) {
 		})
 		return
 	}
+	if !recipe.ValidSort(req.SortBy) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_SORT",
+			Message: "不支持的排序方式：" + string(req.SortBy),
+		})
+		return
+	}
+
+	req.Leftovers = leftover.Normalize(req.Leftovers)
+	if !h.resolveCuisine(c, &req) {
+		return
+	}
 
 	// Get language from context
 	lang := i18n.GetLang(c)
 
-	// 生成缓存键：语言 + 排序后的食材列表 + 偏好
-	cacheKey := h.buildRecipesCacheKey(req.Ingredients, req.Preference, lang)
+	// 结合库存推荐时读取用户的可用库存
+	var opts recipe.RecommendOptions
+	if req.UsePantry {
+		items, ok := h.loadPantry(c)
+		if !ok {
+			return
+		}
+		opts.Pantry = items
+	}
+	opts.Feedback = h.loadFeedbackProfile(c)
 
-	// 尝试从双层缓存获取
+	// 生成缓存键：语言 + 食材 + 偏好 + 过敏原 + 时长上限 + 菜系（当地菜系含规范化的城市） + 剩余食材 + 库存 + 口味偏好
+	declared := allergen.Normalize(req.Allergens)
+	cacheKey := h.buildRecipesCacheKey(c, &req, declared, opts, lang)
+
+	// 尝试从双层缓存获取，变旧的推荐在后台重新生成
 	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if h.service != nil {
+			refreshReq := req
+			refresh = func(ctx context.Context) (any, error) {
+				return h.service.GetRecipeRecommendations(ctx, &refreshReq, opts, lang)
+			}
+		}
 		var cached models.GetRecipesByIngredientsResponse
-		if cache.DefaultManager.GetJSON(cacheKey, &cached) {
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
 			log.Printf("[RecipeHandler] 菜谱推荐缓存命中: %s", cacheKey)
+			h.rank(c, &cached, &req, opts.Feedback, lang)
+			h.afterRecommend(c, &cached, &req, lang)
 			c.JSON(http.StatusOK, &cached)
 			return
 		}
@@ -65,7 +136,7 @@ func (h *NewFlowRecipeHandler) GetRecipesByIngredients(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.GetRecipeRecommendations(c.Request.Context(), &req, lang)
+	result, err := h.service.GetRecipeRecommendations(c.Request.Context(), &req, opts, lang)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    "GENERATION_FAILED",
@@ -83,21 +154,242 @@ func (h *NewFlowRecipeHandler) GetRecipesByIngredients(c *gin.Context) {
 		}
 	}
 
+	// 缓存保存原始结果，应季评分、过滤和排序在返回前进行（评分随月份变化）
+	h.rank(c, result, &req, opts.Feedback, lang)
+	h.afterRecommend(c, result, &req, lang)
 	c.JSON(http.StatusOK, result)
 }
 
+// resolveCuisine normalizes the preferred cuisine of the request to a cuisine code;
+// "local" is resolved from the city and kept as is when the city is not in the table.
+// ok is false when an error response has been written
+func (h *NewFlowRecipeHandler) resolveCuisine(c *gin.Context, req *models.GetRecipesByIngredientsRequest) bool {
+	name := strings.TrimSpace(req.Cuisine)
+	if name == "" {
+		req.Cuisine = ""
+		return true
+	}
+
+	if strings.EqualFold(name, string(models.CuisineLocal)) {
+		if strings.TrimSpace(req.Location) == "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_CUISINE",
+				Message: "使用当地菜系时需要提供城市",
+			})
+			return false
+		}
+		req.Cuisine = string(models.CuisineLocal)
+		if code, ok := cuisine.Local(req.Location); ok {
+			req.Cuisine = string(code)
+		}
+		return true
+	}
+
+	code, ok := cuisine.Normalize(name)
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_CUISINE",
+			Message: "不支持的菜系：" + name,
+			Details: map[string]any{"supported": cuisine.All()},
+		})
+		return false
+	}
+	req.Cuisine = string(code)
+	return true
+}
+
+// rank drops the dishes the user rejected and scores the rest against the user's taste
+// profile, scores their seasonality against the seasonal ingredients of the requested
+// city, then filters and sorts them and drops near-duplicates of better-ranked dishes.
+// Without a city (or when the seasonal list cannot be loaded) only the user's selection
+// counts as in season.
+func (h *NewFlowRecipeHandler) rank(c *gin.Context, resp *models.GetRecipesByIngredientsResponse, req *models.GetRecipesByIngredientsRequest, profile *models.FeedbackProfile, lang string) {
+	month := int(time.Now().Month())
+	var seasonal []models.SeasonalIngredient
+	if city := strings.TrimSpace(req.Location); city != "" {
+		result, err := ingredient.LoadSeasonalIngredients(c.Request.Context(), h.ingredientService, city, lang)
+		if err != nil {
+			log.Printf("[RecipeHandler] 获取应季食材失败，仅按所选食材计算应季评分: %v", err)
+		} else {
+			seasonal = ingredient.Flatten(result)
+			if result.Location.Month > 0 {
+				month = result.Location.Month
+			}
+		}
+	}
+	recipe.ApplyFeedback(resp, profile)
+	recipe.Rank(resp, req, seasonal, month)
+	if h.embedding != nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), dedupeTimeout)
+		if err := h.embedding.Dedupe(ctx, resp); err != nil {
+			log.Printf("[RecipeHandler] 推荐去重失败，保留全部推荐: %v", err)
+		}
+		cancel()
+	}
+	if models.Cuisine(req.Cuisine) != models.CuisineLocal {
+		resp.Cuisine = models.Cuisine(req.Cuisine)
+	}
+}
+
+// afterRecommend persists the context of every recommended recipe for detail generation
+// and schedules background generation of the details of the top recipes, superseding the
+// prefetches of the user's previous recommendation. It runs on cache hits too, so the
+// seasonal ingredients reflect the latest ranking; unchanged contexts are not rewritten.
+func (h *NewFlowRecipeHandler) afterRecommend(c *gin.Context, resp *models.GetRecipesByIngredientsResponse, req *models.GetRecipesByIngredientsRequest, lang string) {
+	if h.store == nil && h.prefetcher.TopN() == 0 {
+		return
+	}
+	contexts := recipe.Contexts(resp, req, lang)
+	if h.store != nil {
+		if err := h.store.SaveRecommendations(contexts); err != nil {
+			log.Printf("[RecipeHandler] 推荐上下文保存失败: %v", err)
+		}
+	}
+
+	// 预取使用与 GetNewRecipeDetail 相同的选项，确保缓存键一致
+	if h.prefetcher.TopN() > 0 {
+		jobs := make([]recipe.PrefetchJob, 0, len(contexts))
+		for i := range contexts {
+			rc := &contexts[i]
+			jobs = append(jobs, recipe.PrefetchJob{
+				RecipeID: rc.RecipeID,
+				Title:    rc.Title,
+				Options:  recipe.ContextOptions(rc),
+				Lang:     lang,
+			})
+		}
+		h.prefetcher.Enqueue(middleware.UserID(c), jobs)
+	}
+}
+
+// loadContext returns the context a recipe was recommended in, nil if unknown
+func (h *NewFlowRecipeHandler) loadContext(recipeID string) *models.RecommendationContext {
+	if h.store == nil {
+		return nil
+	}
+	rc, err := h.store.GetRecommendation(recipeID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[RecipeHandler] 推荐上下文读取失败: %v", err)
+		}
+		return nil
+	}
+	return rc
+}
+
 // buildRecipesCacheKey 生成菜谱推荐缓存键
-func (h *NewFlowRecipeHandler) buildRecipesCacheKey(ingredients []string, preference string, lang string) string {
-	// 排序食材以保证一致性
-	sorted := make([]string, len(ingredients))
-	copy(sorted, ingredients)
-	sort.Strings(sorted)
+func (h *NewFlowRecipeHandler) buildRecipesCacheKey(c *gin.Context, req *models.GetRecipesByIngredientsRequest, allergens []models.Allergen, opts recipe.RecommendOptions, lang string) string {
+	key := cache.NewKey(cache.NamespaceRecipes).
+		Field("lang", lang).
+		List("ingredients", req.Ingredients).
+		Field("preference", req.Preference).
+		List("allergens", allergenCodes(allergens)).
+		Int("maxTime", req.MaxCookingMinutes).
+		Field("cuisine", req.Cuisine)
+	if models.Cuisine(req.Cuisine) == models.CuisineLocal {
+		key.Field("city", city.Canonical(c.Request.Context(), req.Location))
+	}
+	if len(req.Leftovers) > 0 {
+		key.Field("leftovers", leftover.Fingerprint(req.Leftovers))
+	}
+	if len(opts.Pantry) > 0 {
+		key.Field("pantry", pantryFingerprint(opts.Pantry))
+	}
+	if opts.Feedback != nil {
+		key.Field("feedback", feedbackFingerprint(opts.Feedback))
+	}
+	return key.String()
+}
+
+// loadPantry returns the usable pantry items of the identified user;
+// ok is false when an error response has been written
+func (h *NewFlowRecipeHandler) loadPantry(c *gin.Context) ([]models.PantryItem, bool) {
+	userID := middleware.UserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "MISSING_USER",
+			Message: "结合库存推荐需要提供用户标识（" + middleware.UserIDHeader + " 请求头）",
+		})
+		return nil, false
+	}
+	if h.pantry == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "库存服务暂不可用，请稍后重试",
+		})
+		return nil, false
+	}
+
+	items, err := h.pantry.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "PANTRY_LOAD_FAILED",
+			Message: "读取库存失败：" + err.Error(),
+		})
+		return nil, false
+	}
+	return pantry.Usable(items), true
+}
+
+// loadFeedbackProfile returns the taste profile of the identified user, nil for anonymous
+// requests, users without feedback or when it cannot be loaded
+func (h *NewFlowRecipeHandler) loadFeedbackProfile(c *gin.Context) *models.FeedbackProfile {
+	userID := middleware.UserID(c)
+	if userID == "" || h.feedback == nil {
+		return nil
+	}
+	profile, err := h.feedback.Profile(userID)
+	if err != nil {
+		log.Printf("[RecipeHandler] 读取口味偏好失败，不做个性化推荐: %v", err)
+		return nil
+	}
+	return profile
+}
 
-	key := lang + ":recipes:" + strings.Join(sorted, ",")
-	if preference != "" {
-		key += ":" + preference
+// feedbackFingerprint 口味偏好的摘要，评价改变了偏好时缓存失效
+func feedbackFingerprint(profile *models.FeedbackProfile) string {
+	data, _ := json.Marshal(profile)
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// pantryFingerprint 库存内容及当天日期的摘要，库存变化或跨天（临期天数变化）时缓存失效
+func pantryFingerprint(items []models.PantryItem) string {
+	h := sha1.New()
+	h.Write([]byte(time.Now().Format("2006-01-02")))
+	for _, item := range items {
+		h.Write([]byte("|" + item.Name + ":" + item.Quantity + ":" + item.ExpiresOn))
 	}
-	return key
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// allergenCodes 返回过敏原代码，用于缓存键
+func allergenCodes(allergens []models.Allergen) []string {
+	codes := make([]string, 0, len(allergens))
+	for _, a := range allergens {
+		codes = append(codes, string(a))
+	}
+	return codes
+}
+
+// mergeAllergens 合并两组过敏原并按固定顺序去重
+func mergeAllergens(a, b []models.Allergen) []models.Allergen {
+	values := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]models.Allergen{a, b} {
+		for _, v := range list {
+			values = append(values, string(v))
+		}
+	}
+	return allergen.Normalize(values)
+}
+
+// parseAllergensQuery 解析查询参数中的过敏原，支持 ?allergens=peanut,shellfish 与重复参数两种写法
+func parseAllergensQuery(c *gin.Context) []models.Allergen {
+	var values []string
+	for _, v := range c.QueryArray("allergens") {
+		values = append(values, strings.Split(v, ",")...)
+	}
+	return allergen.Normalize(values)
 }
 
 // GetNewRecipeDetail handles GET /api/v1/recipes/:id/detail
@@ -111,8 +403,19 @@ func (h *NewFlowRecipeHandler) GetNewRecipeDetail(c *gin.Context) {
 		return
 	}
 
-	// Get recipe title from query param
+	// 推荐时的上下文（食材、城市、偏好、过敏原、菜系），不存在时仅按标题生成
+	rc := h.loadContext(recipeID)
+
+	// 用户编辑过（或从分享复制）的菜谱返回其个人版本，不重新生成
+	if h.userRecipe(c, recipeID, rc) {
+		return
+	}
+
+	// Get recipe title from query param, falling back to the recommendation
 	recipeTitle := c.Query("title")
+	if recipeTitle == "" && rc != nil {
+		recipeTitle = rc.Title
+	}
 	if recipeTitle == "" {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{
 			Code:    "MISSING_TITLE",
@@ -124,22 +427,58 @@ func (h *NewFlowRecipeHandler) GetNewRecipeDetail(c *gin.Context) {
 	// Get language from context
 	lang := i18n.GetLang(c)
 
-	// 生成缓存键（包含语言）
-	cacheKey := lang + ":" + cache.RecipeDetailKey(recipeID)
+	// 用户声明的过敏原，合并推荐时声明的过敏原
+	opts := recipe.ContextOptions(rc)
+	declared := mergeAllergens(parseAllergensQuery(c), opts.Allergens)
+	opts.Allergens = declared
+
+	// 菜系（可选，默认沿用推荐时的菜系），详情保持相同风格
+	if name := strings.TrimSpace(c.Query("cuisine")); name != "" {
+		code, ok := cuisine.Normalize(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_CUISINE",
+				Message: "不支持的菜系：" + name,
+			})
+			return
+		}
+		opts.Cuisine = code
+	}
+
+	// 生成缓存键（包含语言、菜系和过敏原）
+	cacheKey := recipe.DetailCacheKey(recipeID, declared, opts.Cuisine, lang)
 
 	// 尝试从双层缓存获取
 	if cache.DefaultManager != nil {
 		var cached models.NewRecipeDetail
 		if cache.DefaultManager.GetJSON(cacheKey, &cached) {
 			log.Printf("[RecipeHandler] 菜谱详情缓存命中: %s", cacheKey)
+			timing.Annotate(&cached)
+			h.recordView(c, &cached, lang)
 			c.JSON(http.StatusOK, models.GetNewRecipeDetailResponse{
-				Recipe: cached,
+				Recipe:           cached,
+				CheckedAllergens: declared,
 			})
 			return
 		}
 		log.Printf("[RecipeHandler] 菜谱详情缓存未命中: %s", cacheKey)
 	}
 
+	// 缓存过期后从菜谱库读取，已保存的版本含过敏原或菜系不同时重新生成
+	if h.library != nil {
+		if stored, err := h.library.Get(recipeID); err == nil && len(allergen.DetailConflicts(stored, declared)) == 0 &&
+			(opts.Cuisine == "" || stored.Cuisine == opts.Cuisine) {
+			log.Printf("[RecipeHandler] 菜谱详情从菜谱库读取: %s", recipeID)
+			timing.Annotate(stored)
+			h.recordView(c, stored, lang)
+			c.JSON(http.StatusOK, models.GetNewRecipeDetailResponse{
+				Recipe:           *stored,
+				CheckedAllergens: declared,
+			})
+			return
+		}
+	}
+
 	// Check if service is available
 	if h.service == nil {
 		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
@@ -149,7 +488,22 @@ func (h *NewFlowRecipeHandler) GetNewRecipeDetail(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.GetRecipeDetail(c.Request.Context(), recipeID, recipeTitle, lang)
+	// 后台正在预取时附加到进行中的生成上，生成结果由服务写入缓存；
+	// 仍在排队的预取由本次请求直接生成，从队列中移除
+	h.prefetcher.Cancel(recipeID, opts, lang)
+	result, err := h.service.LoadRecipeDetail(c.Request.Context(), h.cache, recipeID, recipeTitle, opts, lang)
+	var conflictErr *recipe.AllergenConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Code:    "ALLERGEN_CONFLICT",
+			Message: "无法生成不含过敏原的菜谱，请选择其他菜谱",
+			Details: map[string]any{
+				"conflicts":        conflictErr.Conflicts,
+				"checkedAllergens": declared,
+			},
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    "GENERATION_FAILED",
@@ -158,19 +512,48 @@ func (h *NewFlowRecipeHandler) GetNewRecipeDetail(c *gin.Context) {
 		return
 	}
 
-	// 存入双层缓存
-	if cache.DefaultManager != nil {
-		if err := cache.DefaultManager.SetJSON(cacheKey, result); err != nil {
-			log.Printf("[RecipeHandler] 菜谱详情缓存写入失败: %v", err)
-		} else {
-			log.Printf("[RecipeHandler] 菜谱详情缓存写入成功: %s", cacheKey)
+	h.recordView(c, result, lang)
+	c.JSON(http.StatusOK, models.GetNewRecipeDetailResponse{
+		Recipe:           *result,
+		CheckedAllergens: declared,
+	})
+}
+
+// userRecipe responds with the user's edited version of a recipe if there is one
+func (h *NewFlowRecipeHandler) userRecipe(c *gin.Context, recipeID string, rc *models.RecommendationContext) bool {
+	userID := middleware.UserID(c)
+	if h.store == nil || userID == "" {
+		return false
+	}
+	edited, detail, err := h.store.GetUserRecipe(userID, recipeID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[RecipeHandler] 读取用户编辑的菜谱失败: %v", err)
 		}
+		return false
 	}
 
-	// 同时存入内存缓存（保持向后兼容）
-	h.cache.SetNewRecipeDetail(recipeID, result)
-
+	declared := mergeAllergens(parseAllergensQuery(c), recipe.ContextOptions(rc).Allergens)
 	c.JSON(http.StatusOK, models.GetNewRecipeDetailResponse{
-		Recipe: *result,
+		Recipe:           *detail,
+		CheckedAllergens: declared,
+		Revision:         edited.Revision,
+		Conflicts:        allergen.DetailConflicts(detail, declared),
 	})
+	return true
+}
+
+// recordView keeps the viewed recipe in the library and adds it to the user's history
+func (h *NewFlowRecipeHandler) recordView(c *gin.Context, detail *models.NewRecipeDetail, lang string) {
+	if h.library == nil {
+		return
+	}
+	if err := h.library.RecordView(middleware.UserID(c), detail, lang); err != nil {
+		log.Printf("[RecipeHandler] 浏览记录保存失败: %v", err)
+	}
+}
+
+// ListCuisines handles GET /api/v1/cuisines
+func (h *NewFlowRecipeHandler) ListCuisines(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ListCuisinesResponse{Cuisines: cuisine.List(i18n.GetLang(c))})
 }
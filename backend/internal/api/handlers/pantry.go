@@ -0,0 +1,150 @@
+// Package handlers provides HTTP handlers for pantry API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/pantry"
+	"github.com/gin-gonic/gin"
+)
+
+// PantryHandler handles pantry inventory requests
+type PantryHandler struct {
+	service *pantry.Service
+}
+
+// NewPantryHandler creates a new pantry handler
+func NewPantryHandler(service *pantry.Service) *PantryHandler {
+	return &PantryHandler{service: service}
+}
+
+// ListItems handles GET /api/v1/pantry
+func (h *PantryHandler) ListItems(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.service.List(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ListPantryResponse{Items: items})
+}
+
+// CreateItem handles POST /api/v1/pantry
+func (h *PantryHandler) CreateItem(c *gin.Context) {
+	var req models.CreatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.service.Create(userID, &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateItem handles PUT /api/v1/pantry/:itemId
+func (h *PantryHandler) UpdateItem(c *gin.Context) {
+	var req models.UpdatePantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.service.Update(userID, c.Param("itemId"), &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteItem handles DELETE /api/v1/pantry/:itemId
+func (h *PantryHandler) DeleteItem(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(userID, c.Param("itemId")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// user returns the identified user; ok is false when an error response has been written
+func (h *PantryHandler) user(c *gin.Context) (string, bool) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "库存服务暂不可用，请稍后重试",
+		})
+		return "", false
+	}
+
+	userID := middleware.UserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "MISSING_USER",
+			Message: "请提供用户标识（" + middleware.UserIDHeader + " 请求头）",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps pantry errors to HTTP responses
+func (h *PantryHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, pantry.ErrInvalidItem):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+	case errors.Is(err, pantry.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "PANTRY_ITEM_NOT_FOUND",
+			Message: "库存食材不存在",
+		})
+	case errors.Is(err, pantry.ErrPantryFull):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "PANTRY_FULL",
+			Message: "库存食材数量已达上限",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "PANTRY_ERROR",
+			Message: "库存操作失败：" + err.Error(),
+		})
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
 	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/models"
 	"github.com/eat-only-in-season/backend/internal/services/pdf"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
 	"github.com/gin-gonic/gin"
 )
 
@@ -62,8 +65,8 @@ func (h *PDFHandler) ExportPDF(c *gin.Context) {
 		req.IncludeImage = true
 	}
 
-	// Try new flow cache first (003-flow-redesign)
-	if newDetail, ok := h.cache.GetNewRecipeDetail(recipeID); ok {
+	// Try the user's edited version, then new flow cache (003-flow-redesign), then the recipe library
+	if newDetail, ok := recipe.FindUserDetail(h.cache, middleware.UserID(c), recipeID, i18n.GetLang(c)); ok {
 		// Try to get image data
 		var imageData []byte
 		if req.IncludeImage {
@@ -87,7 +90,7 @@ func (h *PDFHandler) ExportPDF(c *gin.Context) {
 	}
 
 	// Fall back to old flow cache
-	recipe, ok := h.cache.GetSingleRecipe(recipeID)
+	oldRecipe, ok := h.cache.GetSingleRecipe(recipeID)
 	if !ok {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Code:    "RECIPE_NOT_FOUND",
@@ -98,7 +101,7 @@ func (h *PDFHandler) ExportPDF(c *gin.Context) {
 
 	// Update image from cache
 	if base64Image, ok := h.cache.GetImageBase64(recipeID); ok {
-		recipe.ImageBase64 = base64Image
+		oldRecipe.ImageBase64 = base64Image
 	}
 
 	// Get detail from cache
@@ -112,7 +115,7 @@ func (h *PDFHandler) ExportPDF(c *gin.Context) {
 	}
 
 	// Generate PDF
-	pdfBase64, fileName, err := h.pdfService.GenerateRecipePDF(recipe, detail, req.IncludeImage)
+	pdfBase64, fileName, err := h.pdfService.GenerateRecipePDF(oldRecipe, detail, req.IncludeImage)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Code:    "PDF_GENERATION_FAILED",
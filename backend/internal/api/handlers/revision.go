@@ -0,0 +1,296 @@
+// Package handlers provides HTTP handlers for recipe editing and revision history API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/revision"
+	"github.com/eat-only-in-season/backend/internal/services/share"
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionHandler handles recipe editing, revision history and fork requests
+type RevisionHandler struct {
+	cache   *cache.Cache
+	service *revision.Service
+	share   *share.Service
+}
+
+// NewRevisionHandler creates a new revision handler
+func NewRevisionHandler(c *cache.Cache, service *revision.Service, shareSvc *share.Service) *RevisionHandler {
+	return &RevisionHandler{cache: c, service: service, share: shareSvc}
+}
+
+// EditRecipe handles PUT /api/v1/recipes/:recipeId
+// 首次编辑生成的菜谱时保存原始版本（修订 1），每次编辑新增一个修订
+func (h *RevisionHandler) EditRecipe(c *gin.Context) {
+	var req models.EditRecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	recipeID := c.Param("recipeId")
+	lang := i18n.GetLang(c)
+	original, ok := recipe.FindUserDetail(h.cache, userID, recipeID, lang)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在或已过期，请先获取菜谱详情",
+		})
+		return
+	}
+
+	result, err := h.service.Edit(userID, original, lang, &req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ForkSharedRecipe handles POST /api/v1/shared/:slug/fork
+// 将分享的菜谱复制为用户的个人菜谱（新的菜谱 ID），之后可自由编辑
+func (h *RevisionHandler) ForkSharedRecipe(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+	if h.share == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "分享服务暂不可用，请稍后重试",
+		})
+		return
+	}
+
+	snapshot, err := h.share.Get(c.Param("slug"))
+	if errors.Is(err, share.ErrSnapshotNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "SHARED_RECIPE_NOT_FOUND",
+			Message: "分享的菜谱不存在",
+		})
+		return
+	}
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	result, err := h.service.Fork(userID, snapshot)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// ListUserRecipes handles GET /api/v1/my-recipes
+func (h *RevisionHandler) ListUserRecipes(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.List(userID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DiscardUserRecipe handles DELETE /api/v1/my-recipes/:recipeId
+// 删除个人版本及其修订历史，编辑过的生成菜谱恢复为生成的版本
+func (h *RevisionHandler) DiscardUserRecipe(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Discard(userID, c.Param("recipeId")); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListRevisions handles GET /api/v1/recipes/:recipeId/revisions
+func (h *RevisionHandler) ListRevisions(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Revisions(userID, c.Param("recipeId"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetRevision handles GET /api/v1/recipes/:recipeId/revisions/:revision
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+	rev, ok := parseRevision(c, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Revision(userID, c.Param("recipeId"), rev)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DiffRevisions handles GET /api/v1/recipes/:recipeId/diff?from=&to=
+// 默认比较当前修订与上一修订
+func (h *RevisionHandler) DiffRevisions(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	var from, to int
+	if value := c.Query("from"); value != "" {
+		if from, ok = parseRevision(c, value); !ok {
+			return
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, ok = parseRevision(c, value); !ok {
+			return
+		}
+	}
+
+	result, err := h.service.Diff(userID, c.Param("recipeId"), from, to)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RestoreRevision handles POST /api/v1/recipes/:recipeId/revisions/:revision/restore
+// 以该修订的内容创建新的修订，历史保持不变
+func (h *RevisionHandler) RestoreRevision(c *gin.Context) {
+	userID, ok := h.user(c)
+	if !ok {
+		return
+	}
+	rev, ok := parseRevision(c, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Restore(userID, c.Param("recipeId"), rev)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// parseRevision parses a revision number; ok is false when an error response has been written
+func parseRevision(c *gin.Context, value string) (int, bool) {
+	rev, err := strconv.Atoi(value)
+	if err != nil || rev < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REVISION",
+			Message: "修订号无效：" + value,
+		})
+		return 0, false
+	}
+	return rev, true
+}
+
+// user returns the identified user; ok is false when an error response has been written
+func (h *RevisionHandler) user(c *gin.Context) (string, bool) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "菜谱编辑服务暂不可用，请稍后重试",
+		})
+		return "", false
+	}
+
+	userID := middleware.UserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Code:    "MISSING_USER",
+			Message: "请提供用户标识（" + middleware.UserIDHeader + " 请求头）",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps revision errors to HTTP responses
+func (h *RevisionHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, revision.ErrRecipeNotEdited):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_EDITED",
+			Message: "尚未编辑或复制该菜谱",
+		})
+	case errors.Is(err, revision.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "REVISION_NOT_FOUND",
+			Message: "修订不存在",
+		})
+	case errors.Is(err, revision.ErrRevisionConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "REVISION_CONFLICT",
+			Message: "菜谱已被修改，请基于最新修订重新编辑",
+		})
+	case errors.Is(err, revision.ErrRevisionsFull):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Code:    "REVISIONS_FULL",
+			Message: "修订数量已达上限",
+		})
+	case errors.Is(err, revision.ErrNoChanges):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "NO_CHANGES",
+			Message: "内容与当前修订相同",
+		})
+	case errors.Is(err, revision.ErrInvalidRecipe):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_RECIPE",
+			Message: "菜谱需要标题、至少一种食材和一个步骤，食材名称和步骤说明不能为空",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "REVISION_ERROR",
+			Message: "菜谱编辑操作失败：" + err.Error(),
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package handlers provides HTTP handlers for recipe search API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/cuisine"
+	"github.com/eat-only-in-season/backend/internal/services/search"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSearchQueryLength 搜索词的最大长度（字符）
+const maxSearchQueryLength = 100
+
+// SearchHandler handles recipe search requests
+type SearchHandler struct {
+	service *search.Service
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(service *search.Service) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// SearchRecipes handles GET /api/v1/recipes/search?q=&cuisine=&difficulty=&maxMinutes=&season=&limit=&offset=
+// 搜索菜谱库中保存和生成过的菜谱，支持中文和英文
+func (h *SearchHandler) SearchRecipes(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "搜索服务暂不可用，请稍后重试",
+		})
+		return
+	}
+
+	q := search.Query{Text: strings.TrimSpace(c.Query("q"))}
+	if q.Text == "" || len([]rune(q.Text)) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_QUERY",
+			Message: "请提供搜索词（q，最多 100 个字符）",
+		})
+		return
+	}
+
+	if name := strings.TrimSpace(c.Query("cuisine")); name != "" {
+		code, ok := cuisine.Normalize(name)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_CUISINE",
+				Message: "不支持的菜系：" + name,
+			})
+			return
+		}
+		q.Cuisine = code
+	}
+	if v := c.Query("difficulty"); v != "" {
+		code, ok := search.ParseDifficulty(v)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_DIFFICULTY",
+				Message: "difficulty 必须为 easy、medium 或 hard",
+			})
+			return
+		}
+		q.Difficulty = code
+	}
+	if v := c.Query("season"); v != "" {
+		id, ok := search.ParseSeason(v)
+		if !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:    "INVALID_SEASON",
+				Message: "season 必须为 spring、summer、autumn 或 winter",
+			})
+			return
+		}
+		q.Season = id
+	}
+
+	var ok bool
+	if q.MaxMinutes, ok = queryInt(c, "maxMinutes", 1); !ok {
+		return
+	}
+	if q.Limit, ok = queryInt(c, "limit", 1); !ok {
+		return
+	}
+	if q.Offset, ok = queryInt(c, "offset", 0); !ok {
+		return
+	}
+
+	result, err := h.service.Search(q)
+	if errors.Is(err, search.ErrEmptyQuery) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_QUERY",
+			Message: "搜索词中没有可搜索的文字",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "SEARCH_FAILED",
+			Message: "搜索菜谱失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// queryInt parses an optional integer query parameter of at least minValue (0 when
+// absent); ok is false when an error response has been written
+func queryInt(c *gin.Context, name string, minValue int) (int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < minValue {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_PARAMETER",
+			Message: name + " 必须为不小于 " + strconv.Itoa(minValue) + " 的整数",
+		})
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,160 @@
+// Package handlers provides HTTP handlers for shared recipe snapshot API
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/share"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// immutableCacheControl 快照发布后不再变化，可长期缓存
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// ShareHandler handles publishing and reading shared recipe snapshots
+type ShareHandler struct {
+	cache   *cache.Cache
+	store   *store.Store
+	service *share.Service
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(c *cache.Cache, st *store.Store, service *share.Service) *ShareHandler {
+	return &ShareHandler{cache: c, store: st, service: service}
+}
+
+// PublishRecipe handles POST /api/v1/recipes/:recipeId/publish
+// 将菜谱详情、图片与推荐上下文冻结为快照；内容未变时返回已有快照（200）
+func (h *ShareHandler) PublishRecipe(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	recipeID := c.Param("recipeId")
+	lang := i18n.GetLang(c)
+	detail, ok := recipe.FindUserDetail(h.cache, middleware.UserID(c), recipeID, lang)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在或已过期，请先获取菜谱详情",
+		})
+		return
+	}
+
+	var rc *models.RecommendationContext
+	if h.store != nil {
+		var err error
+		rc, err = h.store.GetRecommendation(recipeID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[ShareHandler] 推荐上下文读取失败: %v", err)
+		}
+	}
+
+	snapshot, created, err := h.service.Publish(detail, rc, h.image(c, recipeID), lang, middleware.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "PUBLISH_FAILED",
+			Message: "发布菜谱失败：" + err.Error(),
+		})
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, snapshot)
+}
+
+// GetSharedRecipe handles GET /api/v1/shared/:slug
+func (h *ShareHandler) GetSharedRecipe(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	snapshot, err := h.service.Get(c.Param("slug"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", immutableCacheControl)
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// GetSharedImage handles GET /api/v1/shared/:slug/image
+func (h *ShareHandler) GetSharedImage(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	img, err := h.service.Image(c.Param("slug"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", immutableCacheControl)
+	c.Data(http.StatusOK, img.ContentType, img.Data)
+}
+
+// image returns the generated image of a recipe to be frozen into its snapshot, nil when
+// none has been generated or it cannot be loaded (the recipe is published without it)
+func (h *ShareHandler) image(c *gin.Context, recipeID string) *share.Image {
+	if encoded, ok := h.cache.GetImageBase64(recipeID); ok && encoded != "" {
+		img, err := share.DecodeImage(encoded)
+		if err == nil {
+			return img
+		}
+		log.Printf("[ShareHandler] 缓存图片解析失败: %v", err)
+	}
+	if url, ok := h.cache.GetImageURL(recipeID); ok && url != "" {
+		img, err := h.service.FetchImage(c.Request.Context(), url)
+		if err == nil {
+			return img
+		}
+		log.Printf("[ShareHandler] 下载菜谱图片失败，发布不含图片的快照: %v", err)
+	}
+	return nil
+}
+
+// available writes an error response when the share service is not configured
+func (h *ShareHandler) available(c *gin.Context) bool {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "分享服务暂不可用，请稍后重试",
+		})
+		return false
+	}
+	return true
+}
+
+// writeError maps share errors to HTTP responses
+func (h *ShareHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, share.ErrSnapshotNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "SHARED_RECIPE_NOT_FOUND",
+			Message: "分享的菜谱不存在",
+		})
+	case errors.Is(err, share.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "IMAGE_NOT_FOUND",
+			Message: "分享的菜谱没有图片",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "SHARE_ERROR",
+			Message: "读取分享的菜谱失败：" + err.Error(),
+		})
+	}
+}
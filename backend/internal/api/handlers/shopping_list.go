@@ -0,0 +1,165 @@
+// Package handlers provides HTTP handlers for shopping list API
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/pdf"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/shopping"
+	"github.com/gin-gonic/gin"
+)
+
+// ShoppingListHandler handles shopping list requests
+type ShoppingListHandler struct {
+	cache             *cache.Cache
+	service           *shopping.Service
+	pdfService        *pdf.Service
+	ingredientService *ingredient.Service
+}
+
+// NewShoppingListHandler creates a new shopping list handler
+func NewShoppingListHandler(c *cache.Cache, service *shopping.Service, pdfSvc *pdf.Service, ingredientService *ingredient.Service) *ShoppingListHandler {
+	return &ShoppingListHandler{
+		cache:             c,
+		service:           service,
+		pdfService:        pdfSvc,
+		ingredientService: ingredientService,
+	}
+}
+
+// CreateShoppingList handles POST /api/v1/shopping-list
+// @Summary 生成购物清单
+// @Description 合并多道菜谱的食材，按超市区域分组，支持 JSON、纯文本和 PDF 导出
+// @Tags shopping
+// @Accept json
+// @Produce json,plain
+// @Param request body models.CreateShoppingListRequest true "菜谱与份数"
+// @Success 200 {object} models.ShoppingList
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /shopping-list [post]
+func (h *ShoppingListHandler) CreateShoppingList(c *gin.Context) {
+	var req models.CreateShoppingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	format, ok := parseShoppingListFormat(c, req.Format)
+	if !ok {
+		return
+	}
+
+	lang := i18n.GetLang(c)
+	// 用户编辑过的菜谱按其个人版本计算
+	userID := middleware.UserID(c)
+
+	// 收集菜谱详情，同一菜谱重复出现时用量倍数相加（未指定份数的一次按 1 倍计）
+	inputs := make([]shopping.RecipeInput, 0, len(req.Recipes))
+	index := make(map[string]int)
+	var missing []string
+	for _, r := range req.Recipes {
+		if i, ok := index[r.ID]; ok {
+			in := &inputs[i]
+			if in.Scale == 0 {
+				in.Scale = shopping.ScaleFor(in.Detail, in.Servings)
+			}
+			in.Scale += shopping.ScaleFor(in.Detail, r.Servings)
+			continue
+		}
+		detail, ok := recipe.FindUserDetail(h.cache, userID, r.ID, lang)
+		if !ok {
+			if !containsID(missing, r.ID) {
+				missing = append(missing, r.ID)
+			}
+			continue
+		}
+		index[r.ID] = len(inputs)
+		inputs = append(inputs, shopping.RecipeInput{Detail: detail, Servings: r.Servings})
+	}
+
+	if len(inputs) == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在或已过期，请先查看菜谱详情",
+			Details: map[string]any{"missingRecipes": missing},
+		})
+		return
+	}
+
+	// 应季标记：城市可选，获取失败时不影响清单生成
+	var seasonal []string
+	if city := strings.TrimSpace(req.City); city != "" {
+		result, err := ingredient.LoadSeasonalIngredients(c.Request.Context(), h.ingredientService, city, lang)
+		if err != nil {
+			log.Printf("[ShoppingListHandler] 获取应季食材失败，跳过应季标记: %v", err)
+		} else {
+			seasonal = ingredient.Names(result)
+		}
+	}
+
+	list := h.service.Build(inputs, seasonal, lang)
+	list.MissingRecipes = missing
+
+	h.writeShoppingList(c, list, format, lang)
+}
+
+// parseShoppingListFormat returns the requested export format, falling back to the
+// format query parameter; ok is false (and a 400 has been written) for unknown formats
+func parseShoppingListFormat(c *gin.Context, format models.ShoppingListFormat) (models.ShoppingListFormat, bool) {
+	if format == "" {
+		format = models.ShoppingListFormat(c.DefaultQuery("format", string(models.ShoppingListFormatJSON)))
+	}
+	switch format {
+	case models.ShoppingListFormatJSON, models.ShoppingListFormatText, models.ShoppingListFormatPDF:
+		return format, true
+	}
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Code:    "INVALID_FORMAT",
+		Message: "不支持的导出格式，可选值：json、text、pdf",
+	})
+	return "", false
+}
+
+// writeShoppingList writes a shopping list in the requested format
+func (h *ShoppingListHandler) writeShoppingList(c *gin.Context, list *models.ShoppingList, format models.ShoppingListFormat, lang string) {
+	switch format {
+	case models.ShoppingListFormatText:
+		c.String(http.StatusOK, h.service.FormatText(list, lang))
+	case models.ShoppingListFormatPDF:
+		pdfBase64, fileName, err := h.pdfService.GenerateShoppingListPDF(list, lang)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Code:    "PDF_GENERATION_FAILED",
+				Message: "生成 PDF 失败: " + err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusOK, models.ExportPDFResponse{
+			FileName:  fileName,
+			PDFBase64: pdfBase64,
+		})
+	default:
+		c.JSON(http.StatusOK, list)
+	}
+}
+
+func containsID(ids []string, id string) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+// Package handlers provides HTTP handlers for similar recipe API
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/embedding"
+	"github.com/gin-gonic/gin"
+)
+
+// SimilarHandler handles similar recipe requests
+type SimilarHandler struct {
+	service *embedding.Service
+}
+
+// NewSimilarHandler creates a new similar recipe handler
+func NewSimilarHandler(service *embedding.Service) *SimilarHandler {
+	return &SimilarHandler{service: service}
+}
+
+// GetSimilarRecipes handles GET /api/v1/recipes/:recipeId/similar?limit=
+// 按嵌入向量的余弦相似度返回菜谱库中相似的菜谱，近似重复的菜谱会被标记
+func (h *SimilarHandler) GetSimilarRecipes(c *gin.Context) {
+	if h.service == nil {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "相似菜谱服务暂不可用，请稍后重试",
+		})
+		return
+	}
+
+	limit, ok := queryInt(c, "limit", 1)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Similar(c.Request.Context(), c.Param("recipeId"), limit)
+	if errors.Is(err, embedding.ErrRecipeNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱库中没有该菜谱，请先获取菜谱详情",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Code:    "SIMILAR_FAILED",
+			Message: "获取相似菜谱失败：" + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
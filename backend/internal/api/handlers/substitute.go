@@ -0,0 +1,233 @@
+// Package handlers provides HTTP handlers for ingredient substitution API
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/api/middleware"
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/services/city"
+	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/gin-gonic/gin"
+)
+
+// SubstituteHandler handles ingredient substitution requests
+type SubstituteHandler struct {
+	cache             *cache.Cache
+	store             *store.Store
+	service           *recipe.Service
+	ingredientService *ingredient.Service
+}
+
+// NewSubstituteHandler creates a new substitute handler
+func NewSubstituteHandler(c *cache.Cache, st *store.Store, service *recipe.Service, ingredientService *ingredient.Service) *SubstituteHandler {
+	return &SubstituteHandler{
+		cache:             c,
+		store:             st,
+		service:           service,
+		ingredientService: ingredientService,
+	}
+}
+
+// GetSubstitutes handles GET /api/v1/ingredients/:id/substitutes?recipeId=&name=&city=
+func (h *SubstituteHandler) GetSubstitutes(c *gin.Context) {
+	lang := i18n.GetLang(c)
+	cityName := strings.TrimSpace(c.Query("city"))
+
+	var detail *models.NewRecipeDetail
+	if recipeID := c.Query("recipeId"); recipeID != "" {
+		cached, ok := recipe.FindUserDetail(h.cache, middleware.UserID(c), recipeID, lang)
+		if !ok {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{
+				Code:    "RECIPE_NOT_FOUND",
+				Message: "菜谱不存在或已过期，请先获取菜谱详情",
+			})
+			return
+		}
+		detail = cached
+	}
+
+	name, ok := h.resolveName(c, c.Param("id"), strings.TrimSpace(c.Query("name")), cityName, detail, lang)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "INGREDIENT_NOT_FOUND",
+			Message: "未找到该食材，请提供食材名称",
+		})
+		return
+	}
+
+	opts := h.options(c, cityName, detail, lang)
+	opts.Allergens = parseAllergensQuery(c)
+	if detail != nil {
+		opts.Allergens = mergeAllergens(opts.Allergens, h.contextAllergens(detail.ID))
+	}
+	result, err := h.service.LoadSubstitutes(c.Request.Context(), name, opts, lang)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ApplySubstitution handles POST /api/v1/recipes/:recipeId/substitutions
+func (h *SubstituteHandler) ApplySubstitution(c *gin.Context) {
+	var req models.ApplySubstitutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "请求参数无效：" + err.Error(),
+		})
+		return
+	}
+
+	lang := i18n.GetLang(c)
+	recipeID := c.Param("recipeId")
+	// 用户声明的过敏原，合并推荐时声明的过敏原
+	declared := mergeAllergens(allergen.Normalize(req.Allergens), h.contextAllergens(recipeID))
+	// 用户编辑过的菜谱（或分享副本）也可以替换食材
+	detail, ok := recipe.FindUserDetail(h.cache, middleware.UserID(c), recipeID, lang)
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Code:    "RECIPE_NOT_FOUND",
+			Message: "菜谱不存在或已过期，请先获取菜谱详情",
+		})
+		return
+	}
+
+	// 替代品的用量比例和调整说明来自推荐结果；不在推荐中的替代品按等量替换
+	opts := h.options(c, strings.TrimSpace(req.City), detail, lang)
+	opts.Allergens = declared
+	suggestions, err := h.service.LoadSubstitutes(c.Request.Context(), req.Ingredient, opts, lang)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	substitute := models.Substitute{Name: strings.TrimSpace(req.Substitute), Role: models.RoleOther, Ratio: 1}
+	for _, sub := range suggestions.Substitutes {
+		if catalog.SameIngredient(sub.Name, substitute.Name) {
+			substitute = sub
+			break
+		}
+	}
+
+	modified, ok := recipe.ApplySubstitution(detail, req.Ingredient, substitute, lang)
+	if !ok {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Code:    "INGREDIENT_NOT_IN_RECIPE",
+			Message: "菜谱中没有该食材",
+		})
+		return
+	}
+
+	// 替代品是自由输入的，替换后重新检查过敏原，避免把过敏原带回已排除过敏原的菜谱
+	allergen.TagDetail(modified)
+	if conflicts := allergen.DetailConflicts(modified, declared); len(conflicts) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Code:    "ALLERGEN_CONFLICT",
+			Message: "替代食材含有声明的过敏原，请选择其他替代品",
+			Details: map[string]any{
+				"conflicts":        conflicts,
+				"checkedAllergens": declared,
+			},
+		})
+		return
+	}
+
+	// 缓存替换后的菜谱，便于后续获取详情、导出 PDF 或生成购物清单
+	if cache.DefaultManager != nil {
+		if err := cache.DefaultManager.SetJSON(recipe.DetailCacheKey(modified.ID, nil, "", lang), modified); err != nil {
+			log.Printf("[SubstituteHandler] 替换后的菜谱缓存写入失败: %v", err)
+		}
+	}
+	if h.cache != nil {
+		h.cache.SetNewRecipeDetail(modified.ID, modified)
+	}
+
+	c.JSON(http.StatusOK, models.ApplySubstitutionResponse{
+		Recipe:     *modified,
+		Original:   req.Ingredient,
+		Substitute: substitute,
+	})
+}
+
+// resolveName returns the ingredient name from the query, the recipe (by name) or the
+// cached seasonal list of the city (by ID)
+func (h *SubstituteHandler) resolveName(c *gin.Context, id, name, cityName string, detail *models.NewRecipeDetail, lang string) (string, bool) {
+	if name != "" {
+		return name, true
+	}
+	if detail != nil {
+		for _, ing := range detail.Ingredients {
+			if catalog.SameIngredient(ing.Name, id) {
+				return ing.Name, true
+			}
+		}
+	}
+	if cityName != "" {
+		if listed, ok := ingredient.FindListed(c.Request.Context(), id, cityName, lang); ok {
+			return listed.Name, true
+		}
+	}
+	return "", false
+}
+
+// options builds the substitution context; the seasonal list is optional and a failure
+// to load it only disables the in-season preference
+func (h *SubstituteHandler) options(c *gin.Context, cityName string, detail *models.NewRecipeDetail, lang string) recipe.SubstituteOptions {
+	opts := recipe.SubstituteOptions{Detail: detail, Month: int(time.Now().Month())}
+	if cityName == "" {
+		return opts
+	}
+	opts.City = city.Canonical(c.Request.Context(), cityName)
+	result, err := ingredient.LoadSeasonalIngredients(c.Request.Context(), h.ingredientService, cityName, lang)
+	if err != nil {
+		log.Printf("[SubstituteHandler] 获取应季食材失败，不按应季排序: %v", err)
+		return opts
+	}
+	opts.Seasonal = ingredient.Flatten(result)
+	if result.Location.Month > 0 {
+		opts.Month = result.Location.Month
+	}
+	return opts
+}
+
+// contextAllergens returns the allergens declared when the recipe was recommended
+func (h *SubstituteHandler) contextAllergens(recipeID string) []models.Allergen {
+	if h.store == nil {
+		return nil
+	}
+	rc, err := h.store.GetRecommendation(recipeID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[SubstituteHandler] 推荐上下文读取失败: %v", err)
+		}
+		return nil
+	}
+	return rc.Allergens
+}
+
+// writeError maps substitution errors to HTTP responses
+func (h *SubstituteHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, recipe.ErrServiceUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: "菜谱服务暂不可用，请稍后重试",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+		Code:    "GENERATION_FAILED",
+		Message: "获取替代食材失败：" + err.Error(),
+	})
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader 个人 API 密钥请求头，供脚本调用
+const APIKeyHeader = "X-API-Key"
+
+// authenticatedKey gin 上下文中标记请求已通过认证的键
+const authenticatedKey = "authenticated"
+
+// Authenticator verifies session tokens and API keys
+type Authenticator interface {
+	// VerifyToken returns the user a session token was issued to
+	VerifyToken(token string) (string, error)
+	// VerifyAPIKey returns the user owning an API key
+	VerifyAPIKey(key string) (string, error)
+	// IsRegistered reports whether id belongs to a registered user
+	IsRegistered(id string) bool
+}
+
+// Authenticate 识别 Authorization: Bearer <token> 或 X-API-Key 请求头中的凭证，
+// 认证通过后以注册用户的 ID 作为用户标识（覆盖 X-User-ID）。
+// 凭证无效时直接返回 401；未携带凭证的请求仍按匿名标识处理，
+// 但不能借用注册用户的 ID。须在 Identity 之后注册，auth 为 nil 时不做任何处理。
+func Authenticate(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth == nil {
+			c.Next()
+			return
+		}
+
+		var userID string
+		var err error
+		if token, ok := bearerToken(c.GetHeader("Authorization")); ok {
+			userID, err = auth.VerifyToken(token)
+		} else if key := c.GetHeader(APIKeyHeader); key != "" {
+			userID, err = auth.VerifyAPIKey(key)
+		} else {
+			if id := UserID(c); id != "" && auth.IsRegistered(id) {
+				c.Set(userIDKey, "")
+			}
+			c.Next()
+			return
+		}
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+				Code:    "INVALID_CREDENTIALS",
+				Message: "登录凭证无效或已过期，请重新登录",
+			})
+			return
+		}
+		c.Set(userIDKey, userID)
+		c.Set(authenticatedKey, true)
+		c.Next()
+	}
+}
+
+// RequireAuth 要求请求已通过认证
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Authenticated(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+				Code:    "UNAUTHENTICATED",
+				Message: "请先登录或提供 API 密钥（" + APIKeyHeader + " 请求头）",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAuthIf 在 required 为 true 时要求请求已通过认证，用于按配置限制的路由
+func RequireAuthIf(required bool) gin.HandlerFunc {
+	if required {
+		return RequireAuth()
+	}
+	return func(c *gin.Context) {
+		c.Next()
+	}
+}
+
+// RequireAdmin 要求请求已通过认证且用户为管理员，isAdmin 为 nil 时拒绝所有请求
+func RequireAdmin(isAdmin func(userID string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Authenticated(c) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{
+				Code:    "UNAUTHENTICATED",
+				Message: "请先登录或提供 API 密钥（" + APIKeyHeader + " 请求头）",
+			})
+			return
+		}
+		if isAdmin == nil || !isAdmin(UserID(c)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+				Code:    "FORBIDDEN",
+				Message: "需要管理员权限",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authenticated reports whether the request carried a valid session token or API key
+func Authenticated(c *gin.Context) bool {
+	return c.GetBool(authenticatedKey)
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserIDHeader 用户标识请求头，由前端生成并保存在本地
+const UserIDHeader = "X-User-ID"
+
+// userIDKey gin 上下文中保存用户标识的键
+const userIDKey = "userID"
+
+// validUserID 用户标识只允许字母、数字、下划线和连字符
+var validUserID = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// Identity 从请求头中识别用户，无效或缺失的标识会被忽略
+func Identity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id := c.GetHeader(UserIDHeader); validUserID.MatchString(id) {
+			c.Set(userIDKey, id)
+		}
+		c.Next()
+	}
+}
+
+// UserID returns the identified user of the request, or "" for anonymous requests
+func UserID(c *gin.Context) string {
+	return c.GetString(userIDKey)
+}
@@ -1,26 +1,45 @@
 package api
 
 import (
+	"context"
+	"crypto/rand"
+	"sync"
+
 	"github.com/eat-only-in-season/backend/internal/api/handlers"
 	"github.com/eat-only-in-season/backend/internal/api/middleware"
 	"github.com/eat-only-in-season/backend/internal/cache"
 	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/services/ai"
 	"github.com/eat-only-in-season/backend/internal/services/ai/imagegen"
+	"github.com/eat-only-in-season/backend/internal/services/auth"
+	"github.com/eat-only-in-season/backend/internal/services/embedding"
+	"github.com/eat-only-in-season/backend/internal/services/feedback"
 	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/library"
+	"github.com/eat-only-in-season/backend/internal/services/pantry"
 	"github.com/eat-only-in-season/backend/internal/services/pdf"
+	"github.com/eat-only-in-season/backend/internal/services/planner"
 	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/revision"
+	"github.com/eat-only-in-season/backend/internal/services/search"
+	"github.com/eat-only-in-season/backend/internal/services/share"
+	"github.com/eat-only-in-season/backend/internal/services/shopping"
+	"github.com/eat-only-in-season/backend/internal/store"
 	"github.com/eat-only-in-season/backend/pkg/config"
 	"github.com/gin-gonic/gin"
 )
 
-// SetupRouter 配置并返回路由
-func SetupRouter() *gin.Engine {
+// SetupRouter 配置并返回路由，以及在关闭时停止后台任务（详情预取、索引和嵌入同步）的函数
+func SetupRouter() (*gin.Engine, func()) {
 	router := gin.Default()
 
 	// Load configuration
 	cfg := config.Load()
 
+	// Background work (search index and embedding sync) runs until the returned stop function is called
+	ctx, cancel := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+
 	// Initialize provider manager
 	provider := ai.NewProviderManager(cfg)
 
@@ -34,16 +53,34 @@ func SetupRouter() *gin.Engine {
 		}
 	}
 
+	// User accounts and authentication (requires the persistent store)
+	var authService *auth.Service
+	var authenticator middleware.Authenticator
+	if store.Default != nil {
+		authService = auth.NewService(store.Default, authSecret(cfg), cfg.AuthTokenTTL, cfg.AdminUsers)
+		authenticator = authService
+	} else if cfg.AuthRequireImage || cfg.AuthRequirePDF {
+		gin.DefaultWriter.Write([]byte("Warning: Account service unavailable, image generation and PDF export restricted to authenticated users are disabled\n"))
+	}
+
 	// Global middleware
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(cfg.CORSAllowedOrigins))
 	router.Use(middleware.ErrorHandler())
 	router.Use(i18n.Middleware(i18n.DefaultLang()))
+	router.Use(middleware.Identity())
+	router.Use(middleware.Authenticate(authenticator))
 
 	// Initialize handlers
 	cityHandler := handlers.NewCityHandler(cache.DefaultCache)
 	systemHandler := handlers.NewSystemHandler(provider)
-	imageHandler := handlers.NewImageHandler(cache.DefaultCache, imageService)
+	imageHandler := handlers.NewImageHandler(cache.DefaultCache, imageService, cfg.AuthRequireImage)
 	pdfHandler := handlers.NewPDFHandler(cache.DefaultCache, pdf.NewService())
+	authHandler := handlers.NewAuthHandler(authService)
+	cacheAdminHandler := handlers.NewCacheAdminHandler(cache.DefaultManager)
+	var isAdmin func(string) bool
+	if authService != nil {
+		isAdmin = authService.IsAdmin
+	}
 
 	// 003-flow-redesign: Initialize new services and handlers
 	var ingredientService *ingredient.Service
@@ -60,11 +97,95 @@ func SetupRouter() *gin.Engine {
 		}
 	}
 	ingredientHandler := handlers.NewIngredientHandler(cache.DefaultCache, ingredientService)
-	newRecipeHandler := handlers.NewNewFlowRecipeHandler(cache.DefaultCache, recipeService)
+	// Pantry (requires the persistent store)
+	var pantryService *pantry.Service
+	if store.Default != nil {
+		pantryService = pantry.NewService(store.Default)
+	}
+	pantryHandler := handlers.NewPantryHandler(pantryService)
+
+	substituteHandler := handlers.NewSubstituteHandler(cache.DefaultCache, store.Default, recipeService, ingredientService)
+	// Background prefetch of the details of the top recommendations
+	var prefetcher *recipe.Prefetcher
+	if recipeService != nil && cfg.PrefetchTopN > 0 {
+		prefetcher = recipe.NewPrefetcher(recipeService, cache.DefaultCache, cfg.PrefetchTopN, cfg.PrefetchWorkers)
+		prefetcher.Start()
+	}
+	// Recipe library, favourites and viewing history (requires the persistent store)
+	var libraryService *library.Service
+	if store.Default != nil {
+		libraryService = library.NewService(store.Default)
+	}
+	libraryHandler := handlers.NewLibraryHandler(cache.DefaultCache, libraryService)
+	// Recipe ratings and feedback (requires the persistent store)
+	var feedbackService *feedback.Service
+	if store.Default != nil {
+		feedbackService = feedback.NewService(store.Default)
+	}
+	feedbackHandler := handlers.NewFeedbackHandler(cache.DefaultCache, store.Default, feedbackService)
+	// Shared recipe snapshots (requires the persistent store)
+	var shareService *share.Service
+	if store.Default != nil {
+		shareService = share.NewService(store.Default)
+	}
+	shareHandler := handlers.NewShareHandler(cache.DefaultCache, store.Default, shareService)
+	// User-edited recipes with revision history (requires the persistent store)
+	var revisionService *revision.Service
+	if store.Default != nil {
+		revisionService = revision.NewService(store.Default)
+	}
+	revisionHandler := handlers.NewRevisionHandler(cache.DefaultCache, revisionService, shareService)
+	// Full-text recipe search (requires the persistent store)
+	var searchService *search.Service
+	if store.Default != nil {
+		searchService = search.NewService(store.Default)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			searchService.SyncInBackground(ctx)
+		}()
+	}
+	searchHandler := handlers.NewSearchHandler(searchService)
+	// Recipe embeddings for similar recipes and near-duplicate detection (requires the persistent store)
+	var embeddingService *embedding.Service
+	if store.Default != nil {
+		embedder, err := embedding.NewEmbedder(cfg)
+		if err != nil {
+			gin.DefaultWriter.Write([]byte("Warning: Embedding service initialization failed: " + err.Error() + "\n"))
+		} else if embedder != nil {
+			embeddingService = embedding.NewService(store.Default, embedder, cfg.EmbeddingDuplicateThreshold)
+			background.Add(1)
+			go func() {
+				defer background.Done()
+				embeddingService.Start(ctx)
+			}()
+		}
+	}
+	similarHandler := handlers.NewSimilarHandler(embeddingService)
+	newRecipeHandler := handlers.NewNewFlowRecipeHandler(cache.DefaultCache, recipeService, pantryService, ingredientService, store.Default, prefetcher, libraryService, feedbackService, embeddingService)
+	shoppingListHandler := handlers.NewShoppingListHandler(cache.DefaultCache, shopping.NewService(), pdf.NewService(), ingredientService)
+
+	// Meal planner (requires the persistent store)
+	var plannerService *planner.Service
+	if store.Default != nil {
+		plannerService = planner.NewService(ingredientService, recipeService, store.Default, cache.DefaultCache)
+	}
+	mealPlanHandler := handlers.NewMealPlanHandler(plannerService, shoppingListHandler)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
+		// Account endpoints
+		authGroup := v1.Group("/auth")
+		{
+			authGroup.POST("/register", authHandler.Register)
+			authGroup.POST("/login", authHandler.Login)
+			authGroup.GET("/me", middleware.RequireAuth(), authHandler.Me)
+			authGroup.GET("/api-keys", middleware.RequireAuth(), authHandler.ListAPIKeys)
+			authGroup.POST("/api-keys", middleware.RequireAuth(), authHandler.CreateAPIKey)
+			authGroup.DELETE("/api-keys/:keyId", middleware.RequireAuth(), authHandler.RevokeAPIKey)
+		}
+
 		// City endpoints
 		city := v1.Group("/city")
 		{
@@ -77,21 +198,96 @@ func SetupRouter() *gin.Engine {
 		{
 			// Image endpoints
 			recipes.GET("/:recipeId/image", imageHandler.GetRecipeImage)
-			recipes.POST("/:recipeId/image", imageHandler.GenerateRecipeImage)
-			recipes.GET("/:recipeId/image-url", imageHandler.GetRecipeImageUrl)
+			recipes.POST("/:recipeId/image", middleware.RequireAuthIf(cfg.AuthRequireImage), imageHandler.GenerateRecipeImage)
+			recipes.GET("/:recipeId/image-url", imageHandler.GetRecipeImageUrl)  // 生成前按配置校验登录
 			recipes.GET("/:recipeId/image-proxy", imageHandler.ProxyRecipeImage) // 006: 图片代理，用于 PDF 导出
 			// PDF export
-			recipes.POST("/:recipeId/pdf", pdfHandler.ExportPDF)
+			recipes.POST("/:recipeId/pdf", middleware.RequireAuthIf(cfg.AuthRequirePDF), pdfHandler.ExportPDF)
 			// New recipe endpoints
 			recipes.POST("/by-ingredients", newRecipeHandler.GetRecipesByIngredients)
+			recipes.GET("/search", searchHandler.SearchRecipes)
+			recipes.GET("/:recipeId/similar", similarHandler.GetSimilarRecipes)
 			recipes.GET("/:recipeId/detail", newRecipeHandler.GetNewRecipeDetail)
+			recipes.POST("/:recipeId/substitutions", substituteHandler.ApplySubstitution)
+			// Ratings and feedback
+			// 评价计入公开的汇总评分，提交和删除需要登录，匿名标识可任意生成
+			recipes.PUT("/:recipeId/feedback", middleware.RequireAuth(), feedbackHandler.SubmitFeedback)
+			recipes.GET("/:recipeId/feedback", feedbackHandler.GetFeedback)
+			recipes.DELETE("/:recipeId/feedback", middleware.RequireAuth(), feedbackHandler.DeleteFeedback)
+			recipes.GET("/:recipeId/ratings", feedbackHandler.GetRatings)
+			// Shareable snapshot
+			recipes.POST("/:recipeId/publish", shareHandler.PublishRecipe)
+			// Editing and revision history
+			recipes.PUT("/:recipeId", revisionHandler.EditRecipe)
+			recipes.GET("/:recipeId/revisions", revisionHandler.ListRevisions)
+			recipes.GET("/:recipeId/revisions/:revision", revisionHandler.GetRevision)
+			recipes.POST("/:recipeId/revisions/:revision/restore", revisionHandler.RestoreRevision)
+			recipes.GET("/:recipeId/diff", revisionHandler.DiffRevisions)
 		}
 
 		// 003-flow-redesign: Ingredient endpoints
 		ingredients := v1.Group("/ingredients")
 		{
 			ingredients.POST("", ingredientHandler.GetSeasonalIngredients)
+			ingredients.GET("/transitions", ingredientHandler.GetSeasonTransitions)
 			ingredients.GET("/:id/detail", ingredientHandler.GetIngredientDetail)
+			ingredients.GET("/:id/calendar", ingredientHandler.GetIngredientCalendar)
+			ingredients.GET("/:id/substitutes", substituteHandler.GetSubstitutes)
+		}
+
+		// Seasonal calendar endpoint
+		v1.GET("/calendar", ingredientHandler.GetSeasonalCalendar)
+
+		// Favourite recipes and viewing history
+		favourites := v1.Group("/favourites")
+		{
+			favourites.GET("", libraryHandler.ListFavourites)
+			favourites.POST("", libraryHandler.AddFavourite)
+			favourites.GET("/:recipeId", libraryHandler.GetFavourite)
+			favourites.DELETE("/:recipeId", libraryHandler.RemoveFavourite)
+		}
+		v1.GET("/history", libraryHandler.ListHistory)
+		v1.DELETE("/history", libraryHandler.ClearHistory)
+		v1.GET("/feedback", feedbackHandler.ListFeedback)
+
+		// Edited recipes and personal copies of shared recipes
+		myRecipes := v1.Group("/my-recipes")
+		{
+			myRecipes.GET("", revisionHandler.ListUserRecipes)
+			myRecipes.DELETE("/:recipeId", revisionHandler.DiscardUserRecipe)
+		}
+
+		// Shared recipe snapshots (anonymous read)
+		shared := v1.Group("/shared")
+		{
+			shared.GET("/:slug", shareHandler.GetSharedRecipe)
+			shared.GET("/:slug/image", shareHandler.GetSharedImage)
+			shared.POST("/:slug/fork", revisionHandler.ForkSharedRecipe)
+		}
+
+		// Cuisine taxonomy endpoint
+		v1.GET("/cuisines", newRecipeHandler.ListCuisines)
+
+		// Shopping list endpoint
+		v1.POST("/shopping-list", shoppingListHandler.CreateShoppingList)
+
+		// Pantry endpoints
+		pantryGroup := v1.Group("/pantry")
+		{
+			pantryGroup.GET("", pantryHandler.ListItems)
+			pantryGroup.POST("", pantryHandler.CreateItem)
+			pantryGroup.PUT("/:itemId", pantryHandler.UpdateItem)
+			pantryGroup.DELETE("/:itemId", pantryHandler.DeleteItem)
+		}
+
+		// Meal plan endpoints
+		mealPlans := v1.Group("/meal-plans")
+		{
+			mealPlans.POST("", mealPlanHandler.CreateMealPlan)
+			mealPlans.GET("/:planId", mealPlanHandler.GetMealPlan)
+			mealPlans.DELETE("/:planId", mealPlanHandler.DeleteMealPlan)
+			mealPlans.POST("/:planId/swap", mealPlanHandler.SwapMeal)
+			mealPlans.GET("/:planId/shopping-list", mealPlanHandler.GetShoppingList)
 		}
 
 		// System endpoints
@@ -101,7 +297,34 @@ func SetupRouter() *gin.Engine {
 			system.GET("/status", systemHandler.Status)
 			system.GET("/cache-stats", systemHandler.CacheStats)
 		}
+
+		// Cache administration (admin users only)
+		adminCache := v1.Group("/admin/cache", middleware.RequireAdmin(isAdmin))
+		{
+			adminCache.GET("/keys", cacheAdminHandler.ListKeys)
+			adminCache.GET("/entry", cacheAdminHandler.InspectEntry)
+			adminCache.DELETE("/keys", cacheAdminHandler.PurgeKeys)
+		}
 	}
 
-	return router
+	stop := func() {
+		cancel()
+		prefetcher.Stop()
+		background.Wait()
+	}
+	return router, stop
+}
+
+// authSecret returns the configured token signing secret, or a random one when none is
+// configured (tokens issued before a restart then become invalid)
+func authSecret(cfg *config.Config) []byte {
+	if cfg.AuthSecret != "" {
+		return []byte(cfg.AuthSecret)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic("generate auth secret: " + err.Error())
+	}
+	gin.DefaultWriter.Write([]byte("Warning: AUTH_SECRET not set, using a random secret; session tokens will not survive a restart\n"))
+	return secret
 }
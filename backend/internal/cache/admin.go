@@ -0,0 +1,151 @@
+// Package cache - 缓存管理：按前缀或模式列出、查看和清除缓存条目
+package cache
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrEmptyFilter 清除缓存时未指定前缀、模式或城市
+var ErrEmptyFilter = errors.New("cache filter needs a prefix, a pattern or a city")
+
+// EntryInfo 缓存条目的元数据
+type EntryInfo struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+	// StaleAt 条目变旧的时间，之后读取会触发后台重新生成
+	StaleAt   time.Time `json:"staleAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	// InMemory 条目是否同时在内存缓存中（仅通过缓存管理器列出时）
+	InMemory bool `json:"inMemory,omitempty"`
+}
+
+// Entry 缓存条目及其值
+type Entry struct {
+	EntryInfo
+	Value []byte `json:"-"`
+}
+
+// KeyFilter selects cache keys by prefix, glob pattern and/or city; an empty filter
+// selects every key
+type KeyFilter struct {
+	Prefix string
+	// Pattern 匹配整个键的通配模式：* 匹配任意字符（包括 :），? 匹配单个字符
+	Pattern string
+	// Cities 城市在键中的形式（见 city.Canonical），只选择含其中任一城市（city=...）的键
+	Cities  []string
+	pattern *regexp.Regexp
+}
+
+// NewKeyFilter creates a filter from a prefix and a glob pattern, either may be empty
+func NewKeyFilter(prefix, pattern string) KeyFilter {
+	f := KeyFilter{Prefix: prefix, Pattern: pattern}
+	if pattern != "" {
+		var sb strings.Builder
+		sb.WriteString("^")
+		for _, r := range pattern {
+			switch r {
+			case '*':
+				sb.WriteString(".*")
+			case '?':
+				sb.WriteString(".")
+			default:
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		sb.WriteString("$")
+		f.pattern = regexp.MustCompile(sb.String())
+	}
+	return f
+}
+
+// WithCities returns the filter restricted to the keys of any of the given cities,
+// in the form they take in cache keys; empty names are ignored
+func (f KeyFilter) WithCities(cities ...string) KeyFilter {
+	f.Cities = nil
+	for _, c := range cities {
+		if c = strings.TrimSpace(c); c != "" && !slices.Contains(f.Cities, c) {
+			f.Cities = append(f.Cities, c)
+		}
+	}
+	return f
+}
+
+// Empty reports whether the filter selects every key
+func (f KeyFilter) Empty() bool {
+	return f.Prefix == "" && f.Pattern == "" && len(f.Cities) == 0
+}
+
+// Match reports whether a key is selected by the filter
+func (f KeyFilter) Match(key string) bool {
+	if !strings.HasPrefix(key, f.Prefix) {
+		return false
+	}
+	if len(f.Cities) > 0 && !f.matchCity(key) {
+		return false
+	}
+	return f.pattern == nil || f.pattern.MatchString(key)
+}
+
+// matchCity 键中任一部分为 city={城市} 时匹配，与该部分在键中的位置无关
+func (f KeyFilter) matchCity(key string) bool {
+	for _, part := range strings.Split(key, ":") {
+		for _, c := range f.Cities {
+			if part == "city="+escapeKeyPart(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// List 列出符合条件的未过期缓存条目（以持久化缓存为准），返回一页条目和总数
+func (m *CacheManager) List(filter KeyFilter, limit, offset int) ([]EntryInfo, int, error) {
+	entries, total, err := m.backend.List(filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range entries {
+		entries[i].InMemory = m.memory.Has(entries[i].Key)
+	}
+	return entries, total, nil
+}
+
+// Inspect 查看缓存条目及其值
+func (m *CacheManager) Inspect(key string) (*Entry, bool) {
+	entry, ok := m.backend.Entry(key)
+	if !ok {
+		return nil, false
+	}
+	entry.InMemory = m.memory.Has(key)
+	return entry, true
+}
+
+// Purge 从内存缓存和持久化缓存中删除符合条件的条目，返回删除的条目数。
+// 为避免误删全部缓存，过滤条件不能为空。
+func (m *CacheManager) Purge(filter KeyFilter) (int, error) {
+	if filter.Empty() {
+		return 0, ErrEmptyFilter
+	}
+
+	purged := make(map[string]bool)
+	for _, key := range m.memory.Keys() {
+		if filter.Match(key) {
+			m.memory.Delete(key)
+			purged[key] = true
+		}
+	}
+
+	keys, err := m.backend.Purge(filter)
+	if err != nil {
+		return len(purged), err
+	}
+	for _, key := range keys {
+		purged[key] = true
+	}
+	return len(purged), nil
+}
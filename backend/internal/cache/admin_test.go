@@ -0,0 +1,36 @@
+package cache
+
+import "testing"
+
+func TestKeyFilterCities(t *testing.T) {
+	filter := NewKeyFilter("", "").WithCities("39.90/116.41", "bei jing", "39.90/116.41", " ")
+	if len(filter.Cities) != 2 {
+		t.Fatalf("Cities = %v, want the two distinct non-empty cities", filter.Cities)
+	}
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{IngredientsKey("39.90/116.41", "zh", 5), true},
+		{CalendarKey("39.90/116.41", "en"), true},
+		{SubstitutesKey("r1", "番茄", "39.90/116.41", "zh"), true},
+		{CalendarKey("bei jing", "zh"), true},
+		{CalendarKey("31.23/121.47", "zh"), false},
+		{CalendarKey("39.90/116.4", "zh"), false},
+		{RecipeDetailKey("r1", "zh", "", nil), false},
+	}
+	for _, tt := range tests {
+		if got := filter.Match(tt.key); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	if !filter.WithCities().Empty() {
+		t.Error("filter without prefix, pattern or cities is not empty")
+	}
+	narrowed := NewKeyFilter(NamespaceCalendar+":", "").WithCities("39.90/116.41")
+	if narrowed.Match(IngredientsKey("39.90/116.41", "zh", 5)) {
+		t.Error("city filter ignored the prefix")
+	}
+}
@@ -0,0 +1,64 @@
+// Package cache - 持久化缓存后端（L2）接口
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// 持久化缓存后端类型
+const (
+	BackendSQLite = "sqlite"
+	BackendRedis  = "redis"
+)
+
+// Backend is the persistent layer (L2) behind CacheManager. Entries become stale
+// after their soft TTL and expire after their hard TTL; expired entries are never
+// returned or listed.
+type Backend interface {
+	// Get 获取未过期的缓存值及其变旧时间和过期时间
+	Get(key string) (value []byte, staleAt, expiresAt time.Time, found bool)
+	// Set 设置缓存值，条目在 softTTL 后变旧，在 hardTTL 后过期
+	Set(key string, value []byte, softTTL, hardTTL time.Duration) error
+	Delete(key string) error
+	// Cleanup 删除过期条目，自动过期的后端无需实现
+	Cleanup() error
+	// Count 未过期的条目数
+	Count() (int, error)
+	// GetAll 获取所有未过期的条目及其值（用于预热）
+	GetAll() ([]Entry, error)
+	// List 按条件列出未过期的条目（按键排序），返回一页条目和符合条件的总数
+	List(filter KeyFilter, limit, offset int) ([]EntryInfo, int, error)
+	// Entry 获取未过期的条目及其值
+	Entry(key string) (*Entry, bool)
+	// Purge 删除符合条件的条目，返回删除的键
+	Purge(filter KeyFilter) ([]string, error)
+	Close() error
+}
+
+// Invalidator is implemented by backends shared between replicas: Watch calls
+// invalidate with the keys other replicas changed or deleted, until ctx is done,
+// so the memory layer (L1) of this replica does not serve outdated values.
+type Invalidator interface {
+	Watch(ctx context.Context, invalidate func(keys []string))
+}
+
+// NewBackend creates the persistent cache backend selected by the configuration
+func NewBackend(config models.CacheConfig) (Backend, error) {
+	switch config.Backend {
+	case "", BackendSQLite:
+		return NewSQLiteCache(config.SQLitePath)
+	case BackendRedis:
+		opts, err := redis.ParseURL(config.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis url: %w", err)
+		}
+		return NewRedisCache(redis.NewClient(opts), config.RedisPrefix)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", config.Backend)
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 缓存命名空间，缓存键的第一段
+const (
+	NamespaceIngredients        = "ingredients"
+	NamespaceIngredientDetail   = "ingredient-detail"
+	NamespaceCalendar           = "calendar"
+	NamespaceIngredientCalendar = "ingredient-calendar"
+	NamespaceRecipes            = "recipes"
+	NamespaceRecipeDetail       = "recipe-detail"
+	NamespaceSubstitutes        = "substitutes"
+	NamespaceImage              = "image"
+	NamespaceImageURL           = "image-url"
+	NamespaceImageStatus        = "image-status"
+	NamespaceDetailByID         = "detail-by-id"
+	NamespaceCity               = "city"
+	NamespaceLegacyRecipes      = "legacy-recipes"
+	NamespaceLegacyRecipe       = "legacy-recipe"
+	NamespaceLegacyDetail       = "legacy-detail"
+)
+
+// Versions 各命名空间的提示词/数据结构版本，写入缓存键的第二段。
+// 修改某类数据的提示词或缓存的结构时递增对应版本，部署后旧条目不再命中，按 TTL 自然过期。
+var Versions = map[string]int{
+	NamespaceIngredients:        1,
+	NamespaceIngredientDetail:   1,
+	NamespaceCalendar:           1,
+	NamespaceIngredientCalendar: 1,
+	NamespaceRecipes:            1,
+	NamespaceRecipeDetail:       1,
+	NamespaceSubstitutes:        1,
+	NamespaceImage:              1,
+	NamespaceImageURL:           1,
+	NamespaceImageStatus:        1,
+	NamespaceDetailByID:         1,
+	NamespaceCity:               1,
+	NamespaceLegacyRecipes:      1,
+	NamespaceLegacyRecipe:       1,
+	NamespaceLegacyDetail:       1,
+}
+
+// KeyBuilder builds cache keys of the form {namespace}:v{version}:{name}={value}:...
+// Values are trimmed and their separators escaped, so user input cannot forge or
+// collide with other parts; empty values are left out, so optional parts can be
+// added unconditionally.
+type KeyBuilder struct {
+	namespace string
+	parts     []string
+}
+
+// NewKey starts a cache key in a namespace, versioned by Versions
+func NewKey(namespace string) *KeyBuilder {
+	return &KeyBuilder{namespace: namespace}
+}
+
+// Field adds a named string part
+func (b *KeyBuilder) Field(name, value string) *KeyBuilder {
+	if value = strings.TrimSpace(value); value != "" {
+		b.parts = append(b.parts, name+"="+escapeKeyPart(value))
+	}
+	return b
+}
+
+// Int adds a named integer part; zero is left out
+func (b *KeyBuilder) Int(name string, value int) *KeyBuilder {
+	if value != 0 {
+		b.parts = append(b.parts, name+"="+strconv.Itoa(value))
+	}
+	return b
+}
+
+// List adds a named set of values, sorted and without duplicates, so the order
+// the values were given in does not matter
+func (b *KeyBuilder) List(name string, values []string) *KeyBuilder {
+	seen := make(map[string]bool, len(values))
+	escaped := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" && !seen[v] {
+			seen[v] = true
+			escaped = append(escaped, escapeKeyPart(v))
+		}
+	}
+	if len(escaped) > 0 {
+		sort.Strings(escaped)
+		b.parts = append(b.parts, name+"="+strings.Join(escaped, ","))
+	}
+	return b
+}
+
+// String returns the cache key
+func (b *KeyBuilder) String() string {
+	key := b.namespace + ":v" + strconv.Itoa(Versions[b.namespace])
+	if len(b.parts) > 0 {
+		key += ":" + strings.Join(b.parts, ":")
+	}
+	return key
+}
+
+// escapeKeyPart percent-encodes the key separators, '%' and control characters and
+// whitespace of a value; other characters (including Chinese) are kept readable
+func escapeKeyPart(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == ':' || r == ',' || r == '=' || r == '%' || r <= ' ' || r == 0x7f:
+			fmt.Fprintf(&sb, "%%%02X", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// NormalizeName returns the form of free text (such as a city that cannot be geocoded)
+// used in cache keys: lower-cased, with runs of whitespace collapsed to one space
+func NormalizeName(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
@@ -14,44 +14,70 @@ import (
 
 // CacheManager 双层缓存管理器
 // L1: 内存 LRU 缓存 (ttlcache) - 快速访问
-// L2: SQLite 持久化缓存 - 服务重启后恢复
+// L2: 持久化缓存后端 (SQLite 或 Redis) - 服务重启后恢复，Redis 可由多个副本共享
 type CacheManager struct {
-	config  models.CacheConfig
-	memory  *ttlcache.Cache[string, []byte]
-	sqlite  *SQLiteCache
-	mutex   sync.RWMutex
-	stopCh  chan struct{}
-	stopped bool
+	config   models.CacheConfig
+	policies map[string]models.CachePolicy
+	memory   *ttlcache.Cache[string, memoryEntry]
+	backend  Backend
+	mutex    sync.RWMutex
+	stopCh   chan struct{}
+	stopped  bool
+	// stopWatch 停止订阅其他副本的失效通知
+	stopWatch context.CancelFunc
+	// refreshing 正在后台刷新的键
+	refreshing sync.Map
+}
+
+// memoryEntry 内存缓存中的值及其变旧时间
+type memoryEntry struct {
+	value   []byte
+	staleAt time.Time
 }
 
 // DefaultManager 全局缓存管理器实例
 var DefaultManager *CacheManager
 
-// NewCacheManager 创建双层缓存管理器
+// NewCacheManager 创建双层缓存管理器，持久化后端由配置选择
 func NewCacheManager(config models.CacheConfig) (*CacheManager, error) {
-	// 创建 SQLite 持久化缓存
-	sqliteCache, err := NewSQLiteCache(config.SQLitePath)
+	backend, err := NewBackend(config)
 	if err != nil {
 		return nil, err
 	}
+	return NewCacheManagerWithBackend(config, backend), nil
+}
 
+// NewCacheManagerWithBackend 在指定的持久化后端上创建双层缓存管理器
+func NewCacheManagerWithBackend(config models.CacheConfig, backend Backend) *CacheManager {
 	// 创建内存 LRU 缓存
-	memoryCache := ttlcache.New[string, []byte](
-		ttlcache.WithTTL[string, []byte](config.MemoryTTL),
-		ttlcache.WithCapacity[string, []byte](uint64(config.MemoryMaxItems)),
+	memoryCache := ttlcache.New[string, memoryEntry](
+		ttlcache.WithTTL[string, memoryEntry](config.MemoryTTL),
+		ttlcache.WithCapacity[string, memoryEntry](uint64(config.MemoryMaxItems)),
 	)
 
 	manager := &CacheManager{
-		config: config,
-		memory: memoryCache,
-		sqlite: sqliteCache,
-		stopCh: make(chan struct{}),
+		config:   config,
+		policies: mergePolicies(config.Policies),
+		memory:   memoryCache,
+		backend:  backend,
+		stopCh:   make(chan struct{}),
 	}
 
 	// 启动内存缓存自动过期清理
 	go memoryCache.Start()
 
-	return manager, nil
+	// 共享的后端：其他副本修改条目后删除内存缓存中的旧值
+	if invalidator, ok := backend.(Invalidator); ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		manager.stopWatch = cancel
+		go invalidator.Watch(ctx, func(keys []string) {
+			for _, key := range keys {
+				memoryCache.Delete(key)
+			}
+		})
+	}
+
+	return manager
 }
 
 // InitDefaultManager 初始化全局缓存管理器
@@ -64,31 +90,49 @@ func InitDefaultManager(config models.CacheConfig) error {
 	return nil
 }
 
-// Get 获取缓存值 (L1 -> L2)
+// Get 获取未过期的缓存值 (L1 -> L2)，包括已变旧的值
 // 查询顺序: 内存缓存 -> SQLite缓存 -> 返回未命中
 func (m *CacheManager) Get(key string) ([]byte, bool) {
+	value, _, found := m.lookup(key)
+	return value, found
+}
+
+// lookup 获取未过期的缓存值及其变旧时间
+func (m *CacheManager) lookup(key string) ([]byte, time.Time, bool) {
 	// L1: 尝试从内存缓存获取
 	if item := m.memory.Get(key); item != nil {
-		return item.Value(), true
+		return item.Value().value, item.Value().staleAt, true
 	}
 
-	// L2: 尝试从 SQLite 缓存获取
-	if value, found := m.sqlite.Get(key); found {
-		// 回填到内存缓存 (使用内存TTL)
-		m.memory.Set(key, value, m.config.MemoryTTL)
-		return value, true
+	// L2: 尝试从持久化缓存获取
+	if value, staleAt, expiresAt, found := m.backend.Get(key); found {
+		// 回填到内存缓存，不超过条目的过期时间
+		m.setMemory(key, value, staleAt, expiresAt)
+		return value, staleAt, true
 	}
 
-	return nil, false
+	return nil, time.Time{}, false
 }
 
-// Set 设置缓存值 (同时写入 L1 和 L2)
+// Set 设置缓存值 (同时写入 L1 和 L2)，按键的命名空间策略过期
 func (m *CacheManager) Set(key string, value []byte) error {
+	policy := m.Policy(key)
+	now := time.Now()
+
 	// L1: 写入内存缓存
-	m.memory.Set(key, value, m.config.MemoryTTL)
+	m.setMemory(key, value, now.Add(policy.SoftTTL), now.Add(policy.HardTTL))
+
+	// L2: 写入持久化缓存
+	return m.backend.Set(key, value, policy.SoftTTL, policy.HardTTL)
+}
 
-	// L2: 写入 SQLite 缓存
-	return m.sqlite.Set(key, value, m.config.SQLiteTTL)
+// setMemory 写入内存缓存，内存 TTL 不超过条目的剩余有效期
+func (m *CacheManager) setMemory(key string, value []byte, staleAt, expiresAt time.Time) {
+	ttl := min(m.config.MemoryTTL, time.Until(expiresAt))
+	if ttl <= 0 {
+		return
+	}
+	m.memory.Set(key, memoryEntry{value: value, staleAt: staleAt}, ttl)
 }
 
 // Delete 删除缓存值 (同时从 L1 和 L2 删除)
@@ -96,8 +140,8 @@ func (m *CacheManager) Delete(key string) error {
 	// L1: 从内存缓存删除
 	m.memory.Delete(key)
 
-	// L2: 从 SQLite 缓存删除
-	return m.sqlite.Delete(key)
+	// L2: 从持久化缓存删除
+	return m.backend.Delete(key)
 }
 
 // GetJSON 获取并反序列化 JSON ��存
@@ -121,18 +165,18 @@ func (m *CacheManager) SetJSON(key string, v interface{}) error {
 	return m.Set(key, data)
 }
 
-// WarmUp 预热: 从 SQLite 加载数据到内存缓存
+// WarmUp 预热: 从持久化缓存加载数据到内存缓存
 func (m *CacheManager) WarmUp() error {
 	log.Println("[CacheManager] 开始缓存预热...")
 
-	allData, err := m.sqlite.GetAll()
+	entries, err := m.backend.GetAll()
 	if err != nil {
 		return err
 	}
 
 	count := 0
-	for key, value := range allData {
-		m.memory.Set(key, value, m.config.MemoryTTL)
+	for _, entry := range entries {
+		m.setMemory(entry.Key, entry.Value, entry.StaleAt, entry.ExpiresAt)
 		count++
 	}
 
@@ -150,10 +194,10 @@ func (m *CacheManager) StartCleanupRoutine(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			if err := m.sqlite.Cleanup(); err != nil {
+			if err := m.backend.Cleanup(); err != nil {
 				log.Printf("[CacheManager] SQLite 清理失败: %v\n", err)
 			} else {
-				count, _ := m.sqlite.Count()
+				count, _ := m.backend.Count()
 				log.Printf("[CacheManager] SQLite 清理完成，当前缓存条目数: %d\n", count)
 			}
 		case <-ctx.Done():
@@ -169,22 +213,26 @@ func (m *CacheManager) StartCleanupRoutine(ctx context.Context) {
 // Stats 获取缓存统计信息
 func (m *CacheManager) Stats() CacheStats {
 	memoryCount := m.memory.Len()
-	sqliteCount, _ := m.sqlite.Count()
+	backendCount, _ := m.backend.Count()
 
 	return CacheStats{
-		MemoryItems: memoryCount,
-		SQLiteItems: sqliteCount,
-		MemoryTTL:   m.config.MemoryTTL,
-		SQLiteTTL:   m.config.SQLiteTTL,
+		Backend:      m.backendName(),
+		MemoryItems:  memoryCount,
+		BackendItems: backendCount,
+		MemoryTTL:    m.config.MemoryTTL,
+		SQLiteTTL:    m.config.SQLiteTTL,
+		Policies:     m.policies,
 	}
 }
 
 // CacheStats 缓存统计信息
 type CacheStats struct {
-	MemoryItems int           `json:"memoryItems"`
-	SQLiteItems int           `json:"sqliteItems"`
-	MemoryTTL   time.Duration `json:"memoryTTL"`
-	SQLiteTTL   time.Duration `json:"sqliteTTL"`
+	Backend      string                        `json:"backend"`
+	MemoryItems  int                           `json:"memoryItems"`
+	BackendItems int                           `json:"backendItems"`
+	MemoryTTL    time.Duration                 `json:"memoryTTL"`
+	SQLiteTTL    time.Duration                 `json:"sqliteTTL"`
+	Policies     map[string]models.CachePolicy `json:"policies"`
 }
 
 // Close 关闭缓存管理器
@@ -197,49 +245,62 @@ func (m *CacheManager) Close() error {
 	}
 	m.stopped = true
 
-	// 停止清理协程
+	// 停止清理协程和失效通知订阅
 	close(m.stopCh)
+	if m.stopWatch != nil {
+		m.stopWatch()
+	}
 
 	// 停止内存缓存
 	m.memory.Stop()
 
-	// 关闭 SQLite 连接
-	return m.sqlite.Close()
+	// 关闭持久化后端连接
+	return m.backend.Close()
+}
+
+// backendName 持久化后端的类型
+func (m *CacheManager) backendName() string {
+	if _, ok := m.backend.(*RedisCache); ok {
+		return BackendRedis
+	}
+	return BackendSQLite
 }
 
 // --- 便捷方法: 特定类型缓存键生成 ---
+// 城市应为规范化后的城市（见 city.Canonical），语言参数为界面语言
 
-// IngredientsKey 生成食材缓存键
-// 格式: ingredients:{city}:{season}:{month}
-func IngredientsKey(city string, season string, month int) string {
-	return "ingredients:" + city + ":" + season + ":" + string(rune('0'+month))
+// IngredientsKey 生成当月应季食材缓存键
+// 格式: ingredients:v{n}:lang={lang}:city={city}:month={month}
+func IngredientsKey(city string, lang string, month int) string {
+	return NewKey(NamespaceIngredients).Field("lang", lang).Field("city", city).Int("month", month).String()
 }
 
-// RecipesByIngredientsKey 生成基于食材的菜谱缓存键
-// 格式: recipes-by-ingredients:{ingredientIDs sorted and joined}
-func RecipesByIngredientsKey(ingredientIDs []string) string {
-	// 简单拼接，实际使用时应排序以保证一致性
-	key := "recipes-by-ingredients:"
-	for i, id := range ingredientIDs {
-		if i > 0 {
-			key += ","
-		}
-		key += id
-	}
-	return key
+// RecipeDetailKey 生成菜谱详情缓存键，按菜系或含过敏原约束生成的详情单独缓存
+// 格式: recipe-detail:v{n}:lang={lang}:id={recipeID}[:cuisine={cuisine}][:allergens={codes}]
+func RecipeDetailKey(recipeID string, lang string, cuisine string, allergens []string) string {
+	return NewKey(NamespaceRecipeDetail).Field("lang", lang).Field("id", recipeID).Field("cuisine", cuisine).List("allergens", allergens).String()
 }
 
-// RecipeDetailKey 生成菜谱详情缓存键
-func RecipeDetailKey(recipeID string) string {
-	return "recipe-detail:" + recipeID
+// IngredientDetailKey 生成食材详情缓存键
+// 格式: ingredient-detail:v{n}:lang={lang}:id={ingredientID}
+func IngredientDetailKey(ingredientID string, lang string) string {
+	return NewKey(NamespaceIngredientDetail).Field("lang", lang).Field("id", ingredientID).String()
 }
 
-// IngredientDetailKey 生成食材详情缓存键
-func IngredientDetailKey(ingredientID string) string {
-	return "ingredient-detail:" + ingredientID
+// CalendarKey 生成地区全年应季日历缓存键
+// 格式: calendar:v{n}:lang={lang}:city={city}
+func CalendarKey(city string, lang string) string {
+	return NewKey(NamespaceCalendar).Field("lang", lang).Field("city", city).String()
+}
+
+// IngredientCalendarKey 生成单个食材全年应季日历缓存键
+// 格式: ingredient-calendar:v{n}:lang={lang}:city={city}:name={name}
+func IngredientCalendarKey(city string, name string, lang string) string {
+	return NewKey(NamespaceIngredientCalendar).Field("lang", lang).Field("city", city).Field("name", name).String()
 }
 
-// ImageKey 生成图片缓存键
-func ImageCacheKey(recipeID string) string {
-	return "image:" + recipeID
+// SubstitutesKey 生成食材替代方案缓存键，提示词包含城市的应季食材，按城市分别缓存
+// 格式: substitutes:v{n}:lang={lang}[:city={city}]:recipe={recipeID}:ingredient={name}
+func SubstitutesKey(recipeID string, ingredientName string, city string, lang string) string {
+	return NewKey(NamespaceSubstitutes).Field("lang", lang).Field("city", city).Field("recipe", recipeID).Field("ingredient", ingredientName).String()
 }
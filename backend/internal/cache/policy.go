@@ -0,0 +1,109 @@
+// Package cache - 按命名空间的过期策略与过期后台刷新
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+const (
+	day = 24 * time.Hour
+	// refreshTimeout 后台重新生成一个条目的最长时间
+	refreshTimeout = 3 * time.Minute
+)
+
+// DefaultPolicies 各命名空间的默认过期策略，可通过 CACHE_TTL_POLICIES 覆盖。
+// 应季数据变化缓慢，变旧后仍可先返回旧值再在后台刷新；
+// 菜谱详情不刷新（软硬 TTL 相同），避免用户已看过的菜谱内容变化。
+// 未列出的命名空间使用 SQLiteTTL，且不变旧。
+var DefaultPolicies = map[string]models.CachePolicy{
+	NamespaceIngredients:        {SoftTTL: 3 * day, HardTTL: 31 * day},
+	NamespaceIngredientDetail:   {SoftTTL: 7 * day, HardTTL: 30 * day},
+	NamespaceCalendar:           {SoftTTL: 7 * day, HardTTL: 60 * day},
+	NamespaceIngredientCalendar: {SoftTTL: 7 * day, HardTTL: 60 * day},
+	NamespaceRecipes:            {SoftTTL: 1 * day, HardTTL: 7 * day},
+	NamespaceRecipeDetail:       {SoftTTL: 30 * day, HardTTL: 30 * day},
+	NamespaceSubstitutes:        {SoftTTL: 7 * day, HardTTL: 30 * day},
+	NamespaceImage:              {SoftTTL: 7 * day, HardTTL: 7 * day},
+	// 类型化缓存（见 Cache），不刷新
+	NamespaceImageURL:      {SoftTTL: ImageTTL, HardTTL: ImageTTL},
+	NamespaceImageStatus:   {SoftTTL: ImageStatusTTL, HardTTL: ImageStatusTTL},
+	NamespaceDetailByID:    {SoftTTL: 30 * day, HardTTL: 30 * day},
+	NamespaceCity:          {SoftTTL: CityTTL, HardTTL: CityTTL},
+	NamespaceLegacyRecipes: {SoftTTL: RecipeTTL, HardTTL: RecipeTTL},
+	NamespaceLegacyRecipe:  {SoftTTL: RecipeTTL, HardTTL: RecipeTTL},
+	NamespaceLegacyDetail:  {SoftTTL: DetailTTL, HardTTL: DetailTTL},
+}
+
+// RefreshFunc 重新生成变旧缓存条目的值
+type RefreshFunc func(ctx context.Context) (any, error)
+
+// Namespace 返回缓存键的命名空间（键的第一段）
+func Namespace(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	return namespace
+}
+
+// Policy 按命名空间返回缓存键的过期策略，未配置的命名空间使用 SQLiteTTL 且不变旧
+func (m *CacheManager) Policy(key string) models.CachePolicy {
+	if policy, ok := m.policies[Namespace(key)]; ok {
+		return policy
+	}
+	return models.CachePolicy{SoftTTL: m.config.SQLiteTTL, HardTTL: m.config.SQLiteTTL}
+}
+
+// mergePolicies 合并默认策略和配置中的覆盖
+func mergePolicies(overrides map[string]models.CachePolicy) map[string]models.CachePolicy {
+	policies := make(map[string]models.CachePolicy, len(DefaultPolicies)+len(overrides))
+	for namespace, policy := range DefaultPolicies {
+		policies[namespace] = policy
+	}
+	for namespace, policy := range overrides {
+		policies[namespace] = policy
+	}
+	return policies
+}
+
+// GetJSONRefresh 获取并反序列化 JSON 缓存，条目已变旧时仍返回旧值，
+// 同时在后台调用 refresh 重新生成并写回缓存。同一个键同时只有一个刷新在进行。
+// refresh 不应使用请求的 context，请求结束后刷新仍会继续。
+func (m *CacheManager) GetJSONRefresh(key string, v any, refresh RefreshFunc) bool {
+	data, staleAt, found := m.lookup(key)
+	if !found {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false
+	}
+	if refresh != nil && !time.Now().Before(staleAt) {
+		m.refresh(key, refresh)
+	}
+	return true
+}
+
+// refresh 在后台重新生成变旧的条目，已有刷新进行中时跳过
+func (m *CacheManager) refresh(key string, refresh RefreshFunc) {
+	if _, running := m.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer m.refreshing.Delete(key)
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+
+		value, err := refresh(ctx)
+		if err != nil {
+			// 刷新失败时保留旧值，直到硬过期
+			log.Printf("[CacheManager] 刷新缓存 %s 失败: %v\n", key, err)
+			return
+		}
+		if err := m.SetJSON(key, value); err != nil {
+			log.Printf("[CacheManager] 写入刷新后的缓存 %s 失败: %v\n", key, err)
+		}
+	}()
+}
@@ -0,0 +1,327 @@
+// Package cache - Redis 持久化缓存实现
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisTimeout 单次 Redis 操作的超时时间
+	redisTimeout = 2 * time.Second
+	// redisScanCount SCAN 每批返回的键数
+	redisScanCount = 1000
+	// redisChannel 内存缓存失效通知的频道名（加上键前缀）
+	redisChannel = "invalidate"
+)
+
+// 每个条目是一个 Redis hash，以硬 TTL 作为键的过期时间
+const (
+	fieldValue   = "value"
+	fieldStale   = "stale"
+	fieldCreated = "created"
+)
+
+// RedisCache 基于 Redis 的持久化缓存，可由多个副本共享。
+// 写入和删除条目时通过 pub/sub 通知其他副本删除内存缓存中的对应条目。
+type RedisCache struct {
+	client  redis.UniversalClient
+	prefix  string
+	channel string
+	// source 当前副本的标识，用于忽略自己发出的失效通知
+	source string
+}
+
+// invalidation 内存缓存失效通知
+type invalidation struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// NewRedisCache creates a Redis cache on a client; all keys are stored under prefix.
+// Any client works, including one connected to an in-process Redis stand-in.
+func NewRedisCache(client redis.UniversalClient, prefix string) (*RedisCache, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisCache{
+		client:  client,
+		prefix:  prefix,
+		channel: prefix + redisChannel,
+		source:  uuid.NewString(),
+	}, nil
+}
+
+// Get 获取未过期的缓存值及其变旧时间和过期时间
+func (c *RedisCache) Get(key string) ([]byte, time.Time, time.Time, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	var fields *redis.SliceCmd
+	var ttl *redis.DurationCmd
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		fields = pipe.HMGet(ctx, c.prefix+key, fieldValue, fieldStale)
+		ttl = pipe.PTTL(ctx, c.prefix+key)
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, time.Time{}, time.Time{}, false
+	}
+	values := fields.Val()
+	if len(values) == 0 || values[0] == nil || ttl.Val() <= 0 {
+		return nil, time.Time{}, time.Time{}, false
+	}
+	value, _ := values[0].(string)
+	return []byte(value), unixMilli(values[1]), time.Now().Add(ttl.Val()), true
+}
+
+// Set 设置缓存值，条目在 softTTL 后变旧，在 hardTTL 后由 Redis 删除
+func (c *RedisCache) Set(key string, value []byte, softTTL, hardTTL time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	now := time.Now()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, c.prefix+key,
+			fieldValue, value,
+			fieldStale, now.Add(softTTL).UnixMilli(),
+			fieldCreated, now.UnixMilli(),
+		)
+		pipe.PExpire(ctx, c.prefix+key, hardTTL)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.publish(key)
+	return nil
+}
+
+// Delete 删除缓存值
+func (c *RedisCache) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, c.prefix+key).Err(); err != nil {
+		return err
+	}
+	c.publish(key)
+	return nil
+}
+
+// Cleanup 过期条目由 Redis 自动删除
+func (c *RedisCache) Cleanup() error {
+	return nil
+}
+
+// Count 获取缓存条目数量
+func (c *RedisCache) Count() (int, error) {
+	keys, err := c.scan(KeyFilter{})
+	return len(keys), err
+}
+
+// Close 关闭 Redis 连接
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// GetAll 获取所有未过期的缓存条目及其值（用于预热）
+func (c *RedisCache) GetAll() ([]Entry, error) {
+	keys, err := c.scan(KeyFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return c.entries(keys, true)
+}
+
+// List 按条件列出未过期的缓存条目（按键排序），返回一页条目和符合条件的总数
+func (c *RedisCache) List(filter KeyFilter, limit, offset int) ([]EntryInfo, int, error) {
+	keys, err := c.scan(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(keys)
+	keys = keys[min(offset, total):]
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	entries, err := c.entries(keys, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	infos := make([]EntryInfo, 0, len(entries))
+	for _, entry := range entries {
+		infos = append(infos, entry.EntryInfo)
+	}
+	return infos, total, nil
+}
+
+// Entry 获取未过期的缓存条目及其值
+func (c *RedisCache) Entry(key string) (*Entry, bool) {
+	entries, err := c.entries([]string{key}, true)
+	if err != nil || len(entries) == 0 {
+		return nil, false
+	}
+	return &entries[0], true
+}
+
+// Purge 删除符合条件的缓存条目，返回删除的键
+func (c *RedisCache) Purge(filter KeyFilter) ([]string, error) {
+	keys, err := c.scan(filter)
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	if err := c.client.Del(ctx, prefixed...).Err(); err != nil {
+		return nil, err
+	}
+	c.publish(keys...)
+	return keys, nil
+}
+
+// Watch 订阅其他副本的失效通知，直到 ctx 结束；连接断开时客户端自动重连
+func (c *RedisCache) Watch(ctx context.Context, invalidate func(keys []string)) {
+	sub := c.client.Subscribe(ctx, c.channel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var inv invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				log.Printf("[RedisCache] 无法解析失效通知: %v", err)
+				continue
+			}
+			if inv.Source != c.source {
+				invalidate(inv.Keys)
+			}
+		}
+	}
+}
+
+// publish 通知其他副本删除内存缓存中的条目；通知失败时其他副本的内存缓存最多在内存 TTL 后更新
+func (c *RedisCache) publish(keys ...string) {
+	payload, _ := json.Marshal(invalidation{Source: c.source, Keys: keys})
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := c.client.Publish(ctx, c.channel, payload).Err(); err != nil {
+		log.Printf("[RedisCache] 发布失效通知失败: %v", err)
+	}
+}
+
+// scan 按条件列出缓存键（不含前缀，已排序）
+func (c *RedisCache) scan(filter KeyFilter) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	seen := make(map[string]bool)
+	iter := c.client.Scan(ctx, 0, escapeGlob(c.prefix+filter.Prefix)+"*", redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		key := strings.TrimPrefix(iter.Val(), c.prefix)
+		if filter.Match(key) {
+			seen[key] = true
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// entries 批量读取条目的元数据（withValue 时包括值），跳过已过期的条目
+func (c *RedisCache) entries(keys []string, withValue bool) ([]Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	fields := make([]*redis.SliceCmd, len(keys))
+	sizes := make([]*redis.IntCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			if withValue {
+				fields[i] = pipe.HMGet(ctx, c.prefix+key, fieldStale, fieldCreated, fieldValue)
+			} else {
+				fields[i] = pipe.HMGet(ctx, c.prefix+key, fieldStale, fieldCreated)
+			}
+			sizes[i] = pipe.HStrLen(ctx, c.prefix+key, fieldValue)
+			ttls[i] = pipe.PTTL(ctx, c.prefix+key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(keys))
+	for i, key := range keys {
+		values := fields[i].Val()
+		ttl := ttls[i].Val()
+		if len(values) == 0 || values[0] == nil || ttl <= 0 {
+			continue
+		}
+		entry := Entry{EntryInfo: EntryInfo{
+			Key:       key,
+			Size:      int(sizes[i].Val()),
+			StaleAt:   unixMilli(values[0]),
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: unixMilli(values[1]),
+		}}
+		if withValue {
+			value, _ := values[2].(string)
+			entry.Value = []byte(value)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// unixMilli 解析以毫秒时间戳保存的字段
+func unixMilli(field any) time.Time {
+	s, _ := field.(string)
+	ms, _ := strconv.ParseInt(s, 10, 64)
+	return time.UnixMilli(ms)
+}
+
+// escapeGlob 转义 Redis SCAN MATCH 模式中的特殊字符
+func escapeGlob(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const testPrefix = "test:"
+
+func newTestRedisCache(t *testing.T, mr *miniredis.Miniredis) *RedisCache {
+	t.Helper()
+	c, err := NewRedisCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), testPrefix)
+	if err != nil {
+		t.Fatalf("NewRedisCache: %v", err)
+	}
+	return c
+}
+
+func TestRedisCacheGetSet(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr)
+	defer c.Close()
+
+	if _, _, _, found := c.Get("recipes:missing"); found {
+		t.Fatal("Get of a missing key found an entry")
+	}
+
+	before := time.Now()
+	if err := c.Set("recipes:a", []byte("fresh"), time.Hour, 2*time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, staleAt, expiresAt, found := c.Get("recipes:a")
+	if !found || string(value) != "fresh" {
+		t.Fatalf("Get = %q, %v; want fresh", value, found)
+	}
+	if staleAt.Before(before.Add(time.Hour).Add(-time.Second)) || staleAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("staleAt = %v, want about an hour from now", staleAt)
+	}
+	if expiresAt.Before(before.Add(2*time.Hour).Add(-time.Second)) || expiresAt.After(time.Now().Add(2*time.Hour)) {
+		t.Errorf("expiresAt = %v, want about two hours from now", expiresAt)
+	}
+
+	// 变旧的条目仍可读取，到硬 TTL 后由 Redis 删除
+	if err := c.Set("recipes:stale", []byte("stale"), 0, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	value, staleAt, _, found = c.Get("recipes:stale")
+	if !found || string(value) != "stale" {
+		t.Fatalf("Get of a stale entry = %q, %v; want stale", value, found)
+	}
+	if staleAt.After(time.Now()) {
+		t.Errorf("staleAt = %v, want in the past", staleAt)
+	}
+	mr.FastForward(time.Minute + time.Second)
+	if _, _, _, found := c.Get("recipes:stale"); found {
+		t.Error("Get found an entry past its hard TTL")
+	}
+	if _, _, _, found := c.Get("recipes:a"); !found {
+		t.Error("Get lost an entry before its hard TTL")
+	}
+}
+
+func TestRedisCacheDelete(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr)
+	defer c.Close()
+
+	if err := c.Set("recipes:a", []byte("a"), time.Hour, time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Delete("recipes:a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, _, found := c.Get("recipes:a"); found {
+		t.Error("Get found a deleted entry")
+	}
+	if mr.Exists(testPrefix + "recipes:a") {
+		t.Error("deleted entry is still stored in Redis")
+	}
+}
+
+func TestRedisCachePurge(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c := newTestRedisCache(t, mr)
+	defer c.Close()
+
+	for _, key := range []string{"recipes:a", "recipes:b", "substitutes:a"} {
+		if err := c.Set(key, []byte(key), time.Hour, time.Hour); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+	}
+
+	keys, err := c.Purge(NewKeyFilter("recipes:", ""))
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "recipes:a" || keys[1] != "recipes:b" {
+		t.Errorf("Purge = %v, want [recipes:a recipes:b]", keys)
+	}
+	for _, key := range []string{"recipes:a", "recipes:b"} {
+		if _, _, _, found := c.Get(key); found {
+			t.Errorf("Get found purged entry %s", key)
+		}
+	}
+	if _, _, _, found := c.Get("substitutes:a"); !found {
+		t.Error("Purge removed an entry of another namespace")
+	}
+	if count, err := c.Count(); err != nil || count != 1 {
+		t.Errorf("Count = %d, %v; want 1", count, err)
+	}
+}
+
+func TestRedisCacheInvalidatesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	config := models.DefaultCacheConfig
+	config.MemoryTTL = time.Hour
+
+	writer := NewCacheManagerWithBackend(config, newTestRedisCache(t, mr))
+	defer writer.Close()
+	reader := NewCacheManagerWithBackend(config, newTestRedisCache(t, mr))
+	defer reader.Close()
+
+	// 等待两个副本都订阅失效通知
+	channel := testPrefix + redisChannel
+	waitFor(t, "subscriptions", func() bool {
+		return mr.PubSubNumSub(channel)[channel] == 2
+	})
+
+	key := NewKey(NamespaceRecipes).Field("id", "1").String()
+	if err := writer.Set(key, []byte("v1")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	// 读取后回填到读取方的内存缓存
+	if value, found := reader.Get(key); !found || string(value) != "v1" {
+		t.Fatalf("reader Get = %q, %v; want v1", value, found)
+	}
+	if !reader.memory.Has(key) {
+		t.Fatal("reader did not cache the entry in memory")
+	}
+
+	if err := writer.Set(key, []byte("v2")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	waitFor(t, "invalidation after Set", func() bool { return !reader.memory.Has(key) })
+	if value, found := reader.Get(key); !found || string(value) != "v2" {
+		t.Fatalf("reader Get after update = %q, %v; want v2", value, found)
+	}
+
+	if err := writer.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	waitFor(t, "invalidation after Delete", func() bool { return !reader.memory.Has(key) })
+	if _, found := reader.Get(key); found {
+		t.Error("reader Get found an entry deleted by another replica")
+	}
+
+	// 自己发出的通知不删除自己的内存缓存
+	if err := writer.Set(key, []byte("v3")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !writer.memory.Has(key) {
+		t.Error("writer dropped its own entry on its own invalidation")
+	}
+}
+
+// waitFor 等待条件成立，最多两秒
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -40,32 +40,52 @@ func NewSQLiteCache(dbPath string) (*SQLiteCache, error) {
 		db.Close()
 		return nil, err
 	}
+	if err := migrateStaleAt(db); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	return &SQLiteCache{db: db}, nil
 }
 
-// Get 获取缓存值
-func (c *SQLiteCache) Get(key string) ([]byte, bool) {
+// migrateStaleAt 为旧版本的缓存表添加 stale_at 列（条目变旧的时间），
+// 已有条目视为在过期前一直新鲜
+func migrateStaleAt(db *sql.DB) error {
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('cache_entries') WHERE name = 'stale_at'").Scan(&exists)
+	if err != nil || exists > 0 {
+		return err
+	}
+	if _, err := db.Exec("ALTER TABLE cache_entries ADD COLUMN stale_at INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	_, err = db.Exec("UPDATE cache_entries SET stale_at = expires_at")
+	return err
+}
+
+// Get 获取未过期的缓存值及其变旧时间和过期时间
+func (c *SQLiteCache) Get(key string) ([]byte, time.Time, time.Time, bool) {
 	var value []byte
+	var staleAt, expiresAt int64
 	err := c.db.QueryRow(
-		"SELECT value FROM cache_entries WHERE key = ? AND expires_at > ?",
+		"SELECT value, stale_at, expires_at FROM cache_entries WHERE key = ? AND expires_at > ?",
 		key, time.Now().Unix(),
-	).Scan(&value)
+	).Scan(&value, &staleAt, &expiresAt)
 	if err != nil {
-		return nil, false
+		return nil, time.Time{}, time.Time{}, false
 	}
-	return value, true
+	return value, time.Unix(staleAt, 0), time.Unix(expiresAt, 0), true
 }
 
-// Set 设置缓存值
-func (c *SQLiteCache) Set(key string, value []byte, ttl time.Duration) error {
+// Set 设置缓存值，条目在 softTTL 后变旧，在 hardTTL 后过期
+func (c *SQLiteCache) Set(key string, value []byte, softTTL, hardTTL time.Duration) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	expiresAt := time.Now().Add(ttl).Unix()
+	now := time.Now()
 	_, err := c.db.Exec(
-		"INSERT OR REPLACE INTO cache_entries (key, value, expires_at, created_at) VALUES (?, ?, ?, ?)",
-		key, value, expiresAt, time.Now().Unix(),
+		"INSERT OR REPLACE INTO cache_entries (key, value, stale_at, expires_at, created_at) VALUES (?, ?, ?, ?, ?)",
+		key, value, now.Add(softTTL).Unix(), now.Add(hardTTL).Unix(), now.Unix(),
 	)
 	return err
 }
@@ -103,10 +123,10 @@ func (c *SQLiteCache) Close() error {
 	return c.db.Close()
 }
 
-// GetAll 获取所有未过期的缓存键（用于预热）
-func (c *SQLiteCache) GetAll() (map[string][]byte, error) {
+// GetAll 获取所有未过期的缓存条目及其值（用于预热）
+func (c *SQLiteCache) GetAll() ([]Entry, error) {
 	rows, err := c.db.Query(
-		"SELECT key, value FROM cache_entries WHERE expires_at > ?",
+		"SELECT key, value, stale_at, expires_at, created_at FROM cache_entries WHERE expires_at > ?",
 		time.Now().Unix(),
 	)
 	if err != nil {
@@ -114,14 +134,109 @@ func (c *SQLiteCache) GetAll() (map[string][]byte, error) {
 	}
 	defer rows.Close()
 
-	result := make(map[string][]byte)
+	var result []Entry
 	for rows.Next() {
-		var key string
-		var value []byte
-		if err := rows.Scan(&key, &value); err != nil {
+		var entry Entry
+		var staleAt, expiresAt, createdAt int64
+		if err := rows.Scan(&entry.Key, &entry.Value, &staleAt, &expiresAt, &createdAt); err != nil {
 			continue
 		}
-		result[key] = value
+		entry.Size = len(entry.Value)
+		entry.StaleAt = time.Unix(staleAt, 0)
+		entry.ExpiresAt = time.Unix(expiresAt, 0)
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		result = append(result, entry)
 	}
 	return result, rows.Err()
 }
+
+// List 按条件列出未过期的缓存条目（按键排序），返回一页条目和符合条件的总数
+func (c *SQLiteCache) List(filter KeyFilter, limit, offset int) ([]EntryInfo, int, error) {
+	rows, err := c.db.Query(
+		"SELECT key, length(value), stale_at, expires_at, created_at FROM cache_entries WHERE expires_at > ? AND substr(key, 1, length(?)) = ? ORDER BY key",
+		time.Now().Unix(), filter.Prefix, filter.Prefix,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]EntryInfo, 0)
+	total := 0
+	for rows.Next() {
+		var info EntryInfo
+		var staleAt, expiresAt, createdAt int64
+		if err := rows.Scan(&info.Key, &info.Size, &staleAt, &expiresAt, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		if !filter.Match(info.Key) {
+			continue
+		}
+		total++
+		if total <= offset || (limit > 0 && len(entries) >= limit) {
+			continue
+		}
+		info.StaleAt = time.Unix(staleAt, 0)
+		info.ExpiresAt = time.Unix(expiresAt, 0)
+		info.CreatedAt = time.Unix(createdAt, 0)
+		entries = append(entries, info)
+	}
+	return entries, total, rows.Err()
+}
+
+// Entry 获取未过期的缓存条目及其值
+func (c *SQLiteCache) Entry(key string) (*Entry, bool) {
+	entry := &Entry{EntryInfo: EntryInfo{Key: key}}
+	var staleAt, expiresAt, createdAt int64
+	err := c.db.QueryRow(
+		"SELECT value, stale_at, expires_at, created_at FROM cache_entries WHERE key = ? AND expires_at > ?",
+		key, time.Now().Unix(),
+	).Scan(&entry.Value, &staleAt, &expiresAt, &createdAt)
+	if err != nil {
+		return nil, false
+	}
+	entry.Size = len(entry.Value)
+	entry.StaleAt = time.Unix(staleAt, 0)
+	entry.ExpiresAt = time.Unix(expiresAt, 0)
+	entry.CreatedAt = time.Unix(createdAt, 0)
+	return entry, true
+}
+
+// Purge 删除符合条件的缓存条目（包括已过期的），返回删除的键
+func (c *SQLiteCache) Purge(filter KeyFilter) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT key FROM cache_entries WHERE substr(key, 1, length(?)) = ?", filter.Prefix, filter.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if filter.Match(key) {
+			keys = append(keys, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, err := tx.Exec("DELETE FROM cache_entries WHERE key = ?", key); err != nil {
+			return nil, err
+		}
+	}
+	return keys, tx.Commit()
+}
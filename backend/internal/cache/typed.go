@@ -0,0 +1,73 @@
+// Package cache - 基于双层缓存管理器的泛型类型化缓存
+package cache
+
+import (
+	"log"
+)
+
+// Load reads a cached value of type T by key from a cache manager; a nil manager
+// (caching disabled) always misses
+func Load[T any](m *CacheManager, key string) (T, bool) {
+	var v T
+	if m == nil || !m.GetJSON(key, &v) {
+		var zero T
+		return zero, false
+	}
+	return v, true
+}
+
+// Store caches a value of type T by key in a cache manager; a nil manager stores nothing
+func Store[T any](m *CacheManager, key string, v T) error {
+	if m == nil {
+		return nil
+	}
+	return m.SetJSON(key, v)
+}
+
+// Typed is a typed view of one namespace of the two-tier cache, keyed by ID.
+// Values are persisted by the cache manager and expire by the namespace policy,
+// so they survive restarts and are shared by replicas using a shared backend.
+type Typed[T any] struct {
+	namespace string
+	// manager 为 nil 时使用调用时的 DefaultManager，可在缓存管理器初始化前创建
+	manager *CacheManager
+}
+
+// NewTyped creates a typed view of a namespace; a nil manager means DefaultManager
+func NewTyped[T any](manager *CacheManager, namespace string) *Typed[T] {
+	return &Typed[T]{namespace: namespace, manager: manager}
+}
+
+// Key returns the cache key of an ID: {namespace}:v{version}:id={id}
+func (t *Typed[T]) Key(id string) string {
+	return NewKey(t.namespace).Field("id", id).String()
+}
+
+// Get reads the cached value of an ID
+func (t *Typed[T]) Get(id string) (T, bool) {
+	return Load[T](t.resolve(), t.Key(id))
+}
+
+// Set caches the value of an ID; failures are logged, the cache being best effort
+func (t *Typed[T]) Set(id string, v T) {
+	if err := Store(t.resolve(), t.Key(id), v); err != nil {
+		log.Printf("[Cache] 缓存写入失败 %s: %v", t.Key(id), err)
+	}
+}
+
+// Delete removes the cached value of an ID
+func (t *Typed[T]) Delete(id string) {
+	if m := t.resolve(); m != nil {
+		if err := m.Delete(t.Key(id)); err != nil {
+			log.Printf("[Cache] 缓存删除失败 %s: %v", t.Key(id), err)
+		}
+	}
+}
+
+// resolve 返回实际使用的缓存管理器
+func (t *Typed[T]) resolve() *CacheManager {
+	if t.manager != nil {
+		return t.manager
+	}
+	return DefaultManager
+}
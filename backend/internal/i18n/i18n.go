@@ -16,6 +16,9 @@ type Locale struct {
 	Categories map[string]string `json:"categories"`
 	Seasons    map[string]string `json:"seasons"`
 	Difficulty map[string]string `json:"difficulty"`
+	Allergens  map[string]string `json:"allergens"`
+	Sections   map[string]string `json:"sections"`
+	Cuisines   map[string]string `json:"cuisines"`
 	Prompts    map[string]string `json:"prompts"`
 	Messages   map[string]string `json:"messages"`
 }
@@ -154,6 +157,42 @@ func GetDifficulty(lang, code string) string {
 	return code
 }
 
+// GetAllergen returns the translated allergen name
+func GetAllergen(lang, code string) string {
+	locale := GetLocale(lang)
+	if locale == nil {
+		return code
+	}
+	if name, ok := locale.Allergens[code]; ok {
+		return name
+	}
+	return code
+}
+
+// GetSection returns the translated store section name
+func GetSection(lang, code string) string {
+	locale := GetLocale(lang)
+	if locale == nil {
+		return code
+	}
+	if name, ok := locale.Sections[code]; ok {
+		return name
+	}
+	return code
+}
+
+// GetCuisine returns the translated cuisine name
+func GetCuisine(lang, code string) string {
+	locale := GetLocale(lang)
+	if locale == nil {
+		return code
+	}
+	if name, ok := locale.Cuisines[code]; ok {
+		return name
+	}
+	return code
+}
+
 // GetPrompt returns the prompt template for the given key
 func GetPrompt(lang, key string) string {
 	locale := GetLocale(lang)
@@ -9,19 +9,40 @@ type CacheConfig struct {
 	MemoryTTL      time.Duration `json:"memoryTTL"`
 	MemoryMaxItems int           `json:"memoryMaxItems"`
 
+	// Backend 持久化缓存后端：sqlite（默认）或 redis
+	Backend string `json:"backend"`
+
 	// SQLite持久化缓存
 	SQLiteTTL           time.Duration `json:"sqliteTTL"`
 	SQLiteCleanInterval time.Duration `json:"sqliteCleanInterval"`
 	SQLitePath          string        `json:"sqlitePath"`
+
+	// Redis持久化缓存，多个副本共享，并通过 pub/sub 使其他副本的内存缓存失效
+	RedisURL    string `json:"-"`
+	RedisPrefix string `json:"redisPrefix"`
+
+	// Policies 按命名空间覆盖默认的过期策略
+	Policies map[string]CachePolicy `json:"policies,omitempty"`
+}
+
+// CachePolicy 缓存命名空间的过期策略
+type CachePolicy struct {
+	// SoftTTL 超过后条目变旧：仍返回旧值，同时在后台重新生成
+	SoftTTL time.Duration `json:"softTTL"`
+	// HardTTL 超过后条目过期，不再返回
+	HardTTL time.Duration `json:"hardTTL"`
 }
 
 // DefaultCacheConfig 默认配置
 var DefaultCacheConfig = CacheConfig{
 	MemoryTTL:           1 * time.Hour,
 	MemoryMaxItems:      1000,
+	Backend:             "sqlite",
 	SQLiteTTL:           7 * 24 * time.Hour,
 	SQLiteCleanInterval: 1 * time.Hour,
 	SQLitePath:          "./data/cache.db",
+	RedisURL:            "redis://localhost:6379/0",
+	RedisPrefix:         "eois:cache:",
 }
 
 // CacheEntry SQLite持久化缓存的数据条目
@@ -207,18 +207,30 @@ type Location struct {
 	Country     string `json:"country,omitempty"`
 	Season      string `json:"season"`
 	Month       int    `json:"month"`
+	// LocalDate 城市当地日期（YYYY-MM-DD），用于判断食材所处的应季阶段
+	LocalDate string `json:"localDate,omitempty"`
 }
 
 // SeasonalIngredient 应季食材
 type SeasonalIngredient struct {
-	ID           string              `json:"id"`
-	Name         string              `json:"name"`
-	Category     IngredientCategory  `json:"category"`
-	BriefIntro   string              `json:"briefIntro"`
-	DetailedInfo *IngredientDetail   `json:"detailedInfo,omitempty"`
-	SeasonMonths []int               `json:"seasonMonths"`
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Category     IngredientCategory `json:"category"`
+	BriefIntro   string             `json:"briefIntro"`
+	DetailedInfo *IngredientDetail  `json:"detailedInfo,omitempty"`
+	SeasonMonths []int              `json:"seasonMonths"`
+	Phase        SeasonPhase        `json:"phase,omitempty"`
 }
 
+// SeasonPhase 食材在应季期内所处的阶段
+type SeasonPhase string
+
+const (
+	PhaseArriving SeasonPhase = "arriving"
+	PhasePeak     SeasonPhase = "peak"
+	PhaseEnding   SeasonPhase = "ending"
+)
+
 // IngredientDetail 食材详情
 type IngredientDetail struct {
 	SeasonReason  string `json:"seasonReason"`
@@ -251,28 +263,157 @@ type GetIngredientDetailResponse struct {
 	Ingredient SeasonalIngredient `json:"ingredient"`
 }
 
+// Availability 食材在某个月份的供应状态
+type Availability string
+
+const (
+	AvailabilityNone      Availability = "none"
+	AvailabilityAvailable Availability = "available"
+	AvailabilityPeak      Availability = "peak"
+)
+
+// MonthAvailability 食材某个月份的供应状态
+type MonthAvailability struct {
+	Month        int          `json:"month"`
+	Availability Availability `json:"availability"`
+}
+
+// IngredientCalendar 食材全年应季日历
+type IngredientCalendar struct {
+	ID         string              `json:"id,omitempty"`
+	Name       string              `json:"name"`
+	Category   IngredientCategory  `json:"category"`
+	Months     []int               `json:"months"`
+	PeakMonths []int               `json:"peakMonths"`
+	Profile    []MonthAvailability `json:"profile"`
+}
+
+// CalendarMonth 应季日历中某个月份的食材
+type CalendarMonth struct {
+	Month     int      `json:"month"`
+	Peak      []string `json:"peak"`
+	Available []string `json:"available"`
+}
+
+// GetIngredientCalendarResponse 食材全年应季日历响应
+type GetIngredientCalendarResponse struct {
+	Location   Location           `json:"location"`
+	Ingredient IngredientCalendar `json:"ingredient"`
+}
+
+// SeasonTransition 即将上市或即将下市的食材
+type SeasonTransition struct {
+	Name     string             `json:"name"`
+	Category IngredientCategory `json:"category"`
+	// Date 上市日期（应季期第一天）或下市日期（应季期最后一天）
+	Date   string `json:"date"`
+	InDays int    `json:"inDays"`
+	Months []int  `json:"months"`
+}
+
+// GetSeasonTransitionsResponse 未来若干周内上市与下市的食材
+type GetSeasonTransitionsResponse struct {
+	Location Location           `json:"location"`
+	Weeks    int                `json:"weeks"`
+	Arriving []SeasonTransition `json:"arriving"`
+	Leaving  []SeasonTransition `json:"leaving"`
+}
+
+// GetSeasonalCalendarResponse 地区应季日历响应（按月份的食材网格）
+type GetSeasonalCalendarResponse struct {
+	Location    Location             `json:"location"`
+	Ingredients []IngredientCalendar `json:"ingredients"`
+	Months      []CalendarMonth      `json:"months"`
+}
+
 // GetRecipesByIngredientsRequest 根据食材获取菜谱推荐请求
 type GetRecipesByIngredientsRequest struct {
 	Ingredients []string `json:"ingredients"`
 	Preference  string   `json:"preference,omitempty" binding:"max=500"`
 	Location    string   `json:"location,omitempty"`
+	Allergens   []string `json:"allergens,omitempty" binding:"max=20"`
+	// UsePantry 结合用户的食材库存推荐，优先消耗临期食材
+	UsePantry bool `json:"usePantry,omitempty"`
+	// MaxCookingMinutes 最长烹饪时间（分钟），0 表示不限
+	MaxCookingMinutes int `json:"maxCookingMinutes,omitempty" binding:"min=0,max=1440"`
+	// Cuisine 偏好的菜系：菜系代码或名称，"local" 表示城市当地菜系
+	Cuisine string `json:"cuisine,omitempty" binding:"max=50"`
+	// Leftovers 需要用完的剩余食材，非空时按减少浪费优先推荐
+	Leftovers []Leftover `json:"leftovers,omitempty" binding:"max=20,dive"`
+	// MinSeasonality 最低应季评分（0-1），0 表示不限
+	MinSeasonality float64 `json:"minSeasonality,omitempty" binding:"min=0,max=1"`
+	// SortBy 排序方式：空（默认，有剩余食材时按剩余食材用量，结合库存时按库存匹配，否则按应季评分）、
+	// leftovers、seasonality、cookingTime
+	SortBy RecipeSort `json:"sortBy,omitempty"`
+}
+
+// RecipeSort 推荐菜谱的排序方式
+type RecipeSort string
+
+const (
+	RecipeSortDefault     RecipeSort = ""
+	RecipeSortLeftovers   RecipeSort = "leftovers"
+	RecipeSortSeasonality RecipeSort = "seasonality"
+	RecipeSortCookingTime RecipeSort = "cookingTime"
+)
+
+// Leftover 剩余食材及大致剩余量
+type Leftover struct {
+	Name   string `json:"name" binding:"required,max=50"`
+	Amount string `json:"amount,omitempty" binding:"max=50"`
+}
+
+// LeftoverUsage 菜谱对某种剩余食材的用量
+type LeftoverUsage struct {
+	Name       string `json:"name"`
+	Available  string `json:"available,omitempty"`
+	UsedAmount string `json:"usedAmount,omitempty"`
+	// UsedGrams 估算的用量（克）
+	UsedGrams float64 `json:"usedGrams"`
+	// Fraction 用掉的比例（0-1）
+	Fraction float64 `json:"fraction"`
 }
 
 // RecipeWithMatch 带匹配信息的菜谱
 type RecipeWithMatch struct {
-	ID                 string   `json:"id"`
-	Title              string   `json:"title"`
-	Description        string   `json:"description"`
-	MatchedIngredients []string `json:"matchedIngredients"`
-	MatchCount         int      `json:"matchCount"`
-	CookingTime        string   `json:"cookingTime"`
-	Difficulty         string   `json:"difficulty"`
-	Tags               []string `json:"tags,omitempty"`
+	ID                  string          `json:"id"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	Ingredients         []string        `json:"ingredients,omitempty"`
+	MatchedIngredients  []string        `json:"matchedIngredients"`
+	MatchCount          int             `json:"matchCount"`
+	SeasonalIngredients []string        `json:"seasonalIngredients"`
+	SeasonalityScore    float64         `json:"seasonalityScore"`
+	LeftoverUsage       []LeftoverUsage `json:"leftoverUsage,omitempty"`
+	LeftoverScore       float64         `json:"leftoverScore,omitempty"`
+	WasteAvoidedGrams   int             `json:"wasteAvoidedGrams,omitempty"`
+	PantryIngredients   []string        `json:"pantryIngredients,omitempty"`
+	MissingIngredients  []string        `json:"missingIngredients"`
+	MissingCount        int             `json:"missingCount"`
+	CookingTime         string          `json:"cookingTime"`
+	CookingTimeSeconds  int             `json:"cookingTimeSeconds,omitempty"`
+	Difficulty          string          `json:"difficulty"`
+	Cuisine             Cuisine         `json:"cuisine"`
+	Tags                []string        `json:"tags,omitempty"`
+	Allergens           []Allergen      `json:"allergens"`
+	// PreferenceScore 与用户历史评价的契合度（-1 到 1），无评价时为 0
+	PreferenceScore float64 `json:"preferenceScore,omitempty"`
 }
 
 // GetRecipesByIngredientsResponse 根据食材获取菜谱推荐响应
 type GetRecipesByIngredientsResponse struct {
-	Recipes []RecipeWithMatch `json:"recipes"`
+	Recipes          []RecipeWithMatch `json:"recipes"`
+	CheckedAllergens []Allergen        `json:"checkedAllergens"`
+	// PantryItems 参与推荐的库存食材（按临期排序）
+	PantryItems []string `json:"pantryItems,omitempty"`
+	// Cuisine 请求的菜系（"local" 已解析为具体菜系，无法解析时为空）
+	Cuisine Cuisine `json:"cuisine,omitempty"`
+	// WasteAvoidedGrams 排名第一的菜谱可避免浪费的剩余食材（克，估算）
+	WasteAvoidedGrams int `json:"wasteAvoidedGrams,omitempty"`
+	// Personalized 是否已按用户的历史评价调整推荐
+	Personalized bool `json:"personalized,omitempty"`
+	// DuplicatesRemoved 因与排名更靠前的菜谱近似重复而去除的推荐数
+	DuplicatesRemoved int `json:"duplicatesRemoved,omitempty"`
 }
 
 // NewRecipeDetail 新的菜谱详情结构
@@ -283,18 +424,47 @@ type NewRecipeDetail struct {
 	Ingredients []RecipeIngredient `json:"ingredients"`
 	Steps       []NewCookingStep   `json:"steps"`
 	CookingTime string             `json:"cookingTime"`
-	Servings    string             `json:"servings"`
-	Difficulty  string             `json:"difficulty"`
-	Tags        []string           `json:"tags,omitempty"`
-	Tips        string             `json:"tips,omitempty"`
-	ImageUrl    string             `json:"imageUrl,omitempty"`
+	// CookingTimeSeconds 由 CookingTime 解析出的总时长（秒），无法解析时为 0
+	CookingTimeSeconds int           `json:"cookingTimeSeconds,omitempty"`
+	Timing             *RecipeTiming `json:"timing,omitempty"`
+	Servings           string        `json:"servings"`
+	Difficulty         string        `json:"difficulty"`
+	Cuisine            Cuisine       `json:"cuisine,omitempty"`
+	Tags               []string      `json:"tags,omitempty"`
+	Tips               string        `json:"tips,omitempty"`
+	ImageUrl           string        `json:"imageUrl,omitempty"`
+	Allergens          []Allergen    `json:"allergens"`
+	// MissingSeasonalIngredients 推荐依据的应季食材中，重新生成后详情仍未用到的部分
+	MissingSeasonalIngredients []string `json:"missingSeasonalIngredients,omitempty"`
+}
+
+// RecommendationContext 生成某道推荐菜谱时的上下文，持久化后用于生成该菜谱的详情
+type RecommendationContext struct {
+	RecipeID    string   `json:"recipeId"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Ingredients []string `json:"ingredients,omitempty"`
+	// SelectedIngredients 用户选择的食材
+	SelectedIngredients []string `json:"selectedIngredients,omitempty"`
+	MatchedIngredients  []string `json:"matchedIngredients,omitempty"`
+	// SeasonalIngredients 菜谱用到的应季食材（推荐依据）
+	SeasonalIngredients []string   `json:"seasonalIngredients,omitempty"`
+	Location            string     `json:"location,omitempty"`
+	Preference          string     `json:"preference,omitempty"`
+	Allergens           []Allergen `json:"allergens,omitempty"`
+	Cuisine             Cuisine    `json:"cuisine,omitempty"`
+	Leftovers           []Leftover `json:"leftovers,omitempty"`
+	MaxCookingMinutes   int        `json:"maxCookingMinutes,omitempty"`
+	Lang                string     `json:"lang"`
+	CreatedAt           time.Time  `json:"createdAt"`
 }
 
 // RecipeIngredient 菜谱食材
 type RecipeIngredient struct {
-	Name   string `json:"name"`
-	Amount string `json:"amount"`
-	Note   string `json:"note,omitempty"`
+	Name      string     `json:"name"`
+	Amount    string     `json:"amount"`
+	Note      string     `json:"note,omitempty"`
+	Allergens []Allergen `json:"allergens,omitempty"`
 }
 
 // NewCookingStep 新的烹饪步骤结构
@@ -302,14 +472,685 @@ type NewCookingStep struct {
 	StepNumber  int    `json:"stepNumber"`
 	Instruction string `json:"instruction"`
 	Duration    string `json:"duration,omitempty"`
+	// DurationSeconds 由 Duration（或步骤说明中的时长）解析出的秒数，供客户端计时
+	DurationSeconds int      `json:"durationSeconds,omitempty"`
+	Mode            StepMode `json:"mode"`
+}
+
+// StepMode 步骤是否需要一直操作
+type StepMode string
+
+const (
+	// StepActive 需要持续操作（切、炒、翻面等）
+	StepActive StepMode = "active"
+	// StepPassive 等待即可（腌制、炖煮、烘烤、静置等）
+	StepPassive StepMode = "passive"
+)
+
+// RecipeTiming 菜谱时间汇总
+type RecipeTiming struct {
+	TotalSeconds   int `json:"totalSeconds"`
+	StepsSeconds   int `json:"stepsSeconds"`
+	ActiveSeconds  int `json:"activeSeconds"`
+	PassiveSeconds int `json:"passiveSeconds"`
+	TimedSteps     int `json:"timedSteps"`
+	// Consistent 步骤时长之和与总时长大致吻合（误差不超过 25% 或 5 分钟）
+	Consistent bool `json:"consistent"`
 }
 
 // GetNewRecipeDetailResponse 获取新菜谱详情响应
 type GetNewRecipeDetailResponse struct {
-	Recipe NewRecipeDetail `json:"recipe"`
+	Recipe           NewRecipeDetail `json:"recipe"`
+	CheckedAllergens []Allergen      `json:"checkedAllergens"`
+	// Revision 用户编辑过该菜谱时为当前修订号，返回的是用户的版本
+	Revision int `json:"revision,omitempty"`
+	// Conflicts 用户的版本不会重新生成，含声明的过敏原时在此列出
+	Conflicts []AllergenConflict `json:"conflicts,omitempty"`
+}
+
+// CulinaryRole 食材在菜谱中的烹饪作用
+type CulinaryRole string
+
+const (
+	RoleAcid      CulinaryRole = "acid"
+	RoleAromatic  CulinaryRole = "aromatic"
+	RoleProtein   CulinaryRole = "protein"
+	RoleThickener CulinaryRole = "thickener"
+	RoleFat       CulinaryRole = "fat"
+	RoleSweetener CulinaryRole = "sweetener"
+	RoleUmami     CulinaryRole = "umami"
+	RoleVegetable CulinaryRole = "vegetable"
+	RoleStarch    CulinaryRole = "starch"
+	RoleOther     CulinaryRole = "other"
+)
+
+// Substitute 食材替代品
+type Substitute struct {
+	Name string       `json:"name"`
+	Role CulinaryRole `json:"role"`
+	// RoleMatch 替代品与原食材的烹饪作用是否相同
+	RoleMatch bool `json:"roleMatch"`
+	// Ratio 替代品用量与原用量之比
+	Ratio float64 `json:"ratio"`
+	// Amount 按菜谱原用量换算后的用量（仅在指定菜谱时提供）
+	Amount   string `json:"amount,omitempty"`
+	Notes    string `json:"notes"`
+	InSeason bool   `json:"inSeason"`
+}
+
+// GetSubstitutesResponse 食材替代品响应（按烹饪作用和应季程度排序）
+type GetSubstitutesResponse struct {
+	Ingredient  string       `json:"ingredient"`
+	Role        CulinaryRole `json:"role"`
+	RecipeID    string       `json:"recipeId,omitempty"`
+	Amount      string       `json:"amount,omitempty"`
+	Substitutes []Substitute `json:"substitutes"`
+}
+
+// ApplySubstitutionRequest 在菜谱中替换食材的请求
+type ApplySubstitutionRequest struct {
+	Ingredient string `json:"ingredient" binding:"required,max=50"`
+	Substitute string `json:"substitute" binding:"required,max=50"`
+	City       string `json:"city,omitempty" binding:"max=100"`
+	// Allergens 用户声明的过敏原，替换后的菜谱含这些过敏原时拒绝替换
+	Allergens []string `json:"allergens,omitempty" binding:"max=20"`
+}
+
+// ApplySubstitutionResponse 替换食材后的菜谱
+type ApplySubstitutionResponse struct {
+	Recipe     NewRecipeDetail `json:"recipe"`
+	Original   string          `json:"original"`
+	Substitute Substitute      `json:"substitute"`
 }
 
 // ImageURLResponse 图片URL响应
 type ImageURLResponse struct {
 	ImageURL string `json:"imageUrl"`
 }
+
+// --- 过敏原 ---
+
+// Allergen 过敏原代码 (code 值，用于 i18n 翻译)
+type Allergen string
+
+// 美国 FDA 公布的九大过敏原
+const (
+	AllergenMilk      Allergen = "milk"
+	AllergenEgg       Allergen = "egg"
+	AllergenFish      Allergen = "fish"
+	AllergenShellfish Allergen = "shellfish" // 甲壳类（虾、蟹、龙虾）
+	AllergenTreeNut   Allergen = "tree_nut"
+	AllergenPeanut    Allergen = "peanut"
+	AllergenWheat     Allergen = "wheat"
+	AllergenSoy       Allergen = "soy"
+	AllergenSesame    Allergen = "sesame"
+)
+
+// 地区性过敏原（欧盟 14 类、日韩标示要求等）
+const (
+	AllergenMollusc   Allergen = "mollusc"   // 软体类（贝、鱿鱼、章鱼）
+	AllergenGluten    Allergen = "gluten"    // 含麸质谷物（大麦、黑麦、燕麦）
+	AllergenCelery    Allergen = "celery"    // 芹菜
+	AllergenMustard   Allergen = "mustard"   // 芥末
+	AllergenLupin     Allergen = "lupin"     // 羽扇豆
+	AllergenSulphites Allergen = "sulphites" // 亚硫酸盐
+	AllergenBuckwheat Allergen = "buckwheat" // 荞麦
+)
+
+// Cuisine 菜系代码 (code 值，用于 i18n 翻译)
+type Cuisine string
+
+const (
+	CuisineCantonese     Cuisine = "cantonese"
+	CuisineSichuan       Cuisine = "sichuan"
+	CuisineHunan         Cuisine = "hunan"
+	CuisineShandong      Cuisine = "shandong"
+	CuisineJiangnan      Cuisine = "jiangnan" // 江浙沪（苏菜、浙菜、本帮菜、淮扬菜）
+	CuisineFujian        Cuisine = "fujian"
+	CuisineAnhui         Cuisine = "anhui"
+	CuisineChaozhou      Cuisine = "chaozhou"
+	CuisineHakka         Cuisine = "hakka"
+	CuisineBeijing       Cuisine = "beijing"
+	CuisineNortheastern  Cuisine = "northeastern"
+	CuisineNorthwestern  Cuisine = "northwestern"
+	CuisineYunnan        Cuisine = "yunnan" // 云贵菜
+	CuisineTaiwanese     Cuisine = "taiwanese"
+	CuisineChineseHome   Cuisine = "chinese_home"
+	CuisineJapaneseHome  Cuisine = "japanese_home"
+	CuisineKorean        Cuisine = "korean"
+	CuisineThai          Cuisine = "thai"
+	CuisineVietnamese    Cuisine = "vietnamese"
+	CuisineIndian        Cuisine = "indian"
+	CuisineItalian       Cuisine = "italian"
+	CuisineFrench        Cuisine = "french"
+	CuisineProvencal     Cuisine = "provencal"
+	CuisineSpanish       Cuisine = "spanish"
+	CuisineMediterranean Cuisine = "mediterranean"
+	CuisineBritish       Cuisine = "british"
+	CuisineMiddleEastern Cuisine = "middle_eastern"
+	CuisineMexican       Cuisine = "mexican"
+	CuisineAmerican      Cuisine = "american"
+	CuisineOther         Cuisine = "other"
+
+	// CuisineLocal 请求中表示「城市当地菜系」，不会出现在响应中
+	CuisineLocal Cuisine = "local"
+)
+
+// CuisineInfo 菜系代码及显示名称
+type CuisineInfo struct {
+	Code Cuisine `json:"code"`
+	Name string  `json:"name"`
+}
+
+// ListCuisinesResponse 菜系列表响应
+type ListCuisinesResponse struct {
+	Cuisines []CuisineInfo `json:"cuisines"`
+}
+
+// AllergenConflict 菜谱与用户声明过敏原的冲突
+type AllergenConflict struct {
+	Allergen    Allergen `json:"allergen"`
+	Ingredients []string `json:"ingredients"`
+	// DeclaredByModel 过敏原由模型声明，菜名和食材中未识别出来源
+	DeclaredByModel bool `json:"declaredByModel,omitempty"`
+}
+
+// --- 购物清单 ---
+
+// StoreSection 超市购买区域 (code 值，用于 i18n 翻译)
+type StoreSection string
+
+const (
+	SectionProduce StoreSection = "produce"
+	SectionMeat    StoreSection = "meat"
+	SectionSeafood StoreSection = "seafood"
+	SectionDairy   StoreSection = "dairy"
+	SectionPantry  StoreSection = "pantry"
+	SectionOther   StoreSection = "other"
+)
+
+// ShoppingListFormat 购物清单导出格式
+type ShoppingListFormat string
+
+const (
+	ShoppingListFormatJSON ShoppingListFormat = "json"
+	ShoppingListFormatText ShoppingListFormat = "text"
+	ShoppingListFormatPDF  ShoppingListFormat = "pdf"
+)
+
+// ShoppingListRecipe 购物清单中的一道菜谱及其份数
+type ShoppingListRecipe struct {
+	ID       string `json:"id" binding:"required"`
+	Servings int    `json:"servings,omitempty" binding:"min=0,max=50"`
+}
+
+// CreateShoppingListRequest 生成购物清单请求
+type CreateShoppingListRequest struct {
+	Recipes []ShoppingListRecipe `json:"recipes" binding:"required,min=1,max=30,dive"`
+	City    string               `json:"city,omitempty" binding:"max=100"`
+	Format  ShoppingListFormat   `json:"format,omitempty"`
+}
+
+// ShoppingQuantity 合并后的用量，Value 为 0 表示无法量化（如「适量」）
+type ShoppingQuantity struct {
+	Value float64 `json:"value,omitempty"`
+	Unit  string  `json:"unit,omitempty"`
+	Text  string  `json:"text,omitempty"`
+}
+
+// ShoppingItem 购物清单条目
+type ShoppingItem struct {
+	Name       string             `json:"name"`
+	Amount     string             `json:"amount"`
+	Quantities []ShoppingQuantity `json:"quantities"`
+	Variants   []string           `json:"variants,omitempty"`
+	InSeason   bool               `json:"inSeason"`
+	Recipes    []string           `json:"recipes"`
+}
+
+// ShoppingSection 按购买区域分组的条目
+type ShoppingSection struct {
+	Section StoreSection   `json:"section"`
+	Name    string         `json:"name"`
+	Items   []ShoppingItem `json:"items"`
+}
+
+// ShoppingListRecipeSummary 购物清单包含的菜谱
+type ShoppingListRecipeSummary struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title"`
+	Servings string  `json:"servings"`
+	Scale    float64 `json:"scale"`
+}
+
+// ShoppingList 合并后的购物清单
+type ShoppingList struct {
+	Recipes        []ShoppingListRecipeSummary `json:"recipes"`
+	Sections       []ShoppingSection           `json:"sections"`
+	MissingRecipes []string                    `json:"missingRecipes,omitempty"`
+}
+
+// --- 膳食计划 ---
+
+// MealSlot 一天中的餐次
+type MealSlot string
+
+const (
+	MealBreakfast MealSlot = "breakfast"
+	MealLunch     MealSlot = "lunch"
+	MealDinner    MealSlot = "dinner"
+)
+
+// CreateMealPlanRequest 生成膳食计划请求
+type CreateMealPlanRequest struct {
+	City string `json:"city" binding:"required,max=100"`
+	// StartDate 起始日期 (YYYY-MM-DD)，默认今天
+	StartDate string `json:"startDate,omitempty"`
+	// EndDate 结束日期 (YYYY-MM-DD，含当天)，默认起始日起共 7 天
+	EndDate string `json:"endDate,omitempty"`
+	// MealsPerDay 每天的餐数 (1-3)，默认 2（午餐、晚餐）
+	MealsPerDay int      `json:"mealsPerDay,omitempty" binding:"min=0,max=3"`
+	Servings    int      `json:"servings,omitempty" binding:"min=0,max=20"`
+	Preference  string   `json:"preference,omitempty" binding:"max=500"`
+	Allergens   []string `json:"allergens,omitempty" binding:"max=20"`
+}
+
+// PlannedMeal 计划中的一餐
+type PlannedMeal struct {
+	Slot   MealSlot        `json:"slot"`
+	Recipe RecipeWithMatch `json:"recipe"`
+	// SeasonalIngredients 该菜谱用到的应季食材
+	SeasonalIngredients []string `json:"seasonalIngredients"`
+	// ReusedIngredients 与前几天共用的食材，减少浪费
+	ReusedIngredients []string `json:"reusedIngredients,omitempty"`
+}
+
+// MealPlanDay 计划中的一天
+type MealPlanDay struct {
+	Date  string        `json:"date"`
+	Meals []PlannedMeal `json:"meals"`
+}
+
+// MealPlanStats 计划的统计信息
+type MealPlanStats struct {
+	Meals                  int `json:"meals"`
+	SeasonalIngredientUses int `json:"seasonalIngredientUses"`
+	DistinctIngredients    int `json:"distinctIngredients"`
+	ReusedIngredients      int `json:"reusedIngredients"`
+}
+
+// MealPlan 膳食计划
+type MealPlan struct {
+	ID                  string        `json:"id"`
+	City                string        `json:"city"`
+	StartDate           string        `json:"startDate"`
+	EndDate             string        `json:"endDate"`
+	MealsPerDay         int           `json:"mealsPerDay"`
+	Servings            int           `json:"servings,omitempty"`
+	Preference          string        `json:"preference,omitempty"`
+	Allergens           []Allergen    `json:"allergens"`
+	SeasonalIngredients []string      `json:"seasonalIngredients"`
+	Days                []MealPlanDay `json:"days"`
+	Stats               MealPlanStats `json:"stats"`
+	CreatedAt           time.Time     `json:"createdAt"`
+	UpdatedAt           time.Time     `json:"updatedAt"`
+}
+
+// SwapMealRequest 替换计划中某一餐的请求
+type SwapMealRequest struct {
+	Date string   `json:"date" binding:"required"`
+	Slot MealSlot `json:"slot" binding:"required"`
+}
+
+// --- 食材库存 ---
+
+// PantryItem 用户库存中的一种食材
+type PantryItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Quantity string `json:"quantity,omitempty"`
+	// ExpiresOn 过期日期 (YYYY-MM-DD)，为空表示不易过期
+	ExpiresOn string `json:"expiresOn,omitempty"`
+	// DaysLeft 距离过期的天数，已过期为负数
+	DaysLeft  *int      `json:"daysLeft,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreatePantryItemRequest 添加库存食材请求
+type CreatePantryItemRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Quantity  string `json:"quantity,omitempty" binding:"max=50"`
+	ExpiresOn string `json:"expiresOn,omitempty"`
+}
+
+// UpdatePantryItemRequest 更新库存食材请求，未提供的字段保持不变
+type UpdatePantryItemRequest struct {
+	Name      *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Quantity  *string `json:"quantity,omitempty" binding:"omitempty,max=50"`
+	ExpiresOn *string `json:"expiresOn,omitempty"`
+}
+
+// ListPantryResponse 库存列表响应
+type ListPantryResponse struct {
+	Items []PantryItem `json:"items"`
+}
+
+// --- 菜谱库（收藏与浏览历史） ---
+
+// RecipeSummary 菜谱摘要，用于收藏和浏览历史列表
+type RecipeSummary struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	CookingTime string  `json:"cookingTime,omitempty"`
+	Difficulty  string  `json:"difficulty,omitempty"`
+	Cuisine     Cuisine `json:"cuisine,omitempty"`
+	Lang        string  `json:"lang"`
+}
+
+// FavouriteRecipe 用户收藏的菜谱
+type FavouriteRecipe struct {
+	Recipe  RecipeSummary `json:"recipe"`
+	Note    string        `json:"note,omitempty"`
+	SavedAt time.Time     `json:"savedAt"`
+}
+
+// HistoryEntry 用户浏览过的菜谱
+type HistoryEntry struct {
+	Recipe    RecipeSummary `json:"recipe"`
+	ViewCount int           `json:"viewCount"`
+	ViewedAt  time.Time     `json:"viewedAt"`
+}
+
+// AddFavouriteRequest 收藏菜谱请求，菜谱须已生成详情
+type AddFavouriteRequest struct {
+	RecipeID string `json:"recipeId" binding:"required,max=100"`
+	Note     string `json:"note,omitempty" binding:"max=500"`
+}
+
+// ListFavouritesResponse 收藏列表响应（最近收藏在前）
+type ListFavouritesResponse struct {
+	Favourites []FavouriteRecipe `json:"favourites"`
+}
+
+// GetFavouriteResponse 收藏的菜谱（含完整详情）
+type GetFavouriteResponse struct {
+	Favourite FavouriteRecipe `json:"favourite"`
+	Recipe    NewRecipeDetail `json:"detail"`
+}
+
+// ListHistoryResponse 浏览历史响应（最近浏览在前）
+type ListHistoryResponse struct {
+	History []HistoryEntry `json:"history"`
+}
+
+// --- 用户账号与 API 密钥 ---
+
+// User 注册用户
+type User struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=32"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Username string `json:"username" binding:"required,max=32"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// AuthResponse 注册或登录成功后返回的会话令牌
+type AuthResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      User      `json:"user"`
+}
+
+// APIKey 用户的个人 API 密钥（不含密钥本身）
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// CreateAPIKeyRequest 创建 API 密钥请求
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreateAPIKeyResponse 新建的 API 密钥，完整密钥只在创建时返回一次
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// ListAPIKeysResponse API 密钥列表响应
+type ListAPIKeysResponse struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// --- 菜谱评价与反馈 ---
+
+// RecipeFeedback 用户对菜谱的评价，保存菜谱的标题、菜系和食材以便归纳口味偏好
+type RecipeFeedback struct {
+	RecipeID    string     `json:"recipeId"`
+	Title       string     `json:"title"`
+	Cuisine     Cuisine    `json:"cuisine,omitempty"`
+	Ingredients []string   `json:"-"`
+	Rating      int        `json:"rating,omitempty"` // 1-5，0 表示未评分
+	CookedAt    *time.Time `json:"cookedAt,omitempty"`
+	Rejected    bool       `json:"rejected"`
+	Note        string     `json:"note,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// RecipeFeedbackRequest 提交菜谱评价请求，未提供的字段保持不变
+type RecipeFeedbackRequest struct {
+	Rating *int `json:"rating,omitempty" binding:"omitempty,min=1,max=5"`
+	// Cooked 标记“做过了”（记录时间），false 取消标记
+	Cooked *bool `json:"cooked,omitempty"`
+	// Rejected 不想再看到这道菜，之后的推荐不会再出现
+	Rejected *bool   `json:"rejected,omitempty"`
+	Note     *string `json:"note,omitempty" binding:"omitempty,max=280"`
+}
+
+// RecipeRatings 菜谱的汇总评分
+type RecipeRatings struct {
+	RecipeID      string  `json:"recipeId"`
+	AverageRating float64 `json:"averageRating"`
+	RatingCount   int     `json:"ratingCount"`
+	// Distribution 1-5 星各自的评分人数
+	Distribution [5]int `json:"distribution"`
+	CookedCount  int    `json:"cookedCount"`
+}
+
+// RecipeFeedbackResponse 菜谱评价响应：当前用户的评价（如有）与汇总评分
+type RecipeFeedbackResponse struct {
+	Feedback *RecipeFeedback `json:"feedback,omitempty"`
+	Ratings  RecipeRatings   `json:"ratings"`
+}
+
+// FeedbackProfile 由用户历史评价归纳出的口味偏好，用于个性化推荐
+type FeedbackProfile struct {
+	LikedCuisines       []Cuisine `json:"likedCuisines,omitempty"`
+	DislikedCuisines    []Cuisine `json:"dislikedCuisines,omitempty"`
+	LikedIngredients    []string  `json:"likedIngredients,omitempty"`
+	DislikedIngredients []string  `json:"dislikedIngredients,omitempty"`
+	// RejectedTitles 不再推荐的菜（最近拒绝在前）
+	RejectedTitles []string `json:"rejectedTitles,omitempty"`
+}
+
+// ListFeedbackResponse 用户的评价列表（最近更新在前）及归纳出的口味偏好
+type ListFeedbackResponse struct {
+	Feedback []RecipeFeedback `json:"feedback"`
+	Profile  FeedbackProfile  `json:"profile"`
+}
+
+// --- 分享快照 ---
+
+// SharedRecipe 发布后不可变的菜谱快照，不受缓存过期影响，可匿名访问
+type SharedRecipe struct {
+	Slug   string          `json:"slug"`
+	Recipe NewRecipeDetail `json:"recipe"`
+	// Context 推荐该菜谱时的上下文（如有）
+	Context *RecommendationContext `json:"context,omitempty"`
+	// ImageURL 快照中保存的菜谱图片地址，无图片时为空
+	ImageURL string `json:"imageUrl,omitempty"`
+	Lang     string `json:"lang"`
+	// ContentHash 快照内容（菜谱、上下文、图片）的 SHA-256 摘要，内容相同的发布得到同一快照
+	ContentHash string    `json:"contentHash"`
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// --- 菜谱编辑与修订历史 ---
+
+// UserRecipe 用户编辑过的菜谱，或从分享快照复制的个人菜谱
+type UserRecipe struct {
+	Recipe RecipeSummary `json:"recipe"`
+	// Revision 当前修订号
+	Revision int `json:"revision"`
+	// ForkedFrom 复制来源的分享快照短链接，编辑生成的菜谱时为空
+	ForkedFrom string    `json:"forkedFrom,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// RecipeRevision 菜谱的一个修订版本。编辑生成的菜谱时，修订 1 为生成的原始版本
+type RecipeRevision struct {
+	Revision int    `json:"revision"`
+	Title    string `json:"title"`
+	Message  string `json:"message,omitempty"`
+	// RestoredFrom 由恢复操作创建时，被恢复的修订号
+	RestoredFrom int       `json:"restoredFrom,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Recipe 该修订的菜谱内容，修订列表中省略
+	Recipe *NewRecipeDetail `json:"recipe,omitempty"`
+}
+
+// EditRecipeRequest 编辑菜谱请求，省略的字段保持不变；食材、步骤和标签整体替换
+type EditRecipeRequest struct {
+	Title       *string            `json:"title" binding:"omitempty,min=1,max=100"`
+	Description *string            `json:"description" binding:"omitempty,max=1000"`
+	Ingredients []RecipeIngredient `json:"ingredients" binding:"omitempty,max=100"`
+	Steps       []NewCookingStep   `json:"steps" binding:"omitempty,max=50"`
+	CookingTime *string            `json:"cookingTime" binding:"omitempty,max=50"`
+	Servings    *string            `json:"servings" binding:"omitempty,max=50"`
+	Difficulty  *string            `json:"difficulty" binding:"omitempty,max=50"`
+	Tips        *string            `json:"tips" binding:"omitempty,max=1000"`
+	Tags        []string           `json:"tags" binding:"omitempty,max=20,dive,max=30"`
+	// BaseRevision 编辑所基于的修订号（可选），与当前修订号不一致时拒绝编辑，避免覆盖其他修改
+	BaseRevision int `json:"baseRevision" binding:"omitempty,min=1"`
+	// Message 修订说明（可选）
+	Message string `json:"message" binding:"max=200"`
+}
+
+// UserRecipeResponse 编辑、复制或恢复后的个人菜谱
+type UserRecipeResponse struct {
+	Recipe     NewRecipeDetail `json:"recipe"`
+	Revision   int             `json:"revision"`
+	ForkedFrom string          `json:"forkedFrom,omitempty"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+// ListUserRecipesResponse 用户编辑过或复制的菜谱列表
+type ListUserRecipesResponse struct {
+	Recipes []UserRecipe `json:"recipes"`
+}
+
+// ListRevisionsResponse 菜谱的修订历史，最新的在前
+type ListRevisionsResponse struct {
+	RecipeID  string           `json:"recipeId"`
+	Current   int              `json:"current"`
+	Revisions []RecipeRevision `json:"revisions"`
+}
+
+// ChangeOp 差异中的变更类型
+type ChangeOp string
+
+const (
+	ChangeAdded    ChangeOp = "added"
+	ChangeRemoved  ChangeOp = "removed"
+	ChangeModified ChangeOp = "modified"
+)
+
+// FieldChange 文本字段的变更
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// IngredientChange 食材的变更（按名称匹配）
+type IngredientChange struct {
+	Op   ChangeOp          `json:"op"`
+	Name string            `json:"name"`
+	From *RecipeIngredient `json:"from,omitempty"`
+	To   *RecipeIngredient `json:"to,omitempty"`
+}
+
+// StepChange 步骤的变更（按步骤说明对齐）
+type StepChange struct {
+	Op   ChangeOp        `json:"op"`
+	From *NewCookingStep `json:"from,omitempty"`
+	To   *NewCookingStep `json:"to,omitempty"`
+}
+
+// RecipeDiff 两个修订之间的差异
+type RecipeDiff struct {
+	RecipeID    string             `json:"recipeId"`
+	From        int                `json:"from"`
+	To          int                `json:"to"`
+	Fields      []FieldChange      `json:"fields"`
+	Ingredients []IngredientChange `json:"ingredients"`
+	Steps       []StepChange       `json:"steps"`
+	TagsAdded   []string           `json:"tagsAdded,omitempty"`
+	TagsRemoved []string           `json:"tagsRemoved,omitempty"`
+}
+
+// --- 菜谱搜索 ---
+
+// RecipeSearchResult 搜索命中的菜谱
+type RecipeSearchResult struct {
+	Recipe             RecipeSummary `json:"recipe"`
+	CookingTimeSeconds int           `json:"cookingTimeSeconds,omitempty"`
+	// Season 推荐（生成）该菜谱时的季节
+	Season SeasonID `json:"season,omitempty"`
+	// Score 相关度，越大越相关
+	Score float64 `json:"score"`
+}
+
+// SearchRecipesResponse 菜谱搜索结果
+type SearchRecipesResponse struct {
+	Query   string               `json:"query"`
+	Total   int                  `json:"total"`
+	Results []RecipeSearchResult `json:"results"`
+}
+
+// --- 相似菜谱 ---
+
+// SimilarRecipe 与指定菜谱相似的菜谱
+type SimilarRecipe struct {
+	Recipe RecipeSummary `json:"recipe"`
+	// Similarity 嵌入向量的余弦相似度，越大越相似
+	Similarity float64 `json:"similarity"`
+	// Duplicate 相似度达到近似重复的阈值
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// SimilarRecipesResponse 相似菜谱列表
+type SimilarRecipesResponse struct {
+	RecipeID string `json:"recipeId"`
+	// Model 生成嵌入向量的服务和模型
+	Model   string          `json:"model"`
+	Results []SimilarRecipe `json:"results"`
+}
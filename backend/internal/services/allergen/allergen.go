@@ -0,0 +1,285 @@
+// Package allergen detects allergens in generated recipes and checks them
+// against the allergens declared by the user
+package allergen
+
+import (
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// order 过敏原的固定输出顺序：九大过敏原在前，地区性过敏原在后
+var order = []models.Allergen{
+	models.AllergenMilk,
+	models.AllergenEgg,
+	models.AllergenFish,
+	models.AllergenShellfish,
+	models.AllergenTreeNut,
+	models.AllergenPeanut,
+	models.AllergenWheat,
+	models.AllergenSoy,
+	models.AllergenSesame,
+	models.AllergenMollusc,
+	models.AllergenGluten,
+	models.AllergenCelery,
+	models.AllergenMustard,
+	models.AllergenLupin,
+	models.AllergenSulphites,
+	models.AllergenBuckwheat,
+}
+
+// aliases 用户输入的过敏原别名 -> 过敏原代码
+// 「海鲜」「shellfish」等笼统说法同时覆盖甲壳类和软体类，宁严勿宽
+var aliases = map[string][]models.Allergen{
+	"乳":           {models.AllergenMilk},
+	"乳制品":         {models.AllergenMilk},
+	"牛奶":          {models.AllergenMilk},
+	"奶":           {models.AllergenMilk},
+	"dairy":       {models.AllergenMilk},
+	"lactose":     {models.AllergenMilk},
+	"乳糖":          {models.AllergenMilk},
+	"蛋":           {models.AllergenEgg},
+	"鸡蛋":          {models.AllergenEgg},
+	"蛋类":          {models.AllergenEgg},
+	"eggs":        {models.AllergenEgg},
+	"鱼":           {models.AllergenFish},
+	"鱼类":          {models.AllergenFish},
+	"虾":           {models.AllergenShellfish},
+	"蟹":           {models.AllergenShellfish},
+	"虾蟹":          {models.AllergenShellfish},
+	"甲壳类":         {models.AllergenShellfish},
+	"crustacean":  {models.AllergenShellfish},
+	"crustaceans": {models.AllergenShellfish},
+	"海鲜":          {models.AllergenFish, models.AllergenShellfish, models.AllergenMollusc},
+	"seafood":     {models.AllergenFish, models.AllergenShellfish, models.AllergenMollusc},
+	"shellfish":   {models.AllergenShellfish, models.AllergenMollusc},
+	"贝类":          {models.AllergenShellfish, models.AllergenMollusc},
+	"软体类":         {models.AllergenMollusc},
+	"molluscs":    {models.AllergenMollusc},
+	"mollusk":     {models.AllergenMollusc},
+	"mollusks":    {models.AllergenMollusc},
+	"坚果":          {models.AllergenTreeNut},
+	"tree nut":    {models.AllergenTreeNut},
+	"tree nuts":   {models.AllergenTreeNut},
+	"nuts":        {models.AllergenTreeNut, models.AllergenPeanut},
+	"花生":          {models.AllergenPeanut},
+	"peanuts":     {models.AllergenPeanut},
+	"小麦":          {models.AllergenWheat},
+	"麦":           {models.AllergenWheat, models.AllergenGluten},
+	"麸质":          {models.AllergenWheat, models.AllergenGluten},
+	"大豆":          {models.AllergenSoy},
+	"黄豆":          {models.AllergenSoy},
+	"soya":        {models.AllergenSoy},
+	"soybean":     {models.AllergenSoy},
+	"芝麻":          {models.AllergenSesame},
+	"芹菜":          {models.AllergenCelery},
+	"芥末":          {models.AllergenMustard},
+	"羽扇豆":         {models.AllergenLupin},
+	"亚硫酸盐":        {models.AllergenSulphites},
+	"sulfites":    {models.AllergenSulphites},
+	"sulphite":    {models.AllergenSulphites},
+	"sulfite":     {models.AllergenSulphites},
+	"荞麦":          {models.AllergenBuckwheat},
+}
+
+// All returns every supported allergen code in display order
+func All() []models.Allergen {
+	result := make([]models.Allergen, len(order))
+	copy(result, order)
+	return result
+}
+
+// Normalize converts user-declared allergens (codes, Chinese or English names)
+// into a de-duplicated list of allergen codes. Unknown entries are ignored.
+func Normalize(declared []string) []models.Allergen {
+	set := make(map[models.Allergen]bool)
+	for _, d := range declared {
+		key := strings.ToLower(strings.TrimSpace(d))
+		if key == "" {
+			continue
+		}
+		if isKnown(models.Allergen(key)) {
+			set[models.Allergen(key)] = true
+			continue
+		}
+		// 兼容 "tree-nut" / "Tree Nut" 等写法
+		if code := models.Allergen(strings.NewReplacer("-", "_", " ", "_").Replace(key)); isKnown(code) {
+			set[code] = true
+			continue
+		}
+		for _, code := range aliases[key] {
+			set[code] = true
+		}
+	}
+	return sorted(set)
+}
+
+// Detect returns the allergens contained in the given ingredient names or free text
+func Detect(texts ...string) []models.Allergen {
+	set := make(map[models.Allergen]bool)
+	for _, text := range texts {
+		for _, m := range catalog.Match(text) {
+			for _, a := range m.Entry.Allergens {
+				set[a] = true
+			}
+		}
+	}
+	return sorted(set)
+}
+
+// TagRecipe tags a recommended recipe with the allergens found in its title and
+// ingredients. Allergens declared by the model are merged in but never trusted alone.
+func TagRecipe(r *models.RecipeWithMatch, declaredByModel []string) {
+	texts := make([]string, 0, len(r.Ingredients)+len(r.MatchedIngredients)+1)
+	texts = append(texts, r.Title)
+	texts = append(texts, r.Ingredients...)
+	texts = append(texts, r.MatchedIngredients...)
+
+	r.Allergens = merge(Detect(texts...), Normalize(declaredByModel))
+}
+
+// TagDetail tags every ingredient of a recipe detail and the recipe itself with allergens
+func TagDetail(detail *models.NewRecipeDetail) {
+	set := make(map[models.Allergen]bool)
+	for _, a := range Detect(detail.Title) {
+		set[a] = true
+	}
+	for i := range detail.Ingredients {
+		ing := &detail.Ingredients[i]
+		ing.Allergens = Detect(ing.Name, ing.Note)
+		for _, a := range ing.Allergens {
+			set[a] = true
+		}
+	}
+	detail.Allergens = sorted(set)
+}
+
+// Conflicts returns the declared allergens among the found ones
+func Conflicts(found, declared []models.Allergen) []models.Allergen {
+	return intersect(found, declared)
+}
+
+// RecipeConflicts returns the declared allergens present in a recommended recipe, with
+// the title or ingredients that carry them; allergens only the model declared (see
+// TagRecipe) are marked as such
+func RecipeConflicts(r *models.RecipeWithMatch, declared []models.Allergen) []models.AllergenConflict {
+	texts := make([]string, 0, len(r.Ingredients)+len(r.MatchedIngredients)+1)
+	texts = append(texts, r.Title)
+	texts = append(texts, r.Ingredients...)
+	texts = append(texts, r.MatchedIngredients...)
+
+	var conflicts []models.AllergenConflict
+	for _, a := range intersect(r.Allergens, declared) {
+		conflict := models.AllergenConflict{Allergen: a, Ingredients: sourcesOf(a, texts)}
+		if len(conflict.Ingredients) == 0 {
+			conflict.Ingredients = []string{}
+			conflict.DeclaredByModel = true
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// DetailConflicts returns the declared allergens present in a recipe detail,
+// together with the ingredients that carry them
+func DetailConflicts(detail *models.NewRecipeDetail, declared []models.Allergen) []models.AllergenConflict {
+	var conflicts []models.AllergenConflict
+	for _, a := range intersect(detail.Allergens, declared) {
+		conflict := models.AllergenConflict{Allergen: a}
+		for _, ing := range detail.Ingredients {
+			if contains(ing.Allergens, a) {
+				conflict.Ingredients = append(conflict.Ingredients, ing.Name)
+			}
+		}
+		if len(conflict.Ingredients) == 0 {
+			conflict.Ingredients = []string{detail.Title}
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
+
+// Names returns the display names of allergens in the given language
+func Names(codes []models.Allergen, lang string) []string {
+	names := make([]string, 0, len(codes))
+	for _, c := range codes {
+		names = append(names, i18n.GetAllergen(lang, string(c)))
+	}
+	return names
+}
+
+// ForbiddenIngredients returns example catalog ingredient names that carry the given
+// allergens, used to make prompt instructions concrete
+func ForbiddenIngredients(codes []models.Allergen, lang string, limit int) []string {
+	var names []string
+	for _, e := range catalog.Entries() {
+		if len(intersect(e.Allergens, codes)) == 0 {
+			continue
+		}
+		names = append(names, e.DisplayName(lang))
+		if limit > 0 && len(names) >= limit {
+			break
+		}
+	}
+	return names
+}
+
+// sourcesOf returns the texts in which an allergen was detected
+func sourcesOf(a models.Allergen, texts []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, t := range texts {
+		if !seen[t] && contains(Detect(t), a) {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+func isKnown(code models.Allergen) bool {
+	return contains(order, code)
+}
+
+func contains(list []models.Allergen, a models.Allergen) bool {
+	for _, x := range list {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+func intersect(a, b []models.Allergen) []models.Allergen {
+	var result []models.Allergen
+	for _, x := range a {
+		if contains(b, x) {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+func merge(a, b []models.Allergen) []models.Allergen {
+	set := make(map[models.Allergen]bool, len(a)+len(b))
+	for _, x := range a {
+		set[x] = true
+	}
+	for _, x := range b {
+		set[x] = true
+	}
+	return sorted(set)
+}
+
+// sorted returns the allergens of a set in display order (never nil, so JSON renders [])
+func sorted(set map[models.Allergen]bool) []models.Allergen {
+	result := make([]models.Allergen, 0, len(set))
+	for _, a := range order {
+		if set[a] {
+			result = append(result, a)
+		}
+	}
+	return result
+}
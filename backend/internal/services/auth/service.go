@@ -0,0 +1,234 @@
+// Package auth manages local user accounts: registration and login with bcrypt-hashed
+// passwords, signed session tokens (HS256 JWT) and personal API keys for scripts
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// apiKeyPrefix API 密钥前缀，便于在日志和代码中识别泄露的密钥
+	apiKeyPrefix = "eois_"
+	// apiKeyDisplayLength 列表中展示的密钥前缀长度
+	apiKeyDisplayLength = 12
+	// maxAPIKeys 每个用户最多持有的 API 密钥数
+	maxAPIKeys = 20
+	// DefaultTokenTTL 会话令牌默认有效期
+	DefaultTokenTTL = 7 * 24 * time.Hour
+	// maxPasswordBytes bcrypt 只接受 72 字节以内的密码（按 UTF-8 字节计，中文每字 3 字节）
+	maxPasswordBytes = 72
+)
+
+var (
+	// ErrInvalidUsername 用户名格式无效
+	ErrInvalidUsername = errors.New("invalid username")
+	// ErrPasswordTooLong 密码超过 72 字节
+	ErrPasswordTooLong = errors.New("password too long")
+	// ErrUsernameTaken 用户名已被注册
+	ErrUsernameTaken = errors.New("username already taken")
+	// ErrInvalidCredentials 用户名或密码错误
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidToken 会话令牌无效
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired 会话令牌已过期
+	ErrTokenExpired = errors.New("token expired")
+	// ErrInvalidAPIKey API 密钥无效或已吊销
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+	// ErrAPIKeyNotFound API 密钥不存在
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeysFull API 密钥数量达到上限
+	ErrAPIKeysFull = errors.New("api keys are full")
+)
+
+// validUsername 用户名只允许字母、数字、下划线、点和连字符
+var validUsername = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+// dummyHash 用户不存在时仍执行一次 bcrypt 比较，避免通过响应时间探测用户名
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("eat-only-in-season"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Service provides account and authentication functionality
+type Service struct {
+	store    *store.Store
+	secret   []byte
+	tokenTTL time.Duration
+	// admins 管理员用户名（小写，用户名不区分大小写）
+	admins map[string]bool
+}
+
+// NewService creates a new auth service; tokens are signed with secret and valid for
+// tokenTTL (DefaultTokenTTL when <= 0). The users named in admins may use the admin API.
+func NewService(st *store.Store, secret []byte, tokenTTL time.Duration, admins []string) *Service {
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+	s := &Service{store: st, secret: secret, tokenTTL: tokenTTL, admins: make(map[string]bool, len(admins))}
+	for _, name := range admins {
+		s.admins[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return s
+}
+
+// Register creates a user account and signs it in
+func (s *Service) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+	username := strings.TrimSpace(req.Username)
+	if !validUsername.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+	if len(req.Password) > maxPasswordBytes {
+		return nil, ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &models.User{ID: uuid.New().String(), Username: username, CreatedAt: time.Now()}
+	if err := s.store.CreateUser(user, string(hash)); err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+	return s.session(user)
+}
+
+// Login verifies a username and password and issues a session token
+func (s *Service) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
+	user, hash, err := s.store.GetUserByUsername(strings.TrimSpace(req.Username))
+	if errors.Is(err, store.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(req.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return s.session(user)
+}
+
+// User returns a registered user
+func (s *Service) User(id string) (*models.User, error) {
+	user, err := s.store.GetUser(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// IsRegistered reports whether id belongs to a registered user
+func (s *Service) IsRegistered(id string) bool {
+	_, err := s.store.GetUser(id)
+	return err == nil
+}
+
+// IsAdmin reports whether id belongs to a registered user named in the admin list
+func (s *Service) IsAdmin(id string) bool {
+	if len(s.admins) == 0 {
+		return false
+	}
+	user, err := s.store.GetUser(id)
+	return err == nil && s.admins[strings.ToLower(user.Username)]
+}
+
+// VerifyToken returns the user a valid session token was issued to
+func (s *Service) VerifyToken(token string) (string, error) {
+	userID, err := parseToken(s.secret, token, time.Now())
+	if err != nil {
+		return "", err
+	}
+	// 账号删除后令牌随之失效
+	if !s.IsRegistered(userID) {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
+
+// VerifyAPIKey returns the user owning a valid API key and records its use
+func (s *Service) VerifyAPIKey(key string) (string, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", ErrInvalidAPIKey
+	}
+	userID, err := s.store.UseAPIKey(hashAPIKey(key))
+	if errors.Is(err, store.ErrNotFound) {
+		return "", ErrInvalidAPIKey
+	}
+	return userID, err
+}
+
+// CreateAPIKey issues a personal API key; the full key is only returned here
+func (s *Service) CreateAPIKey(userID, name string) (*models.CreateAPIKeyResponse, error) {
+	existing, err := s.store.ListAPIKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxAPIKeys {
+		return nil, ErrAPIKeysFull
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	apiKey := models.APIKey{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		Prefix:    key[:apiKeyDisplayLength],
+		CreatedAt: time.Now(),
+	}
+	if err := s.store.CreateAPIKey(userID, &apiKey, hashAPIKey(key)); err != nil {
+		return nil, err
+	}
+	return &models.CreateAPIKeyResponse{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns the API keys of a user without the keys themselves
+func (s *Service) ListAPIKeys(userID string) ([]models.APIKey, error) {
+	return s.store.ListAPIKeys(userID)
+}
+
+// RevokeAPIKey deletes an API key of a user
+func (s *Service) RevokeAPIKey(userID, id string) error {
+	err := s.store.DeleteAPIKey(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// session issues a session token for a user
+func (s *Service) session(user *models.User) (*models.AuthResponse, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.tokenTTL)
+	token, err := signToken(s.secret, user.ID, now, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{Token: token, ExpiresAt: expiresAt, User: *user}, nil
+}
+
+// hashAPIKey API 密钥为高熵随机串，使用 SHA-256 摘要存储即可
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
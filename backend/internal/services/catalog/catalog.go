@@ -0,0 +1,254 @@
+// Package catalog provides a static ingredient catalog used to canonicalize
+// ingredient names returned by the LLM and to look up their properties.
+package catalog
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// Entry 目录中的一种标准食材
+type Entry struct {
+	// ID 标准化的食材代码
+	ID string
+	// Names 中英文名称及常见别名，第一个为中文标准名，第二个为英文标准名
+	Names []string
+	// Allergens 该食材包含的过敏原
+	Allergens []models.Allergen
+	// Section 超市中的购买区域
+	Section models.StoreSection
+}
+
+// DisplayName returns the canonical name of the entry for the given language
+func (e *Entry) DisplayName(lang string) string {
+	if lang == "en" && len(e.Names) > 1 {
+		return e.Names[1]
+	}
+	return e.Names[0]
+}
+
+// synonym 别名索引项
+type synonym struct {
+	name  string
+	ascii bool
+	entry *Entry
+}
+
+var (
+	byID     map[string]*Entry
+	synonyms []synonym
+)
+
+func init() {
+	byID = make(map[string]*Entry, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		byID[e.ID] = e
+		for _, n := range e.Names {
+			n = Normalize(n)
+			synonyms = append(synonyms, synonym{name: n, ascii: isASCII(n), entry: e})
+		}
+	}
+
+	// 按长度降序排列，保证最长匹配优先（如「奶白菜」优先于「奶」、「eggplant」优先于「egg」）
+	sort.SliceStable(synonyms, func(i, j int) bool {
+		return len(synonyms[i].name) > len(synonyms[j].name)
+	})
+}
+
+// Get returns the entry with the given ID
+func Get(id string) (*Entry, bool) {
+	e, ok := byID[id]
+	return e, ok
+}
+
+// Entries returns all catalog entries
+func Entries() []*Entry {
+	result := make([]*Entry, 0, len(entries))
+	for i := range entries {
+		result = append(result, &entries[i])
+	}
+	return result
+}
+
+// Normalize normalizes an ingredient name for matching:
+// lower case, parenthesized notes removed, whitespace collapsed
+func Normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	var sb strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch r {
+		case '(', '（', '[', '【':
+			depth++
+			continue
+		case ')', '）', ']', '】':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		sb.WriteRune(r)
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// Lookup finds the catalog entry that best describes an ingredient name.
+// An exact synonym match wins; otherwise the longest synonym contained in the name is used.
+func Lookup(name string) (*Entry, bool) {
+	normalized := Normalize(name)
+	if normalized == "" {
+		return nil, false
+	}
+
+	for _, s := range synonyms {
+		if s.name == normalized {
+			return s.entry, true
+		}
+	}
+
+	matches := Match(normalized)
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	// 取最长的匹配，通常是主体食材（如「蒜蓉粉丝蒸扇贝」中的「扇贝」）
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if len(m.Matched) > len(best.Matched) {
+			best = m
+		}
+	}
+	return best.Entry, true
+}
+
+// Canonical returns the canonical name for an ingredient, or the trimmed input if unknown
+func Canonical(name string, lang string) string {
+	if e, ok := Lookup(name); ok {
+		return e.DisplayName(lang)
+	}
+	return strings.TrimSpace(name)
+}
+
+// SectionOf returns the store section of an ingredient, or SectionOther if unknown
+func SectionOf(name string) models.StoreSection {
+	if e, ok := Lookup(name); ok && e.Section != "" {
+		return e.Section
+	}
+	return models.SectionOther
+}
+
+// staples 家中通常常备、不计入缺少食材的基础调料
+var staples = map[string]bool{
+	"salt": true, "sugar": true, "oil": true, "soy_sauce": true, "vinegar": true,
+	"pepper": true, "msg": true, "starch": true, "shaoxing_wine": true,
+}
+
+// IsStaple reports whether an ingredient is a basic seasoning (or water) that
+// households are assumed to have
+func IsStaple(name string) bool {
+	switch Normalize(name) {
+	case "水", "清水", "温水", "开水", "water":
+		return true
+	}
+	e, ok := Lookup(name)
+	return ok && staples[e.ID]
+}
+
+// SameIngredient reports whether two ingredient names refer to the same ingredient,
+// either through the catalog or because one name contains the other (如「春笋」与「笋」)
+func SameIngredient(a, b string) bool {
+	na, nb := Normalize(a), Normalize(b)
+	if na == "" || nb == "" {
+		return false
+	}
+	if na == nb {
+		return true
+	}
+	// 单字名称（如「米」「鱼」）过于宽泛，不做包含匹配
+	if utf8.RuneCountInString(na) > 1 && utf8.RuneCountInString(nb) > 1 &&
+		(strings.Contains(na, nb) || strings.Contains(nb, na)) {
+		return true
+	}
+	ea, okA := Lookup(na)
+	eb, okB := Lookup(nb)
+	return okA && okB && ea.ID == eb.ID
+}
+
+// MatchResult 文本中匹配到的目录食材
+type MatchResult struct {
+	Entry   *Entry
+	Matched string
+}
+
+// Match scans free text and returns every catalog entry mentioned in it.
+// Matching is greedy and longest-first so that overlapping names do not double count.
+func Match(text string) []MatchResult {
+	text = Normalize(text)
+	if text == "" {
+		return nil
+	}
+
+	var result []MatchResult
+	seen := make(map[string]bool)
+
+	for i := 0; i < len(text); {
+		matched := false
+		for _, s := range synonyms {
+			if !strings.HasPrefix(text[i:], s.name) {
+				continue
+			}
+			if s.ascii && !isWordBoundary(text, i, i+len(s.name)) {
+				continue
+			}
+			if !seen[s.entry.ID] {
+				seen[s.entry.ID] = true
+				result = append(result, MatchResult{Entry: s.entry, Matched: s.name})
+			}
+			i += len(s.name)
+			matched = true
+			break
+		}
+		if !matched {
+			// 按 rune 前进，避免切断多字节字符
+			_, size := utf8.DecodeRuneInString(text[i:])
+			i += size
+		}
+	}
+
+	return result
+}
+
+// isWordBoundary reports whether text[start:end] is a standalone English word,
+// allowing a trailing plural "s"/"es"
+func isWordBoundary(text string, start, end int) bool {
+	if start > 0 && isLetter(text[start-1]) {
+		return false
+	}
+	rest := text[end:]
+	rest = strings.TrimPrefix(rest, "es")
+	rest = strings.TrimPrefix(rest, "s")
+	return rest == "" || !isLetter(rest[0])
+}
+
+func isLetter(b byte) bool {
+	return b < 0x80 && unicode.IsLetter(rune(b))
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
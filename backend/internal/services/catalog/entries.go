@@ -0,0 +1,252 @@
+package catalog
+
+import "github.com/eat-only-in-season/backend/internal/models"
+
+// 过敏原简写，便于阅读下方的目录数据
+const (
+	milk      = models.AllergenMilk
+	egg       = models.AllergenEgg
+	fish      = models.AllergenFish
+	shellfish = models.AllergenShellfish
+	treeNut   = models.AllergenTreeNut
+	peanut    = models.AllergenPeanut
+	wheat     = models.AllergenWheat
+	soy       = models.AllergenSoy
+	sesame    = models.AllergenSesame
+	mollusc   = models.AllergenMollusc
+	gluten    = models.AllergenGluten
+	celery    = models.AllergenCelery
+	mustard   = models.AllergenMustard
+	lupin     = models.AllergenLupin
+	sulphites = models.AllergenSulphites
+	buckwheat = models.AllergenBuckwheat
+)
+
+// entries 食材目录数据
+// 名称顺序：中文标准名、英文标准名、其余别名
+var entries = []Entry{
+	// --- 蔬菜 ---
+	{ID: "bamboo_shoot", Names: []string{"竹笋", "bamboo shoot", "春笋", "冬笋", "笋", "笋尖", "bamboo shoots"}, Section: models.SectionProduce},
+	{ID: "asparagus", Names: []string{"芦笋", "asparagus", "绿芦笋", "白芦笋"}, Section: models.SectionProduce},
+	{ID: "celtuce", Names: []string{"莴笋", "celtuce", "莴苣笋", "青笋"}, Section: models.SectionProduce},
+	{ID: "celery", Names: []string{"芹菜", "celery", "西芹", "香芹", "药芹", "celeriac", "根芹"}, Allergens: []models.Allergen{celery}, Section: models.SectionProduce},
+	{ID: "bok_choy", Names: []string{"青菜", "bok choy", "小白菜", "上海青", "油菜", "奶白菜", "pak choi"}, Section: models.SectionProduce},
+	{ID: "napa_cabbage", Names: []string{"白菜", "napa cabbage", "大白菜", "chinese cabbage"}, Section: models.SectionProduce},
+	{ID: "cabbage", Names: []string{"卷心菜", "cabbage", "包菜", "圆白菜", "甘蓝"}, Section: models.SectionProduce},
+	{ID: "spinach", Names: []string{"菠菜", "spinach"}, Section: models.SectionProduce},
+	{ID: "water_spinach", Names: []string{"空心菜", "water spinach", "蕹菜", "morning glory"}, Section: models.SectionProduce},
+	{ID: "amaranth", Names: []string{"苋菜", "amaranth greens", "amaranth"}, Section: models.SectionProduce},
+	{ID: "shepherds_purse", Names: []string{"荠菜", "shepherd's purse"}, Section: models.SectionProduce},
+	{ID: "chinese_toon", Names: []string{"香椿", "chinese toon", "香椿芽"}, Section: models.SectionProduce},
+	{ID: "pea_shoots", Names: []string{"豌豆苗", "pea shoots", "豆苗", "豌豆尖"}, Section: models.SectionProduce},
+	{ID: "peas", Names: []string{"豌豆", "peas", "青豆", "pea", "荷兰豆", "snow peas", "snap peas"}, Section: models.SectionProduce},
+	{ID: "broad_bean", Names: []string{"蚕豆", "broad bean", "fava bean", "fava beans", "胡豆"}, Section: models.SectionProduce},
+	{ID: "green_bean", Names: []string{"四季豆", "green beans", "豆角", "扁豆", "green bean", "长豆角", "豇豆"}, Section: models.SectionProduce},
+	{ID: "edamame", Names: []string{"毛豆", "edamame"}, Allergens: []models.Allergen{soy}, Section: models.SectionProduce},
+	{ID: "soybean", Names: []string{"黄豆", "soybean", "大豆", "soybeans", "soya"}, Allergens: []models.Allergen{soy}, Section: models.SectionProduce},
+	{ID: "bean_sprout", Names: []string{"豆芽", "bean sprouts", "绿豆芽", "黄豆芽", "bean sprout"}, Section: models.SectionProduce},
+	{ID: "lupin", Names: []string{"羽扇豆", "lupin", "lupini", "lupine"}, Allergens: []models.Allergen{lupin}, Section: models.SectionProduce},
+	{ID: "tomato", Names: []string{"番茄", "tomato", "西红柿", "圣女果", "cherry tomato"}, Section: models.SectionProduce},
+	{ID: "eggplant", Names: []string{"茄子", "eggplant", "aubergine"}, Section: models.SectionProduce},
+	{ID: "cucumber", Names: []string{"黄瓜", "cucumber"}, Section: models.SectionProduce},
+	{ID: "zucchini", Names: []string{"西葫芦", "zucchini", "courgette"}, Section: models.SectionProduce},
+	{ID: "bitter_melon", Names: []string{"苦瓜", "bitter melon", "bitter gourd"}, Section: models.SectionProduce},
+	{ID: "loofah", Names: []string{"丝瓜", "loofah", "luffa"}, Section: models.SectionProduce},
+	{ID: "winter_melon", Names: []string{"冬瓜", "winter melon", "wax gourd"}, Section: models.SectionProduce},
+	{ID: "pumpkin", Names: []string{"南瓜", "pumpkin", "squash", "butternut squash"}, Section: models.SectionProduce},
+	{ID: "bell_pepper", Names: []string{"甜椒", "bell pepper", "彩椒", "青椒", "capsicum"}, Section: models.SectionProduce},
+	{ID: "chili", Names: []string{"辣椒", "chili", "小米辣", "尖椒", "chili pepper", "chilli"}, Section: models.SectionProduce},
+	{ID: "okra", Names: []string{"秋葵", "okra"}, Section: models.SectionProduce},
+	{ID: "corn", Names: []string{"玉米", "corn", "甜玉米", "sweetcorn"}, Section: models.SectionProduce},
+	{ID: "lotus_root", Names: []string{"莲藕", "lotus root", "藕"}, Section: models.SectionProduce},
+	{ID: "lotus_seed", Names: []string{"莲子", "lotus seeds", "lotus seed"}, Section: models.SectionProduce},
+	{ID: "water_chestnut", Names: []string{"荸荠", "water chestnut", "马蹄"}, Section: models.SectionProduce},
+	{ID: "water_bamboo", Names: []string{"茭白", "water bamboo"}, Section: models.SectionProduce},
+	{ID: "chinese_yam", Names: []string{"山药", "chinese yam", "淮山"}, Section: models.SectionProduce},
+	{ID: "taro", Names: []string{"芋头", "taro", "芋艿", "香芋"}, Section: models.SectionProduce},
+	{ID: "sweet_potato", Names: []string{"红薯", "sweet potato", "地瓜", "番薯"}, Section: models.SectionProduce},
+	{ID: "potato", Names: []string{"土豆", "potato", "马铃薯", "potatoes"}, Section: models.SectionProduce},
+	{ID: "carrot", Names: []string{"胡萝卜", "carrot"}, Section: models.SectionProduce},
+	{ID: "radish", Names: []string{"萝卜", "radish", "白萝卜", "daikon", "青萝卜"}, Section: models.SectionProduce},
+	{ID: "onion", Names: []string{"洋葱", "onion", "shallot", "红葱头", "shallots"}, Section: models.SectionProduce},
+	{ID: "scallion", Names: []string{"葱", "scallion", "小葱", "大葱", "葱花", "spring onion", "green onion", "leek", "韭葱"}, Section: models.SectionProduce},
+	{ID: "garlic", Names: []string{"大蒜", "garlic", "蒜", "蒜头", "蒜末", "蒜蓉", "蒜瓣"}, Section: models.SectionProduce},
+	{ID: "garlic_sprout", Names: []string{"蒜苗", "garlic sprouts", "蒜薹", "蒜苔", "garlic scapes", "青蒜"}, Section: models.SectionProduce},
+	{ID: "chinese_chive", Names: []string{"韭菜", "chinese chives", "韭黄", "chives"}, Section: models.SectionProduce},
+	{ID: "ginger", Names: []string{"生姜", "ginger", "姜", "姜片", "姜丝", "姜末", "嫩姜"}, Section: models.SectionProduce},
+	{ID: "cilantro", Names: []string{"香菜", "cilantro", "芫荽", "coriander"}, Section: models.SectionProduce},
+	{ID: "basil", Names: []string{"罗勒", "basil", "九层塔"}, Section: models.SectionProduce},
+	{ID: "mint", Names: []string{"薄荷", "mint"}, Section: models.SectionProduce},
+	{ID: "broccoli", Names: []string{"西兰花", "broccoli", "西蓝花"}, Section: models.SectionProduce},
+	{ID: "cauliflower", Names: []string{"菜花", "cauliflower", "花菜", "花椰菜"}, Section: models.SectionProduce},
+	{ID: "gai_lan", Names: []string{"芥蓝", "chinese broccoli", "gai lan"}, Section: models.SectionProduce},
+	{ID: "choy_sum", Names: []string{"菜心", "choy sum"}, Section: models.SectionProduce},
+	{ID: "mustard_greens", Names: []string{"芥菜", "mustard greens", "雪里蕻", "雪菜"}, Allergens: []models.Allergen{mustard}, Section: models.SectionProduce},
+	{ID: "lettuce", Names: []string{"生菜", "lettuce", "莴苣", "romaine"}, Section: models.SectionProduce},
+	{ID: "chrysanthemum_greens", Names: []string{"茼蒿", "chrysanthemum greens", "garland chrysanthemum"}, Section: models.SectionProduce},
+	{ID: "fennel", Names: []string{"茴香", "fennel", "茴香苗"}, Section: models.SectionProduce},
+	{ID: "artichoke", Names: []string{"洋蓟", "artichoke"}, Section: models.SectionProduce},
+	{ID: "beetroot", Names: []string{"甜菜根", "beetroot", "beet"}, Section: models.SectionProduce},
+	{ID: "kale", Names: []string{"羽衣甘蓝", "kale"}, Section: models.SectionProduce},
+	{ID: "wild_vegetable", Names: []string{"野菜", "wild greens", "马兰头", "蕨菜", "fiddlehead", "fiddleheads"}, Section: models.SectionProduce},
+
+	// --- 菌菇 ---
+	{ID: "shiitake", Names: []string{"香菇", "shiitake", "冬菇", "花菇", "shiitake mushroom"}, Section: models.SectionProduce},
+	{ID: "enoki", Names: []string{"金针菇", "enoki"}, Section: models.SectionProduce},
+	{ID: "king_oyster", Names: []string{"杏鲍菇", "king oyster mushroom"}, Section: models.SectionProduce},
+	{ID: "oyster_mushroom", Names: []string{"平菇", "oyster mushroom"}, Section: models.SectionProduce},
+	{ID: "matsutake", Names: []string{"松茸", "matsutake"}, Section: models.SectionProduce},
+	{ID: "morel", Names: []string{"羊肚菌", "morel", "morels"}, Section: models.SectionProduce},
+	{ID: "porcini", Names: []string{"牛肝菌", "porcini", "cep"}, Section: models.SectionProduce},
+	{ID: "chanterelle", Names: []string{"鸡枞", "chanterelle", "鸡枞菌", "鸡油菌"}, Section: models.SectionProduce},
+	{ID: "truffle", Names: []string{"松露", "truffle"}, Section: models.SectionProduce},
+	{ID: "wood_ear", Names: []string{"木耳", "wood ear", "黑木耳", "银耳", "white fungus"}, Section: models.SectionProduce},
+	{ID: "mushroom", Names: []string{"蘑菇", "mushroom", "口蘑", "button mushroom", "菌菇", "菌子", "蟹味菇", "白玉菇", "海鲜菇"}, Section: models.SectionProduce},
+
+	// --- 水果 ---
+	{ID: "strawberry", Names: []string{"草莓", "strawberry"}, Section: models.SectionProduce},
+	{ID: "cherry", Names: []string{"樱桃", "cherry", "车厘子"}, Section: models.SectionProduce},
+	{ID: "loquat", Names: []string{"枇杷", "loquat"}, Section: models.SectionProduce},
+	{ID: "bayberry", Names: []string{"杨梅", "bayberry", "yangmei"}, Section: models.SectionProduce},
+	{ID: "lychee", Names: []string{"荔枝", "lychee", "litchi"}, Section: models.SectionProduce},
+	{ID: "longan", Names: []string{"龙眼", "longan", "桂圆"}, Section: models.SectionProduce},
+	{ID: "mango", Names: []string{"芒果", "mango"}, Section: models.SectionProduce},
+	{ID: "peach", Names: []string{"桃子", "peach", "水蜜桃", "蟠桃", "桃"}, Section: models.SectionProduce},
+	{ID: "apricot", Names: []string{"杏", "apricot", "杏子"}, Section: models.SectionProduce},
+	{ID: "plum", Names: []string{"李子", "plum", "青梅", "梅子"}, Section: models.SectionProduce},
+	{ID: "watermelon", Names: []string{"西瓜", "watermelon"}, Section: models.SectionProduce},
+	{ID: "melon", Names: []string{"甜瓜", "melon", "哈密瓜", "香瓜", "cantaloupe"}, Section: models.SectionProduce},
+	{ID: "grape", Names: []string{"葡萄", "grape", "grapes", "提子"}, Section: models.SectionProduce},
+	{ID: "raisin", Names: []string{"葡萄干", "raisins", "raisin"}, Allergens: []models.Allergen{sulphites}, Section: models.SectionProduce},
+	{ID: "pear", Names: []string{"梨", "pear", "雪梨", "秋月梨"}, Section: models.SectionProduce},
+	{ID: "apple", Names: []string{"苹果", "apple"}, Section: models.SectionProduce},
+	{ID: "persimmon", Names: []string{"柿子", "persimmon"}, Section: models.SectionProduce},
+	{ID: "pomegranate", Names: []string{"石榴", "pomegranate"}, Section: models.SectionProduce},
+	{ID: "kiwi", Names: []string{"猕猴桃", "kiwi", "奇异果", "kiwifruit"}, Section: models.SectionProduce},
+	{ID: "citrus", Names: []string{"橘子", "mandarin", "柑橘", "橙子", "orange", "砂糖橘", "沃柑", "tangerine"}, Section: models.SectionProduce},
+	{ID: "pomelo", Names: []string{"柚子", "pomelo", "grapefruit", "西柚"}, Section: models.SectionProduce},
+	{ID: "lemon", Names: []string{"柠檬", "lemon", "lime", "青柠", "柠檬汁", "lemon juice"}, Section: models.SectionProduce},
+	{ID: "jujube", Names: []string{"红枣", "jujube", "枣", "red dates"}, Section: models.SectionProduce},
+	{ID: "fig", Names: []string{"无花果", "fig", "figs"}, Section: models.SectionProduce},
+	{ID: "blueberry", Names: []string{"蓝莓", "blueberry", "blueberries"}, Section: models.SectionProduce},
+	{ID: "pineapple", Names: []string{"菠萝", "pineapple", "凤梨"}, Section: models.SectionProduce},
+	{ID: "banana", Names: []string{"香蕉", "banana"}, Section: models.SectionProduce},
+	{ID: "durian", Names: []string{"榴莲", "durian"}, Section: models.SectionProduce},
+
+	// --- 坚果与种子 ---
+	{ID: "peanut", Names: []string{"花生", "peanut", "花生米", "花生碎", "groundnut", "peanut butter", "花生酱", "花生油", "peanut oil"}, Allergens: []models.Allergen{peanut}, Section: models.SectionPantry},
+	{ID: "walnut", Names: []string{"核桃", "walnut", "核桃仁"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "almond", Names: []string{"杏仁", "almond", "巴旦木", "almond milk", "杏仁露"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "cashew", Names: []string{"腰果", "cashew"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "pine_nut", Names: []string{"松子", "pine nut", "松仁", "pine nuts"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "chestnut", Names: []string{"板栗", "chestnut", "栗子"}, Section: models.SectionPantry},
+	{ID: "hazelnut", Names: []string{"榛子", "hazelnut"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "pistachio", Names: []string{"开心果", "pistachio"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "pecan", Names: []string{"碧根果", "pecan", "山核桃"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "macadamia", Names: []string{"夏威夷果", "macadamia"}, Allergens: []models.Allergen{treeNut}, Section: models.SectionPantry},
+	{ID: "ginkgo", Names: []string{"白果", "ginkgo nut", "银杏"}, Section: models.SectionPantry},
+	{ID: "coconut", Names: []string{"椰子", "coconut", "椰浆", "coconut milk", "椰奶"}, Section: models.SectionPantry},
+	{ID: "sesame", Names: []string{"芝麻", "sesame", "白芝麻", "黑芝麻", "芝麻酱", "tahini", "麻酱", "香油", "芝麻油", "麻油", "sesame oil", "sesame seeds"}, Allergens: []models.Allergen{sesame}, Section: models.SectionPantry},
+
+	// --- 肉类 ---
+	{ID: "pork", Names: []string{"猪肉", "pork", "五花肉", "里脊", "排骨", "猪排", "肉末", "肉馅", "pork belly", "spare ribs", "猪蹄", "猪肘"}, Section: models.SectionMeat},
+	{ID: "bacon", Names: []string{"培根", "bacon", "腊肉", "咸肉"}, Allergens: []models.Allergen{sulphites}, Section: models.SectionMeat},
+	{ID: "ham", Names: []string{"火腿", "ham", "金华火腿"}, Section: models.SectionMeat},
+	{ID: "sausage", Names: []string{"香肠", "sausage", "腊肠"}, Allergens: []models.Allergen{sulphites}, Section: models.SectionMeat},
+	{ID: "beef", Names: []string{"牛肉", "beef", "牛腩", "牛腱", "牛排", "steak", "brisket"}, Section: models.SectionMeat},
+	{ID: "lamb", Names: []string{"羊肉", "lamb", "羊排", "mutton"}, Section: models.SectionMeat},
+	{ID: "chicken", Names: []string{"鸡肉", "chicken", "鸡腿", "鸡翅", "鸡胸肉", "鸡丁", "土鸡", "三黄鸡", "chicken breast", "chicken thigh"}, Section: models.SectionMeat},
+	{ID: "duck", Names: []string{"鸭肉", "duck", "鸭子", "老鸭"}, Section: models.SectionMeat},
+	{ID: "goose", Names: []string{"鹅肉", "goose"}, Section: models.SectionMeat},
+	{ID: "rabbit", Names: []string{"兔肉", "rabbit"}, Section: models.SectionMeat},
+
+	// --- 蛋奶 ---
+	{ID: "egg", Names: []string{"鸡蛋", "egg", "蛋", "蛋液", "蛋黄", "蛋清", "鸭蛋", "咸蛋", "咸鸭蛋", "皮蛋", "鹌鹑蛋", "egg yolk", "egg white", "eggs", "mayonnaise", "蛋黄酱"}, Allergens: []models.Allergen{egg}, Section: models.SectionDairy},
+	{ID: "milk", Names: []string{"牛奶", "milk", "鲜奶", "全脂奶", "奶"}, Allergens: []models.Allergen{milk}, Section: models.SectionDairy},
+	{ID: "cream", Names: []string{"奶油", "cream", "淡奶油", "鲜奶油", "heavy cream", "sour cream", "酸奶油"}, Allergens: []models.Allergen{milk}, Section: models.SectionDairy},
+	{ID: "butter", Names: []string{"黄油", "butter", "牛油"}, Allergens: []models.Allergen{milk}, Section: models.SectionDairy},
+	{ID: "cheese", Names: []string{"奶酪", "cheese", "芝士", "起司", "parmesan", "mozzarella", "ricotta", "帕玛森"}, Allergens: []models.Allergen{milk}, Section: models.SectionDairy},
+	{ID: "yogurt", Names: []string{"酸奶", "yogurt", "yoghurt"}, Allergens: []models.Allergen{milk}, Section: models.SectionDairy},
+
+	// --- 海鲜 ---
+	{ID: "fish", Names: []string{"鱼", "fish", "鱼肉", "鱼片", "鱼柳", "fish fillet"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "sea_bass", Names: []string{"鲈鱼", "sea bass", "海鲈鱼", "bass"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "hairtail", Names: []string{"带鱼", "hairtail", "ribbonfish"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "yellow_croaker", Names: []string{"黄鱼", "yellow croaker", "大黄鱼", "小黄鱼"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "pomfret", Names: []string{"鲳鱼", "pomfret"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "mackerel", Names: []string{"鲅鱼", "mackerel", "马鲛鱼", "青花鱼", "鲭鱼"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "salmon", Names: []string{"三文鱼", "salmon", "鲑鱼"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "cod", Names: []string{"鳕鱼", "cod"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "tuna", Names: []string{"金枪鱼", "tuna"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "carp", Names: []string{"鲤鱼", "carp", "鲫鱼", "草鱼", "鳙鱼", "鲢鱼", "crucian carp"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "mandarin_fish", Names: []string{"鳜鱼", "mandarin fish", "桂鱼"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "shad", Names: []string{"鲥鱼", "shad", "刀鱼", "鲚鱼"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "eel", Names: []string{"鳗鱼", "eel", "黄鳝", "鳝鱼", "鳗"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "anchovy", Names: []string{"凤尾鱼", "anchovy", "鳀鱼", "anchovies"}, Allergens: []models.Allergen{fish}, Section: models.SectionSeafood},
+	{ID: "shrimp", Names: []string{"虾", "shrimp", "虾仁", "大虾", "基围虾", "对虾", "河虾", "海虾", "明虾", "prawn", "prawns", "虾米", "虾皮", "虾干"}, Allergens: []models.Allergen{shellfish}, Section: models.SectionSeafood},
+	{ID: "crayfish", Names: []string{"小龙虾", "crayfish", "crawfish"}, Allergens: []models.Allergen{shellfish}, Section: models.SectionSeafood},
+	{ID: "lobster", Names: []string{"龙虾", "lobster"}, Allergens: []models.Allergen{shellfish}, Section: models.SectionSeafood},
+	{ID: "crab", Names: []string{"螃蟹", "crab", "蟹", "大闸蟹", "梭子蟹", "蟹肉", "蟹黄", "hairy crab", "青蟹", "面包蟹"}, Allergens: []models.Allergen{shellfish}, Section: models.SectionSeafood},
+	{ID: "mantis_shrimp", Names: []string{"皮皮虾", "mantis shrimp", "濑尿虾", "虾蛄"}, Allergens: []models.Allergen{shellfish}, Section: models.SectionSeafood},
+	{ID: "oyster", Names: []string{"牡蛎", "oyster", "生蚝", "蚝", "海蛎子", "oysters"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "clam", Names: []string{"蛤蜊", "clam", "花蛤", "文蛤", "蚬子", "clams", "花甲"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "mussel", Names: []string{"青口", "mussel", "贻贝", "淡菜", "mussels"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "scallop", Names: []string{"扇贝", "scallop", "干贝", "瑶柱", "scallops"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "abalone", Names: []string{"鲍鱼", "abalone"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "razor_clam", Names: []string{"蛏子", "razor clam", "竹蛏"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "squid", Names: []string{"鱿鱼", "squid", "墨鱼", "乌贼", "cuttlefish", "calamari", "鱿鱼须"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "octopus", Names: []string{"章鱼", "octopus", "八爪鱼"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "snail", Names: []string{"田螺", "snail", "螺蛳", "海螺", "escargot", "whelk"}, Allergens: []models.Allergen{mollusc}, Section: models.SectionSeafood},
+	{ID: "sea_cucumber", Names: []string{"海参", "sea cucumber"}, Section: models.SectionSeafood},
+	{ID: "seaweed", Names: []string{"海带", "kelp", "紫菜", "裙带菜", "seaweed", "nori", "海苔"}, Section: models.SectionSeafood},
+
+	// --- 豆制品 ---
+	{ID: "tofu", Names: []string{"豆腐", "tofu", "嫩豆腐", "老豆腐", "豆干", "豆腐干", "千张", "腐竹", "油豆腐", "豆皮", "yuba"}, Allergens: []models.Allergen{soy}, Section: models.SectionDairy},
+	{ID: "soy_milk", Names: []string{"豆浆", "soy milk", "soymilk"}, Allergens: []models.Allergen{soy}, Section: models.SectionDairy},
+
+	// --- 调味品 ---
+	{ID: "stock", Names: []string{"高汤", "stock", "鸡汤", "chicken stock", "broth", "骨汤"}, Allergens: []models.Allergen{celery}, Section: models.SectionPantry},
+	{ID: "fish_sauce", Names: []string{"鱼露", "fish sauce"}, Allergens: []models.Allergen{fish}, Section: models.SectionPantry},
+	{ID: "oyster_sauce", Names: []string{"蚝油", "oyster sauce"}, Allergens: []models.Allergen{mollusc, wheat, soy}, Section: models.SectionPantry},
+	{ID: "soy_sauce", Names: []string{"酱油", "soy sauce", "生抽", "老抽", "味极鲜", "shoyu"}, Allergens: []models.Allergen{soy, wheat}, Section: models.SectionPantry},
+	{ID: "tamari", Names: []string{"无麸质酱油", "tamari"}, Allergens: []models.Allergen{soy}, Section: models.SectionPantry},
+	{ID: "doubanjiang", Names: []string{"豆瓣酱", "doubanjiang", "郫县豆瓣", "chili bean paste"}, Allergens: []models.Allergen{soy, wheat}, Section: models.SectionPantry},
+	{ID: "bean_paste", Names: []string{"黄豆酱", "soybean paste", "甜面酱", "大酱", "豆豉", "fermented black beans", "doenjang"}, Allergens: []models.Allergen{soy, wheat}, Section: models.SectionPantry},
+	{ID: "miso", Names: []string{"味噌", "miso"}, Allergens: []models.Allergen{soy}, Section: models.SectionPantry},
+	{ID: "hoisin", Names: []string{"海鲜酱", "hoisin sauce", "hoisin"}, Allergens: []models.Allergen{soy, wheat, sesame}, Section: models.SectionPantry},
+	{ID: "kung_pao", Names: []string{"宫保", "kung pao", "gong bao", "宫爆"}, Allergens: []models.Allergen{peanut}, Section: models.SectionPantry},
+	{ID: "yuxiang", Names: []string{"鱼香", "fish-fragrant", "yu xiang"}, Allergens: []models.Allergen{soy, wheat}, Section: models.SectionPantry},
+	{ID: "fermented_tofu", Names: []string{"腐乳", "fermented tofu", "南乳", "红腐乳"}, Allergens: []models.Allergen{soy}, Section: models.SectionPantry},
+	{ID: "vinegar", Names: []string{"醋", "vinegar", "香醋", "陈醋", "米醋", "白醋", "镇江香醋"}, Section: models.SectionPantry},
+	{ID: "wine_vinegar", Names: []string{"葡萄酒醋", "wine vinegar", "red wine vinegar", "balsamic vinegar", "意大利黑醋"}, Allergens: []models.Allergen{sulphites}, Section: models.SectionPantry},
+	{ID: "wine", Names: []string{"葡萄酒", "wine", "白葡萄酒", "红酒", "red wine", "white wine"}, Allergens: []models.Allergen{sulphites}, Section: models.SectionPantry},
+	{ID: "shaoxing_wine", Names: []string{"料酒", "shaoxing wine", "黄酒", "绍兴酒", "花雕", "cooking wine"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "mirin", Names: []string{"味醂", "mirin"}, Section: models.SectionPantry},
+	{ID: "mustard", Names: []string{"芥末", "mustard", "黄芥末", "芥末酱", "dijon mustard", "wasabi", "芥末油"}, Allergens: []models.Allergen{mustard}, Section: models.SectionPantry},
+	{ID: "salt", Names: []string{"盐", "salt", "食盐", "海盐"}, Section: models.SectionPantry},
+	{ID: "sugar", Names: []string{"糖", "sugar", "白糖", "冰糖", "红糖", "砂糖", "brown sugar", "rock sugar"}, Section: models.SectionPantry},
+	{ID: "honey", Names: []string{"蜂蜜", "honey"}, Section: models.SectionPantry},
+	{ID: "oil", Names: []string{"食用油", "cooking oil", "油", "植物油", "菜籽油", "vegetable oil", "橄榄油", "olive oil"}, Section: models.SectionPantry},
+	{ID: "sichuan_pepper", Names: []string{"花椒", "sichuan pepper", "花椒粉", "麻椒"}, Section: models.SectionPantry},
+	{ID: "pepper", Names: []string{"胡椒", "pepper", "白胡椒", "黑胡椒", "胡椒粉", "black pepper"}, Section: models.SectionPantry},
+	{ID: "star_anise", Names: []string{"八角", "star anise", "大料"}, Section: models.SectionPantry},
+	{ID: "cinnamon", Names: []string{"桂皮", "cinnamon", "肉桂"}, Section: models.SectionPantry},
+	{ID: "curry", Names: []string{"咖喱", "curry", "咖喱粉", "curry powder"}, Allergens: []models.Allergen{mustard, celery}, Section: models.SectionPantry},
+	{ID: "msg", Names: []string{"味精", "msg", "鸡精"}, Section: models.SectionPantry},
+	{ID: "starch", Names: []string{"淀粉", "starch", "玉米淀粉", "生粉", "cornstarch", "水淀粉", "土豆淀粉"}, Section: models.SectionPantry},
+
+	// --- 主食与谷物 ---
+	{ID: "rice", Names: []string{"大米", "rice", "米饭", "米", "糯米", "剩饭", "glutinous rice", "cooked rice"}, Section: models.SectionPantry},
+	{ID: "flour", Names: []string{"面粉", "flour", "中筋面粉", "低筋面粉", "高筋面粉", "all-purpose flour", "wheat flour"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "noodle", Names: []string{"面条", "noodles", "挂面", "拉面", "乌冬面", "udon", "ramen", "pasta", "意面", "spaghetti", "面", "noodle"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "soba", Names: []string{"荞麦面", "soba", "荞麦", "buckwheat", "buckwheat noodles"}, Allergens: []models.Allergen{buckwheat}, Section: models.SectionPantry},
+	{ID: "bread", Names: []string{"面包", "bread", "面包糠", "breadcrumbs", "吐司", "toast", "baguette", "panko"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "dumpling_wrapper", Names: []string{"饺子皮", "dumpling wrappers", "馄饨皮", "wonton wrappers", "春卷皮", "spring roll wrappers"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "gluten", Names: []string{"面筋", "seitan", "烤麸", "wheat gluten"}, Allergens: []models.Allergen{wheat}, Section: models.SectionPantry},
+	{ID: "barley", Names: []string{"大麦", "barley", "麦芽", "malt", "薏米", "pearl barley"}, Allergens: []models.Allergen{gluten}, Section: models.SectionPantry},
+	{ID: "rye", Names: []string{"黑麦", "rye"}, Allergens: []models.Allergen{gluten}, Section: models.SectionPantry},
+	{ID: "oats", Names: []string{"燕麦", "oats", "燕麦片", "oatmeal"}, Allergens: []models.Allergen{gluten}, Section: models.SectionPantry},
+	{ID: "rice_noodle", Names: []string{"米粉", "rice noodles", "河粉", "米线", "粉丝", "glass noodles", "vermicelli", "粉条"}, Section: models.SectionPantry},
+	{ID: "millet", Names: []string{"小米", "millet"}, Section: models.SectionPantry},
+	{ID: "quinoa", Names: []string{"藜麦", "quinoa"}, Section: models.SectionPantry},
+}
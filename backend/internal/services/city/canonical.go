@@ -0,0 +1,125 @@
+package city
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+const (
+	// canonicalTimeout 规范化城市时等待地理编码的最长时间，超时后使用规范化的名称
+	canonicalTimeout = 3 * time.Second
+	// geocodeTimeout 后台地理编码的最长时间，不随请求结束而取消
+	geocodeTimeout = 30 * time.Second
+	// unresolvedTTL 无法识别的城市名称的缓存时间，较短以便网络恢复后重新地理编码
+	unresolvedTTL = time.Hour
+	// maxCanonicalNames 缓存的规范化结果数
+	maxCanonicalNames = 10000
+)
+
+// canonicalNames 城市名称到规范形式的缓存，包括无法识别的名称，避免重复地理编码
+var canonicalNames = ttlcache.New[string, string](
+	ttlcache.WithTTL[string, string](cache.CityTTL),
+	ttlcache.WithCapacity[string, string](maxCanonicalNames),
+)
+
+// geocodeFlight 正在进行的一次城市地理编码，相同名称的并发请求等待同一次编码
+type geocodeFlight struct {
+	done      chan struct{}
+	canonical string
+}
+
+var (
+	geocodesMutex sync.Mutex
+	// geocodes 按规范化名称记录进行中的地理编码
+	geocodes = make(map[string]*geocodeFlight)
+)
+
+// Canonical returns the canonical form of a city for cache keys, so different
+// spellings and languages of a city ("Beijing", "北京", " beijing") share cache
+// entries: the coordinates of the geocoded city, rounded to two decimals. A city
+// that cannot be geocoded (or not in time) is identified by its normalized name.
+//
+// Until the first geocode of a city finishes (at most geocodeTimeout), requests that
+// gave up waiting use its name, so entries cached meanwhile are keyed by the name and
+// are not shared with later requests keyed by coordinates; they expire by their TTL.
+func Canonical(ctx context.Context, name string) string {
+	return NewGeocoder(cache.DefaultCache).Canonical(ctx, name)
+}
+
+// Canonical returns the canonical form of a city for cache keys, see the package function
+func (g *Geocoder) Canonical(ctx context.Context, name string) string {
+	normalized := cache.NormalizeName(name)
+	if normalized == "" {
+		return ""
+	}
+	if item := canonicalNames.Get(normalized); item != nil {
+		return item.Value()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, canonicalTimeout)
+	defer cancel()
+
+	f := g.geocode(name, normalized)
+	select {
+	case <-f.done:
+		return f.canonical
+	case <-ctx.Done():
+		return normalized
+	}
+}
+
+// geocode returns the running geocode of a city, starting one when there is none
+func (g *Geocoder) geocode(name, normalized string) *geocodeFlight {
+	geocodesMutex.Lock()
+	defer geocodesMutex.Unlock()
+
+	if f, ok := geocodes[normalized]; ok {
+		return f
+	}
+	f := &geocodeFlight{done: make(chan struct{})}
+	// 等待锁期间其他请求的地理编码可能已完成
+	if item := canonicalNames.Get(normalized); item != nil {
+		f.canonical = item.Value()
+		close(f.done)
+		return f
+	}
+	geocodes[normalized] = f
+
+	go func() {
+		// 地理编码使用独立的 context：请求等待超时或被取消后仍继续，并记录结果供之后的请求直接使用
+		searchCtx, cancel := context.WithTimeout(context.Background(), geocodeTimeout)
+		defer cancel()
+
+		canonical, ttl := normalized, unresolvedTTL
+		if info, err := g.SearchCity(searchCtx, name); err == nil {
+			canonical, ttl = fmt.Sprintf("%.2f/%.2f", info.Latitude, info.Longitude), ttlcache.DefaultTTL
+		}
+		canonicalNames.Set(normalized, canonical, ttl)
+
+		geocodesMutex.Lock()
+		delete(geocodes, normalized)
+		geocodesMutex.Unlock()
+		f.canonical = canonical
+		close(f.done)
+	}()
+	return f
+}
+
+// KeyForms returns the forms a city takes in cache keys, for purging its entries:
+// its canonical form and its normalized name, which keys the entries cached before
+// its first geocode finished
+func KeyForms(ctx context.Context, name string) []string {
+	canonical := Canonical(ctx, name)
+	if canonical == "" {
+		return nil
+	}
+	if normalized := cache.NormalizeName(name); normalized != canonical {
+		return []string{canonical, normalized}
+	}
+	return []string{canonical}
+}
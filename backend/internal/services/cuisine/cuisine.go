@@ -0,0 +1,249 @@
+// Package cuisine provides the regional cuisine taxonomy used to steer recipe
+// generation and to normalize the cuisine reported by the model
+package cuisine
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// order 菜系的固定输出顺序：中国地方菜系在前，其他地区在后
+var order = []models.Cuisine{
+	models.CuisineCantonese,
+	models.CuisineSichuan,
+	models.CuisineHunan,
+	models.CuisineShandong,
+	models.CuisineJiangnan,
+	models.CuisineFujian,
+	models.CuisineAnhui,
+	models.CuisineChaozhou,
+	models.CuisineHakka,
+	models.CuisineBeijing,
+	models.CuisineNortheastern,
+	models.CuisineNorthwestern,
+	models.CuisineYunnan,
+	models.CuisineTaiwanese,
+	models.CuisineChineseHome,
+	models.CuisineJapaneseHome,
+	models.CuisineKorean,
+	models.CuisineThai,
+	models.CuisineVietnamese,
+	models.CuisineIndian,
+	models.CuisineItalian,
+	models.CuisineFrench,
+	models.CuisineProvencal,
+	models.CuisineSpanish,
+	models.CuisineMediterranean,
+	models.CuisineBritish,
+	models.CuisineMiddleEastern,
+	models.CuisineMexican,
+	models.CuisineAmerican,
+	models.CuisineOther,
+}
+
+// aliases 菜系名称及常见别称 -> 菜系代码（中英文显示名称另由 i18n 提供）
+var aliases = map[string]models.Cuisine{
+	"广东菜":          models.CuisineCantonese,
+	"广府菜":          models.CuisineCantonese,
+	"粤式":           models.CuisineCantonese,
+	"港式":           models.CuisineCantonese,
+	"四川菜":          models.CuisineSichuan,
+	"川味":           models.CuisineSichuan,
+	"szechuan":     models.CuisineSichuan,
+	"湖南菜":          models.CuisineHunan,
+	"山东菜":          models.CuisineShandong,
+	"江南菜":          models.CuisineJiangnan,
+	"苏菜":           models.CuisineJiangnan,
+	"浙菜":           models.CuisineJiangnan,
+	"淮扬菜":          models.CuisineJiangnan,
+	"本帮菜":          models.CuisineJiangnan,
+	"上海菜":          models.CuisineJiangnan,
+	"杭帮菜":          models.CuisineJiangnan,
+	"shanghainese": models.CuisineJiangnan,
+	"huaiyang":     models.CuisineJiangnan,
+	"zhejiang":     models.CuisineJiangnan,
+	"jiangsu":      models.CuisineJiangnan,
+	"福建菜":          models.CuisineFujian,
+	"闽南菜":          models.CuisineFujian,
+	"徽州菜":          models.CuisineAnhui,
+	"潮州菜":          models.CuisineChaozhou,
+	"teochew":      models.CuisineChaozhou,
+	"chiuchow":     models.CuisineChaozhou,
+	"北京菜":          models.CuisineBeijing,
+	"京味":           models.CuisineBeijing,
+	"东北":           models.CuisineNortheastern,
+	"西北":           models.CuisineNorthwestern,
+	"陕西菜":          models.CuisineNorthwestern,
+	"新疆菜":          models.CuisineNorthwestern,
+	"滇菜":           models.CuisineYunnan,
+	"云南菜":          models.CuisineYunnan,
+	"贵州菜":          models.CuisineYunnan,
+	"黔菜":           models.CuisineYunnan,
+	"家常菜":          models.CuisineChineseHome,
+	"中餐":           models.CuisineChineseHome,
+	"chinese":      models.CuisineChineseHome,
+	"日料":           models.CuisineJapaneseHome,
+	"日本料理":         models.CuisineJapaneseHome,
+	"和食":           models.CuisineJapaneseHome,
+	"日式":           models.CuisineJapaneseHome,
+	"japanese":     models.CuisineJapaneseHome,
+	"washoku":      models.CuisineJapaneseHome,
+	"韩国料理":         models.CuisineKorean,
+	"韩餐":           models.CuisineKorean,
+	"泰国菜":          models.CuisineThai,
+	"泰餐":           models.CuisineThai,
+	"越南料理":         models.CuisineVietnamese,
+	"印度料理":         models.CuisineIndian,
+	"意式":           models.CuisineItalian,
+	"意大利料理":        models.CuisineItalian,
+	"法餐":           models.CuisineFrench,
+	"法国菜":          models.CuisineFrench,
+	"普罗旺斯":         models.CuisineProvencal,
+	"provence":     models.CuisineProvencal,
+	"provencal":    models.CuisineProvencal,
+	"西班牙料理":        models.CuisineSpanish,
+	"希腊菜":          models.CuisineMediterranean,
+	"greek":        models.CuisineMediterranean,
+	"英国菜":          models.CuisineBritish,
+	"english":      models.CuisineBritish,
+	"土耳其菜":         models.CuisineMiddleEastern,
+	"黎巴嫩菜":         models.CuisineMiddleEastern,
+	"lebanese":     models.CuisineMiddleEastern,
+	"turkish":      models.CuisineMiddleEastern,
+	"墨西哥料理":        models.CuisineMexican,
+	"美国菜":          models.CuisineAmerican,
+	"美式":           models.CuisineAmerican,
+}
+
+// localByPlace 城市或国家/地区 -> 当地菜系，按顺序匹配，城市在前、国家在后
+var localByPlace = []struct {
+	places  []string
+	cuisine models.Cuisine
+}{
+	{[]string{"广州", "佛山", "深圳", "香港", "澳门", "guangzhou", "canton", "foshan", "shenzhen", "hong kong", "macau"}, models.CuisineCantonese},
+	{[]string{"潮州", "汕头", "揭阳", "chaozhou", "shantou"}, models.CuisineChaozhou},
+	{[]string{"梅州", "meizhou"}, models.CuisineHakka},
+	{[]string{"成都", "重庆", "乐山", "chengdu", "chongqing"}, models.CuisineSichuan},
+	{[]string{"长沙", "changsha"}, models.CuisineHunan},
+	{[]string{"济南", "青岛", "烟台", "jinan", "qingdao"}, models.CuisineShandong},
+	{[]string{"上海", "杭州", "苏州", "南京", "扬州", "宁波", "无锡", "绍兴", "shanghai", "hangzhou", "suzhou", "nanjing", "yangzhou", "ningbo"}, models.CuisineJiangnan},
+	{[]string{"福州", "厦门", "泉州", "fuzhou", "xiamen"}, models.CuisineFujian},
+	{[]string{"合肥", "黄山", "hefei", "huangshan"}, models.CuisineAnhui},
+	{[]string{"北京", "beijing", "peking"}, models.CuisineBeijing},
+	{[]string{"哈尔滨", "沈阳", "长春", "大连", "harbin", "shenyang", "changchun", "dalian"}, models.CuisineNortheastern},
+	{[]string{"西安", "兰州", "乌鲁木齐", "银川", "xi'an", "xian", "lanzhou", "urumqi"}, models.CuisineNorthwestern},
+	{[]string{"昆明", "丽江", "大理", "贵阳", "kunming", "lijiang", "dali", "guiyang"}, models.CuisineYunnan},
+	{[]string{"台北", "台南", "高雄", "台湾", "taipei", "tainan", "kaohsiung", "taiwan"}, models.CuisineTaiwanese},
+	{[]string{"马赛", "尼斯", "阿维尼翁", "普罗旺斯", "marseille", "nice", "avignon", "provence"}, models.CuisineProvencal},
+	{[]string{"巴塞罗那", "马德里", "barcelona", "madrid", "西班牙", "spain"}, models.CuisineSpanish},
+	{[]string{"雅典", "athens", "希腊", "greece"}, models.CuisineMediterranean},
+	{[]string{"伊斯坦布尔", "迪拜", "贝鲁特", "istanbul", "dubai", "beirut", "土耳其", "turkey", "阿联酋", "uae"}, models.CuisineMiddleEastern},
+	{[]string{"中国", "china"}, models.CuisineChineseHome},
+	{[]string{"日本", "东京", "大阪", "京都", "japan", "tokyo", "osaka", "kyoto"}, models.CuisineJapaneseHome},
+	{[]string{"韩国", "首尔", "釜山", "korea", "seoul", "busan"}, models.CuisineKorean},
+	{[]string{"泰国", "曼谷", "清迈", "thailand", "bangkok", "chiang mai"}, models.CuisineThai},
+	{[]string{"越南", "河内", "胡志明", "vietnam", "hanoi", "ho chi minh"}, models.CuisineVietnamese},
+	{[]string{"印度", "孟买", "新德里", "india", "mumbai", "delhi"}, models.CuisineIndian},
+	{[]string{"意大利", "罗马", "米兰", "佛罗伦萨", "italy", "rome", "milan", "florence"}, models.CuisineItalian},
+	{[]string{"法国", "巴黎", "里昂", "france", "paris", "lyon"}, models.CuisineFrench},
+	{[]string{"英国", "伦敦", "uk", "united kingdom", "london"}, models.CuisineBritish},
+	{[]string{"墨西哥", "mexico"}, models.CuisineMexican},
+	{[]string{"美国", "纽约", "洛杉矶", "旧金山", "usa", "united states", "new york", "los angeles", "san francisco"}, models.CuisineAmerican},
+}
+
+// All returns every cuisine code in display order
+func All() []models.Cuisine {
+	result := make([]models.Cuisine, len(order))
+	copy(result, order)
+	return result
+}
+
+// Normalize converts a cuisine code, Chinese or English name or common alias into a
+// cuisine code. ok is false for unknown cuisines.
+func Normalize(name string) (models.Cuisine, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if key == "" {
+		return "", false
+	}
+	key = strings.TrimSuffix(strings.TrimSuffix(key, " cuisine"), " food")
+
+	for _, code := range order {
+		if key == string(code) ||
+			key == strings.ToLower(i18n.GetCuisine("zh", string(code))) ||
+			key == strings.ToLower(i18n.GetCuisine("en", string(code))) {
+			return code, true
+		}
+	}
+	if code, ok := aliases[key]; ok {
+		return code, true
+	}
+	return "", false
+}
+
+// FromModel normalizes the cuisine reported by the model, falling back to other
+func FromModel(name string) models.Cuisine {
+	if code, ok := Normalize(name); ok {
+		return code
+	}
+	return models.CuisineOther
+}
+
+// Local returns the local cuisine of a city (or its country). ok is false for
+// places that are not in the built-in table; the model decides in that case.
+func Local(city string) (models.Cuisine, bool) {
+	text := strings.ToLower(strings.TrimSpace(city))
+	if text == "" {
+		return "", false
+	}
+	// 英文地名按整词匹配，避免 "nice" 命中 "Venice"、"uk" 命中 "Fukuoka"
+	words := " " + strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}), " ") + " "
+	for _, entry := range localByPlace {
+		for _, place := range entry.places {
+			if isASCII(place) {
+				if strings.Contains(words, " "+place+" ") {
+					return entry.cuisine, true
+				}
+			} else if strings.Contains(text, place) {
+				return entry.cuisine, true
+			}
+		}
+	}
+	return "", false
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// Name returns the display name of a cuisine in the given language
+func Name(code models.Cuisine, lang string) string {
+	return i18n.GetCuisine(lang, string(code))
+}
+
+// List returns every cuisine with its display name in the given language
+func List(lang string) []models.CuisineInfo {
+	result := make([]models.CuisineInfo, 0, len(order))
+	for _, code := range order {
+		result = append(result, models.CuisineInfo{Code: code, Name: Name(code, lang)})
+	}
+	return result
+}
+
+// Codes returns the comma-separated cuisine codes, used in prompts
+func Codes() string {
+	codes := make([]string, 0, len(order))
+	for _, code := range order {
+		codes = append(codes, string(code))
+	}
+	return strings.Join(codes, ", ")
+}
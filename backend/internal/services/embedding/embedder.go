@@ -0,0 +1,162 @@
+// Package embedding embeds recipes as vectors through a configurable embedding
+// provider and keeps them in a local index in the persistent store. The index powers
+// similar recipe lookups and near-duplicate detection in recommendations.
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ahhsitt/helloagents-go/pkg/core/llm"
+	"github.com/eat-only-in-season/backend/pkg/config"
+)
+
+// Embedder turns texts into vectors. Vectors returned by an Embedder are L2-normalized,
+// so the cosine similarity of two vectors is their dot product.
+type Embedder interface {
+	// Model identifies the provider and model; vectors of different models are not comparable
+	Model() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Default embedding models
+const (
+	defaultOpenAIModel    = "text-embedding-3-small"
+	defaultDashScopeModel = "text-embedding-v3"
+	defaultOllamaModel    = "nomic-embed-text"
+)
+
+// NewEmbedder creates the embedder selected by the configuration. It returns nil
+// when embeddings are turned off (EMBEDDING_PROVIDER=none).
+func NewEmbedder(cfg *config.Config) (Embedder, error) {
+	name := cfg.EmbeddingProvider
+	if name == "" {
+		switch {
+		case cfg.HasOpenAI():
+			name = "openai"
+		case cfg.HasDashScope():
+			name = "dashscope"
+		case cfg.HasOllama():
+			name = "ollama"
+		default:
+			name = "hash"
+		}
+	}
+
+	switch name {
+	case "none":
+		return nil, nil
+	case "hash":
+		return NewHashEmbedder(cfg.EmbeddingDimensions), nil
+	case "openai":
+		if !cfg.HasOpenAI() {
+			return nil, fmt.Errorf("嵌入服务 openai 需要配置 OPENAI_API_KEY")
+		}
+		model := modelOrDefault(cfg.EmbeddingModel, defaultOpenAIModel)
+		opts := []llm.Option{llm.WithAPIKey(cfg.OpenAIAPIKey), llm.WithEmbeddingModel(model)}
+		if cfg.OpenAIBaseURL != "" {
+			opts = append(opts, llm.WithBaseURL(cfg.OpenAIBaseURL))
+		}
+		client, err := llm.NewOpenAI(opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &providerEmbedder{model: "openai:" + model, provider: client, batch: 64}, nil
+	case "dashscope":
+		if !cfg.HasDashScope() {
+			return nil, fmt.Errorf("嵌入服务 dashscope 需要配置 DASHSCOPE_API_KEY")
+		}
+		model := modelOrDefault(cfg.EmbeddingModel, defaultDashScopeModel)
+		client, err := llm.NewOpenAI(
+			llm.WithAPIKey(cfg.DashScopeAPIKey),
+			llm.WithBaseURL("https://dashscope.aliyuncs.com/compatible-mode/v1"),
+			llm.WithEmbeddingModel(model),
+		)
+		if err != nil {
+			return nil, err
+		}
+		// DashScope 兼容模式每次最多嵌入 10 条文本
+		return &providerEmbedder{model: "dashscope:" + model, provider: client, batch: 10}, nil
+	case "ollama":
+		if !cfg.HasOllama() {
+			return nil, fmt.Errorf("嵌入服务 ollama 需要配置 OLLAMA_HOST")
+		}
+		model := modelOrDefault(cfg.EmbeddingModel, defaultOllamaModel)
+		client := llm.NewOllamaClient(
+			llm.WithOllamaBaseURL(cfg.OllamaHost),
+			llm.WithOllamaModel(model),
+		)
+		return &providerEmbedder{model: "ollama:" + model, provider: client, batch: 16}, nil
+	}
+	return nil, fmt.Errorf("不支持的嵌入服务：%s", name)
+}
+
+// modelOrDefault returns the configured model, or the default model of the provider
+func modelOrDefault(model, defaultModel string) string {
+	if model != "" {
+		return model
+	}
+	return defaultModel
+}
+
+// providerEmbedder embeds texts through an LLM provider's embedding API
+type providerEmbedder struct {
+	model    string
+	provider llm.Provider
+	// batch 每次请求最多嵌入的文本数
+	batch int
+}
+
+// Model implements Embedder
+func (e *providerEmbedder) Model() string {
+	return e.model
+}
+
+// Embed implements Embedder, sending the texts in batches the provider accepts
+func (e *providerEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.batch {
+		end := min(start+e.batch, len(texts))
+		batch, err := e.provider.Embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("嵌入服务返回 %d 个向量，预期 %d 个", len(batch), end-start)
+		}
+		for _, v := range batch {
+			vectors = append(vectors, normalize(v))
+		}
+	}
+	return vectors, nil
+}
+
+// normalize scales v to unit length in place; a zero vector is returned as is
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	scale := float32(1 / math.Sqrt(sum))
+	for i := range v {
+		v[i] *= scale
+	}
+	return v
+}
+
+// Cosine returns the cosine similarity of two normalized vectors, 0 when their
+// dimensions differ
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
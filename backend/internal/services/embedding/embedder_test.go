@@ -0,0 +1,27 @@
+package embedding
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"same", []float32{3, 4}, []float32{3, 4}, 1},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 2}, 0},
+		{"45 degrees", []float32{1, 0}, []float32{1, 1}, math.Sqrt2 / 2},
+		{"different dimensions", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		got := Cosine(normalize(tt.a), normalize(tt.b))
+		if math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: Cosine = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
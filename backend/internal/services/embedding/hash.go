@@ -0,0 +1,101 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+// minHashDimensions hash 嵌入的最小维度
+const minHashDimensions = 16
+
+// HashEmbedder embeds texts by feature hashing their words and, for Chinese and
+// other CJK text, their characters and pairs of adjacent characters. It needs no
+// model or network and is deterministic, so it serves offline use and tests; its
+// similarity is lexical rather than semantic.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a hash embedder producing vectors of dims dimensions
+func NewHashEmbedder(dims int) *HashEmbedder {
+	return &HashEmbedder{dims: max(dims, minHashDimensions)}
+}
+
+// Model implements Embedder
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("hash:%d", e.dims)
+}
+
+// Embed implements Embedder
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		v := make([]float32, e.dims)
+		for _, f := range features(text) {
+			h := fnv.New32a()
+			h.Write([]byte(f.token))
+			sum := h.Sum32()
+			// 最高位决定符号，减小哈希冲突带来的偏差
+			weight := f.weight
+			if sum&(1<<31) != 0 {
+				weight = -weight
+			}
+			v[int(sum%uint32(e.dims))] += weight
+		}
+		vectors = append(vectors, normalize(v))
+	}
+	return vectors, nil
+}
+
+// feature 参与哈希的词项及其权重
+type feature struct {
+	token  string
+	weight float32
+}
+
+// features splits lower-cased text into words, and CJK runs into single characters
+// (half weight) and pairs of adjacent characters
+func features(text string) []feature {
+	var result []feature
+	var run []rune
+	cjk := false
+	flush := func() {
+		switch {
+		case len(run) == 0:
+		case !cjk:
+			result = append(result, feature{token: string(run), weight: 1})
+		default:
+			for i := range run {
+				result = append(result, feature{token: string(run[i]), weight: 0.5})
+				if i+1 < len(run) {
+					result = append(result, feature{token: string(run[i : i+2]), weight: 1})
+				}
+			}
+		}
+		run = run[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			if !cjk {
+				flush()
+				cjk = true
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if cjk {
+				flush()
+				cjk = false
+			}
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return result
+}
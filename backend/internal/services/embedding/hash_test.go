@@ -0,0 +1,39 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHashEmbedderDeterministic(t *testing.T) {
+	texts := []string{"番茄炒蛋\n家常快手菜\n番茄, 鸡蛋", "Braised pork belly\npork, soy sauce"}
+	first, err := NewHashEmbedder(64).Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	second, err := NewHashEmbedder(64).Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	for i := range texts {
+		if len(first[i]) != 64 {
+			t.Fatalf("vector %d has %d dimensions, want 64", i, len(first[i]))
+		}
+		for j := range first[i] {
+			if first[i][j] != second[i][j] {
+				t.Fatalf("vector %d differs at %d: %v != %v", i, j, first[i][j], second[i][j])
+			}
+		}
+		if sim := Cosine(first[i], first[i]); sim < 0.999 || sim > 1.001 {
+			t.Errorf("vector %d is not normalized: self-similarity %v", i, sim)
+		}
+	}
+}
+
+func TestHashEmbedderMinDimensions(t *testing.T) {
+	e := NewHashEmbedder(1)
+	if e.Model() != "hash:16" {
+		t.Errorf("Model() = %q, want hash:16", e.Model())
+	}
+}
@@ -0,0 +1,355 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+const (
+	// defaultLimit 默认返回的相似菜谱数
+	defaultLimit = 10
+	// maxLimit 单次最多返回的相似菜谱数
+	maxLimit = 50
+	// syncBatch 每批嵌入的菜谱数
+	syncBatch = 64
+	// syncInterval 后台同步的间隔
+	syncInterval = 10 * time.Minute
+	// maxCachedTexts 推荐去重时缓存的文本向量数，超出后清空
+	maxCachedTexts = 2048
+)
+
+// ErrRecipeNotFound 菜谱库中没有该菜谱
+var ErrRecipeNotFound = errors.New("recipe not found")
+
+// Service keeps the embeddings of the recipe library and answers similarity queries
+// by brute-force cosine similarity over an in-memory copy of the index
+type Service struct {
+	store    *store.Store
+	embedder Embedder
+	// threshold 近似重复的相似度阈值
+	threshold float64
+
+	// syncMutex 同一时间只进行一次同步
+	syncMutex sync.Mutex
+	mutex     sync.RWMutex
+	loaded    bool
+	index     map[string]store.RecipeEmbedding
+	// texts 推荐结果文本的向量缓存，缓存命中的推荐不必重复嵌入
+	texts map[string][]float32
+}
+
+// NewService creates a new embedding service. Recipes whose embeddings have a cosine
+// similarity of at least threshold are near-duplicates.
+func NewService(st *store.Store, embedder Embedder, threshold float64) *Service {
+	return &Service{
+		store:     st,
+		embedder:  embedder,
+		threshold: threshold,
+		index:     make(map[string]store.RecipeEmbedding),
+		texts:     make(map[string][]float32),
+	}
+}
+
+// Model returns the embedding model of the index
+func (s *Service) Model() string {
+	return s.embedder.Model()
+}
+
+// Start embeds the recipe library now and then periodically, so recipes added by
+// detail generation are picked up, until ctx is done. It blocks; run it in a goroutine.
+func (s *Service) Start(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.Sync(ctx)
+		switch {
+		case ctx.Err() != nil:
+		case err != nil:
+			log.Printf("[EmbeddingService] 嵌入同步失败: %v", err)
+		case n > 0:
+			log.Printf("[EmbeddingService] 已为 %d 道菜谱生成嵌入向量（%s）", n, s.Model())
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("[EmbeddingService] 后台嵌入同步已停止")
+			return
+		}
+	}
+}
+
+// Sync embeds the recipes added to or updated in the recipe library since the last
+// sync, or embedded by another model, and returns how many were embedded
+func (s *Service) Sync(ctx context.Context) (int, error) {
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+	if err := s.load(); err != nil {
+		return 0, err
+	}
+
+	model := s.Model()
+	embedded := 0
+	for {
+		recipes, err := s.store.UnembeddedRecipes(model, syncBatch)
+		if err != nil {
+			return embedded, err
+		}
+		if len(recipes) == 0 {
+			return embedded, nil
+		}
+
+		// 无法解析的菜谱保存空向量，不在每次同步时重试
+		embeddings := make([]store.RecipeEmbedding, len(recipes))
+		var texts []string
+		var positions []int
+		for i, r := range recipes {
+			embeddings[i] = store.RecipeEmbedding{RecipeID: r.ID, Lang: r.Lang, Model: model, SourceUpdatedAt: r.UpdatedAt}
+			var detail models.NewRecipeDetail
+			if err := json.Unmarshal([]byte(r.Data), &detail); err != nil {
+				log.Printf("[EmbeddingService] 菜谱 %s 解析失败，跳过嵌入: %v", r.ID, err)
+				continue
+			}
+			ingredients := make([]string, 0, len(detail.Ingredients))
+			for _, ing := range detail.Ingredients {
+				ingredients = append(ingredients, ing.Name)
+			}
+			texts = append(texts, recipeText(detail.Title, detail.Description, ingredients, detail.Tags))
+			positions = append(positions, i)
+		}
+
+		if len(texts) > 0 {
+			vectors, err := s.embedder.Embed(ctx, texts)
+			if err != nil {
+				return embedded, err
+			}
+			for j, v := range vectors {
+				embeddings[positions[j]].Vector = v
+			}
+		}
+		if err := s.store.SaveEmbeddings(embeddings); err != nil {
+			return embedded, err
+		}
+
+		s.mutex.Lock()
+		for _, e := range embeddings {
+			if len(e.Vector) > 0 {
+				s.index[e.RecipeID] = e
+			} else {
+				delete(s.index, e.RecipeID)
+			}
+		}
+		s.mutex.Unlock()
+		embedded += len(embeddings)
+	}
+}
+
+// load reads the stored embeddings of the current model into memory, once
+func (s *Service) load() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.loaded {
+		return nil
+	}
+
+	embeddings, err := s.store.ListEmbeddings(s.Model())
+	if err != nil {
+		return err
+	}
+	for _, e := range embeddings {
+		s.index[e.RecipeID] = e
+	}
+	s.loaded = true
+	return nil
+}
+
+// Similar returns the recipes of the library most similar to a recipe, in the same
+// language, most similar first. Results at or above the near-duplicate threshold are
+// marked as duplicates. A recipe not embedded yet is embedded first.
+func (s *Service) Similar(ctx context.Context, recipeID string, limit int) (*models.SimilarRecipesResponse, error) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	limit = min(limit, maxLimit)
+
+	target, ok, err := s.lookup(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.SimilarRecipesResponse{RecipeID: recipeID, Model: s.Model(), Results: []models.SimilarRecipe{}}
+	if !ok {
+		return result, nil
+	}
+
+	type candidate struct {
+		id    string
+		score float64
+	}
+	var candidates []candidate
+	s.mutex.RLock()
+	for id, e := range s.index {
+		if id == recipeID || e.Lang != target.Lang {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, score: Cosine(target.Vector, e.Vector)})
+	}
+	s.mutex.RUnlock()
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].id < candidates[j].id
+	})
+	candidates = candidates[:min(limit, len(candidates))]
+
+	ids := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.id)
+	}
+	summaries, err := s.store.RecipeSummaries(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range candidates {
+		summary, ok := summaries[c.id]
+		if !ok {
+			continue
+		}
+		result.Results = append(result.Results, models.SimilarRecipe{
+			Recipe:     summary,
+			Similarity: c.score,
+			Duplicate:  c.score >= s.threshold,
+		})
+	}
+	return result, nil
+}
+
+// lookup returns the embedding of a recipe, syncing first when it is not embedded yet.
+// ok is false for a stored recipe without an embedding (it could not be decoded).
+func (s *Service) lookup(ctx context.Context, recipeID string) (store.RecipeEmbedding, bool, error) {
+	if err := s.load(); err != nil {
+		return store.RecipeEmbedding{}, false, err
+	}
+	s.mutex.RLock()
+	e, ok := s.index[recipeID]
+	s.mutex.RUnlock()
+	if ok {
+		return e, true, nil
+	}
+
+	if _, _, err := s.store.GetRecipe(recipeID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return store.RecipeEmbedding{}, false, ErrRecipeNotFound
+		}
+		return store.RecipeEmbedding{}, false, err
+	}
+	if _, err := s.Sync(ctx); err != nil {
+		return store.RecipeEmbedding{}, false, err
+	}
+	s.mutex.RLock()
+	e, ok = s.index[recipeID]
+	s.mutex.RUnlock()
+	return e, ok, nil
+}
+
+// Dedupe drops the recommendations that are near-duplicates of a recommendation
+// ranked before them, so variations of the same dish are recommended once, and
+// records how many were dropped. The recommendations are left as they are when
+// they cannot be embedded.
+func (s *Service) Dedupe(ctx context.Context, resp *models.GetRecipesByIngredientsResponse) error {
+	if len(resp.Recipes) < 2 {
+		return nil
+	}
+
+	vectors, err := s.embedTexts(ctx, resp.Recipes)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]models.RecipeWithMatch, 0, len(resp.Recipes))
+	var keptVectors [][]float32
+	for i, r := range resp.Recipes {
+		duplicate := false
+		for _, v := range keptVectors {
+			if Cosine(vectors[i], v) >= s.threshold {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			log.Printf("[EmbeddingService] 推荐中去除近似重复的菜谱: %s", r.Title)
+			continue
+		}
+		kept = append(kept, r)
+		keptVectors = append(keptVectors, vectors[i])
+	}
+	resp.DuplicatesRemoved += len(resp.Recipes) - len(kept)
+	resp.Recipes = kept
+	return nil
+}
+
+// embedTexts returns the vectors of the texts of recommended recipes, embedding only
+// the texts not in the cache
+func (s *Service) embedTexts(ctx context.Context, recipes []models.RecipeWithMatch) ([][]float32, error) {
+	texts := make([]string, len(recipes))
+	vectors := make([][]float32, len(recipes))
+	var missing []string
+	s.mutex.RLock()
+	for i, r := range recipes {
+		texts[i] = recipeText(r.Title, r.Description, r.Ingredients, r.Tags)
+		if v, ok := s.texts[texts[i]]; ok {
+			vectors[i] = v
+		} else {
+			missing = append(missing, texts[i])
+		}
+	}
+	s.mutex.RUnlock()
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := s.embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	if len(s.texts)+len(missing) > maxCachedTexts {
+		s.texts = make(map[string][]float32)
+	}
+	for j, text := range missing {
+		s.texts[text] = embedded[j]
+	}
+	s.mutex.Unlock()
+
+	j := 0
+	for i := range vectors {
+		if vectors[i] == nil {
+			vectors[i] = embedded[j]
+			j++
+		}
+	}
+	return vectors, nil
+}
+
+// recipeText builds the text embedded for a recipe: its title, description,
+// ingredient names and tags
+func recipeText(title, description string, ingredients, tags []string) string {
+	parts := []string{strings.TrimSpace(title), strings.TrimSpace(description)}
+	if len(ingredients) > 0 {
+		parts = append(parts, strings.Join(ingredients, ", "))
+	}
+	if len(tags) > 0 {
+		parts = append(parts, strings.Join(tags, ", "))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,36 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+func TestDedupe(t *testing.T) {
+	s := NewService(nil, NewHashEmbedder(256), 0.9)
+	resp := &models.GetRecipesByIngredientsResponse{Recipes: []models.RecipeWithMatch{
+		{ID: "1", Title: "番茄炒蛋", Description: "家常快手菜", Ingredients: []string{"番茄", "鸡蛋", "葱"}},
+		{ID: "2", Title: "清蒸鲈鱼", Description: "鲜嫩清淡", Ingredients: []string{"鲈鱼", "姜", "葱"}},
+		{ID: "3", Title: "番茄炒鸡蛋", Description: "家常快手菜", Ingredients: []string{"番茄", "鸡蛋", "葱"}},
+	}}
+
+	if err := s.Dedupe(context.Background(), resp); err != nil {
+		t.Fatalf("Dedupe: %v", err)
+	}
+	if len(resp.Recipes) != 2 || resp.Recipes[0].ID != "1" || resp.Recipes[1].ID != "2" {
+		t.Errorf("Dedupe kept %v, want recipes 1 and 2", resp.Recipes)
+	}
+	if resp.DuplicatesRemoved != 1 {
+		t.Errorf("DuplicatesRemoved = %d, want 1", resp.DuplicatesRemoved)
+	}
+
+	// 缓存的文本向量与重新嵌入的结果相同
+	again := &models.GetRecipesByIngredientsResponse{Recipes: resp.Recipes}
+	if err := s.Dedupe(context.Background(), again); err != nil {
+		t.Fatalf("Dedupe: %v", err)
+	}
+	if len(again.Recipes) != 2 || again.DuplicatesRemoved != 0 {
+		t.Errorf("Dedupe of distinct recipes kept %d and removed %d, want 2 and 0", len(again.Recipes), again.DuplicatesRemoved)
+	}
+}
@@ -0,0 +1,133 @@
+package feedback
+
+import (
+	"sort"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+const (
+	// preferenceThreshold 累计权重达到该值的菜系或食材才视为喜欢（或不喜欢）
+	preferenceThreshold = 2
+	// maxProfileCuisines 口味偏好中最多列出的菜系数
+	maxProfileCuisines = 3
+	// maxProfileIngredients 口味偏好中最多列出的食材数
+	maxProfileIngredients = 8
+)
+
+// weight 一条评价的倾向：5 星 +2、4 星 +1、3 星 0、2 星 -1、1 星或拒绝 -2；
+// 未评分但做过视为 +1
+func weight(f *models.RecipeFeedback) int {
+	if Rejected(f) {
+		return -2
+	}
+	switch f.Rating {
+	case 5:
+		return 2
+	case 4:
+		return 1
+	case 3:
+		return 0
+	case 2:
+		return -1
+	}
+	if f.CookedAt != nil {
+		return 1
+	}
+	return 0
+}
+
+// Rejected reports whether a dish must not be recommended to the user again:
+// it was rejected explicitly or rated one star (see store.RejectedTitles)
+func Rejected(f *models.RecipeFeedback) bool {
+	return f.Rejected || f.Rating == 1
+}
+
+// BuildProfile derives a taste profile from feedback, most recent first, and the titles
+// of all rejected dishes, which are not limited to the feedback read. Cuisines and
+// ingredients (staples excluded) of liked dishes count for them, those of disliked dishes
+// against them. Returns nil when the feedback carries no signal.
+func BuildProfile(items []models.RecipeFeedback, rejected []string) *models.FeedbackProfile {
+	cuisines := make(map[models.Cuisine]int)
+	var cuisineOrder []models.Cuisine
+	ingredients := make(map[string]int)
+	names := make(map[string]string)
+	var ingredientOrder []string
+	profile := &models.FeedbackProfile{RejectedTitles: rejected}
+
+	for i := range items {
+		f := &items[i]
+		w := weight(f)
+		if w == 0 {
+			continue
+		}
+
+		if f.Cuisine != "" && f.Cuisine != models.CuisineOther && f.Cuisine != models.CuisineLocal {
+			if _, ok := cuisines[f.Cuisine]; !ok {
+				cuisineOrder = append(cuisineOrder, f.Cuisine)
+			}
+			cuisines[f.Cuisine] += w
+		}
+
+		seen := make(map[string]bool)
+		for _, name := range f.Ingredients {
+			key := ingredientKey(name)
+			if key == "" || seen[key] || catalog.IsStaple(name) {
+				continue
+			}
+			seen[key] = true
+			if _, ok := ingredients[key]; !ok {
+				ingredientOrder = append(ingredientOrder, key)
+				names[key] = name
+			}
+			ingredients[key] += w
+		}
+	}
+
+	profile.LikedCuisines, profile.DislikedCuisines = split(cuisineOrder, cuisines, maxProfileCuisines)
+	liked, disliked := split(ingredientOrder, ingredients, maxProfileIngredients)
+	for _, key := range liked {
+		profile.LikedIngredients = append(profile.LikedIngredients, names[key])
+	}
+	for _, key := range disliked {
+		profile.DislikedIngredients = append(profile.DislikedIngredients, names[key])
+	}
+
+	if len(profile.LikedCuisines) == 0 && len(profile.DislikedCuisines) == 0 &&
+		len(profile.LikedIngredients) == 0 && len(profile.DislikedIngredients) == 0 &&
+		len(profile.RejectedTitles) == 0 {
+		return nil
+	}
+	return profile
+}
+
+// split returns the keys scoring at least the threshold (strongest first) and those
+// scoring at most minus the threshold; ties keep the most recent first
+func split[K comparable](order []K, scores map[K]int, limit int) (liked, disliked []K) {
+	for _, key := range order {
+		switch {
+		case scores[key] >= preferenceThreshold:
+			liked = append(liked, key)
+		case scores[key] <= -preferenceThreshold:
+			disliked = append(disliked, key)
+		}
+	}
+	sort.SliceStable(liked, func(i, j int) bool { return scores[liked[i]] > scores[liked[j]] })
+	sort.SliceStable(disliked, func(i, j int) bool { return scores[disliked[i]] < scores[disliked[j]] })
+	if len(liked) > limit {
+		liked = liked[:limit]
+	}
+	if len(disliked) > limit {
+		disliked = disliked[:limit]
+	}
+	return liked, disliked
+}
+
+// ingredientKey 同一食材的不同写法（如「西红柿」与「番茄」）归为一类
+func ingredientKey(name string) string {
+	if e, ok := catalog.Lookup(name); ok {
+		return e.ID
+	}
+	return catalog.Normalize(name)
+}
@@ -0,0 +1,162 @@
+// Package feedback records users' ratings, "cooked it" marks, rejections and notes on
+// recipes, aggregates ratings per recipe and derives taste profiles that personalize
+// later recommendations
+package feedback
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+// maxFeedback 归纳口味偏好与列出评价时读取的最近评价条数
+const maxFeedback = 500
+
+var (
+	// ErrEmptyFeedback 评价请求未包含任何字段
+	ErrEmptyFeedback = errors.New("empty feedback")
+	// ErrFeedbackNotFound 用户未评价该菜谱
+	ErrFeedbackNotFound = errors.New("feedback not found")
+)
+
+// Service provides recipe feedback functionality
+type Service struct {
+	store *store.Store
+}
+
+// NewService creates a new feedback service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st}
+}
+
+// Subject describes the recipe feedback is given on; its title, cuisine and ingredients
+// are kept with the feedback to derive the user's taste profile
+type Subject struct {
+	RecipeID    string
+	Title       string
+	Cuisine     models.Cuisine
+	Ingredients []string
+}
+
+// FromDetail returns the feedback subject of a recipe detail
+func FromDetail(detail *models.NewRecipeDetail) Subject {
+	names := make([]string, 0, len(detail.Ingredients))
+	for _, ing := range detail.Ingredients {
+		names = append(names, ing.Name)
+	}
+	return Subject{RecipeID: detail.ID, Title: detail.Title, Cuisine: detail.Cuisine, Ingredients: names}
+}
+
+// FromContext returns the feedback subject of a recommended recipe whose detail has not
+// been generated
+func FromContext(rc *models.RecommendationContext) Subject {
+	return Subject{RecipeID: rc.RecipeID, Title: rc.Title, Cuisine: rc.Cuisine, Ingredients: rc.Ingredients}
+}
+
+// Submit creates or updates the feedback of a user on a recipe; fields missing from
+// the request keep their previous values
+func (s *Service) Submit(userID string, subject Subject, req *models.RecipeFeedbackRequest) (*models.RecipeFeedback, error) {
+	if req.Rating == nil && req.Cooked == nil && req.Rejected == nil && req.Note == nil {
+		return nil, ErrEmptyFeedback
+	}
+
+	now := time.Now()
+	feedback, err := s.store.GetFeedback(userID, subject.RecipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		feedback = &models.RecipeFeedback{RecipeID: subject.RecipeID, CreatedAt: now}
+	} else if err != nil {
+		return nil, err
+	}
+	feedback.Title = subject.Title
+	feedback.Cuisine = subject.Cuisine
+	feedback.Ingredients = subject.Ingredients
+
+	if req.Rating != nil {
+		feedback.Rating = *req.Rating
+	}
+	if req.Cooked != nil {
+		feedback.CookedAt = nil
+		if *req.Cooked {
+			feedback.CookedAt = &now
+		}
+	}
+	if req.Rejected != nil {
+		feedback.Rejected = *req.Rejected
+	}
+	if req.Note != nil {
+		feedback.Note = strings.TrimSpace(*req.Note)
+	}
+	feedback.UpdatedAt = now
+
+	if err := s.store.SaveFeedback(userID, feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// Get returns the feedback of a user on a recipe (nil if none) with the aggregate ratings
+func (s *Service) Get(userID, recipeID string) (*models.RecipeFeedbackResponse, error) {
+	ratings, err := s.store.RecipeRatings(recipeID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.RecipeFeedbackResponse{Ratings: *ratings}
+	if userID == "" {
+		return resp, nil
+	}
+
+	feedback, err := s.store.GetFeedback(userID, recipeID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	resp.Feedback = feedback
+	return resp, nil
+}
+
+// Ratings returns the aggregate ratings of a recipe
+func (s *Service) Ratings(recipeID string) (*models.RecipeRatings, error) {
+	return s.store.RecipeRatings(recipeID)
+}
+
+// Delete removes the feedback of a user on a recipe
+func (s *Service) Delete(userID, recipeID string) error {
+	err := s.store.DeleteFeedback(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrFeedbackNotFound
+	}
+	return err
+}
+
+// List returns the feedback of a user, most recently updated first, with the taste
+// profile derived from it
+func (s *Service) List(userID string) (*models.ListFeedbackResponse, error) {
+	items, err := s.store.ListFeedback(userID, maxFeedback)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := s.store.RejectedTitles(userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.ListFeedbackResponse{Feedback: items}
+	if profile := BuildProfile(items, rejected); profile != nil {
+		resp.Profile = *profile
+	}
+	return resp, nil
+}
+
+// Profile returns the taste profile of a user, nil when the feedback carries no signal
+func (s *Service) Profile(userID string) (*models.FeedbackProfile, error) {
+	items, err := s.store.ListFeedback(userID, maxFeedback)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := s.store.RejectedTitles(userID)
+	if err != nil {
+		return nil, err
+	}
+	return BuildProfile(items, rejected), nil
+}
@@ -0,0 +1,236 @@
+package ingredient
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/services/city"
+	"github.com/eat-only-in-season/backend/internal/services/seasonality"
+)
+
+// ErrServiceUnavailable 缓存未命中且食材服务未初始化
+var ErrServiceUnavailable = errors.New("ingredient service unavailable")
+
+// LoadSeasonalIngredients returns the seasonal ingredients of a city for the current month,
+// reading the two-tier cache first and generating (and caching) them on a miss.
+// service may be nil, in which case only the cache is consulted.
+func LoadSeasonalIngredients(ctx context.Context, service *Service, cityName, lang string) (*models.GetIngredientsResponse, error) {
+	// 生成缓存键：语言+规范化的城市+月份
+	month := time.Now().Month()
+	cacheKey := cache.IngredientsKey(city.Canonical(ctx, cityName), lang, int(month))
+
+	// 尝试从双层缓存获取，变旧的列表在后台重新生成
+	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if service != nil {
+			refresh = func(ctx context.Context) (any, error) {
+				return service.GetSeasonalIngredients(ctx, cityName, lang)
+			}
+		}
+		var cached models.GetIngredientsResponse
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
+			log.Printf("[IngredientService] 缓存命中: %s", cacheKey)
+			return &cached, nil
+		}
+		log.Printf("[IngredientService] 缓存未命中: %s", cacheKey)
+	}
+
+	if service == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	result, err := service.GetSeasonalIngredients(ctx, cityName, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	// 存入双层缓存
+	if cache.DefaultManager != nil {
+		if err := cache.DefaultManager.SetJSON(cacheKey, result); err != nil {
+			log.Printf("[IngredientService] 缓存写入失败: %v", err)
+		} else {
+			log.Printf("[IngredientService] 缓存写入成功: %s", cacheKey)
+		}
+	}
+
+	return result, nil
+}
+
+// Names flattens a seasonal ingredient response into a list of ingredient names
+func Names(resp *models.GetIngredientsResponse) []string {
+	if resp == nil {
+		return nil
+	}
+	var names []string
+	for _, group := range resp.Categories {
+		for _, ing := range group.Ingredients {
+			names = append(names, ing.Name)
+		}
+	}
+	return names
+}
+
+// Flatten returns all seasonal ingredients of a response regardless of category
+func Flatten(resp *models.GetIngredientsResponse) []models.SeasonalIngredient {
+	if resp == nil {
+		return nil
+	}
+	var all []models.SeasonalIngredient
+	for _, group := range resp.Categories {
+		all = append(all, group.Ingredients...)
+	}
+	return all
+}
+
+// ErrIngredientNotFound 无法根据 ID 找到食材（未提供名称且不在当月应季列表中）
+var ErrIngredientNotFound = errors.New("ingredient not found")
+
+// LoadSeasonalCalendar returns the year-round seasonal calendar of a city,
+// reading the two-tier cache first and generating (and caching) it on a miss.
+// service may be nil, in which case only the cache is consulted.
+func LoadSeasonalCalendar(ctx context.Context, service *Service, cityName, lang string) (*models.GetSeasonalCalendarResponse, error) {
+	cacheKey := cache.CalendarKey(city.Canonical(ctx, cityName), lang)
+	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if service != nil {
+			refresh = func(ctx context.Context) (any, error) {
+				return service.GetSeasonalCalendar(ctx, cityName, lang)
+			}
+		}
+		var cached models.GetSeasonalCalendarResponse
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
+			log.Printf("[IngredientService] 应季日历缓存命中: %s", cacheKey)
+			return &cached, nil
+		}
+		log.Printf("[IngredientService] 应季日历缓存未命中: %s", cacheKey)
+	}
+
+	if service == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	result, err := service.GetSeasonalCalendar(ctx, cityName, lang)
+	if err != nil {
+		return nil, err
+	}
+	storeJSON(cacheKey, result)
+	return result, nil
+}
+
+// LoadIngredientCalendar returns the year-round calendar of an ingredient in a city.
+// Without a name the ingredient is looked up by ID in the city's seasonal list.
+// Cached data is preferred over LLM calls, in order: the ingredient calendar itself,
+// the city calendar, and the season months of the current seasonal list.
+func LoadIngredientCalendar(ctx context.Context, service *Service, id, name, cityName, lang string) (*models.GetIngredientCalendarResponse, error) {
+	// 当月应季列表只读缓存，用于根据 ID 查找食材以及兜底的应季月份
+	canonical := city.Canonical(ctx, cityName)
+	seasonal := cachedSeasonal(canonical, lang)
+
+	var listed *models.SeasonalIngredient
+	for _, ing := range Flatten(seasonal) {
+		if ing.ID == id || (name != "" && catalog.SameIngredient(ing.Name, name)) {
+			listed = &ing
+			break
+		}
+	}
+	if name == "" {
+		if listed == nil {
+			return nil, ErrIngredientNotFound
+		}
+		name = listed.Name
+	}
+
+	cacheKey := cache.IngredientCalendarKey(canonical, catalog.Normalize(name), lang)
+	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if service != nil {
+			refresh = func(ctx context.Context) (any, error) {
+				return service.GetIngredientCalendar(ctx, id, name, cityName, lang)
+			}
+		}
+		var cached models.GetIngredientCalendarResponse
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
+			log.Printf("[IngredientService] 食材日历缓存命中: %s", cacheKey)
+			cached.Ingredient.ID = id
+			return &cached, nil
+		}
+		log.Printf("[IngredientService] 食材日历缓存未命中: %s", cacheKey)
+	}
+
+	result, ok := ingredientCalendarFromCache(id, name, canonical, lang, seasonal, listed)
+	if !ok {
+		if service == nil {
+			return nil, ErrServiceUnavailable
+		}
+		var err error
+		result, err = service.GetIngredientCalendar(ctx, id, name, cityName, lang)
+		if err != nil {
+			return nil, err
+		}
+	}
+	storeJSON(cacheKey, result)
+	return result, nil
+}
+
+// FindListed looks up an ingredient by ID in the cached seasonal list of a city for the current month
+func FindListed(ctx context.Context, id, cityName, lang string) (*models.SeasonalIngredient, bool) {
+	for _, ing := range Flatten(cachedSeasonal(city.Canonical(ctx, cityName), lang)) {
+		if ing.ID == id {
+			return &ing, true
+		}
+	}
+	return nil, false
+}
+
+// cachedSeasonal returns the cached seasonal list of a canonical city for the current month, nil on a miss
+func cachedSeasonal(canonical, lang string) *models.GetIngredientsResponse {
+	if cache.DefaultManager == nil {
+		return nil
+	}
+	var cached models.GetIngredientsResponse
+	if !cache.DefaultManager.GetJSON(cache.IngredientsKey(canonical, lang, int(time.Now().Month())), &cached) {
+		return nil
+	}
+	return &cached
+}
+
+// ingredientCalendarFromCache builds an ingredient calendar from the cached calendar of a
+// canonical city or from the season months of the seasonal list; ok is false when neither
+// has the ingredient
+func ingredientCalendarFromCache(id, name, canonical, lang string, seasonal *models.GetIngredientsResponse, listed *models.SeasonalIngredient) (*models.GetIngredientCalendarResponse, bool) {
+	if cache.DefaultManager != nil {
+		var calendar models.GetSeasonalCalendarResponse
+		if cache.DefaultManager.GetJSON(cache.CalendarKey(canonical, lang), &calendar) {
+			for _, ing := range calendar.Ingredients {
+				if catalog.SameIngredient(ing.Name, name) {
+					ing.ID = id
+					return &models.GetIngredientCalendarResponse{Location: calendar.Location, Ingredient: ing}, true
+				}
+			}
+		}
+	}
+
+	if listed != nil && len(seasonality.NormalizeMonths(listed.SeasonMonths)) > 0 {
+		ing := seasonality.Calendar(name, listed.Category, listed.SeasonMonths, nil)
+		ing.ID = id
+		return &models.GetIngredientCalendarResponse{Location: seasonal.Location, Ingredient: ing}, true
+	}
+	return nil, false
+}
+
+// storeJSON 写入双层缓存，失败只记录日志
+func storeJSON(key string, v any) {
+	if cache.DefaultManager == nil {
+		return
+	}
+	if err := cache.DefaultManager.SetJSON(key, v); err != nil {
+		log.Printf("[IngredientService] 缓存写入失败: %v", err)
+	} else {
+		log.Printf("[IngredientService] 缓存写入成功: %s", key)
+	}
+}
@@ -0,0 +1,201 @@
+package ingredient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/seasonality"
+)
+
+// rawCalendarIngredient LLM 返回的食材应季月份
+type rawCalendarIngredient struct {
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	Months     []int  `json:"months"`
+	PeakMonths []int  `json:"peakMonths"`
+}
+
+// rawCalendarLocation LLM 返回的地区信息
+type rawCalendarLocation struct {
+	MatchedName string `json:"matchedName"`
+	Country     string `json:"country"`
+}
+
+// GetSeasonalCalendar returns the year-round seasonal produce chart of a city
+func (s *Service) GetSeasonalCalendar(ctx context.Context, city string, lang string) (*models.GetSeasonalCalendarResponse, error) {
+	if !s.provider.HasLLM() {
+		return nil, fmt.Errorf("没有可用的 LLM 服务，请配置 API Key")
+	}
+
+	response, err := s.callLLM(ctx, s.buildCalendarPrompt(city, lang), lang)
+	if err != nil {
+		return nil, fmt.Errorf("获取应季日历失败: %w", err)
+	}
+
+	var raw struct {
+		Location    rawCalendarLocation     `json:"location"`
+		Ingredients []rawCalendarIngredient `json:"ingredients"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &raw); err != nil {
+		return nil, fmt.Errorf("解析应季日历响应失败: JSON 解析失败: %w", err)
+	}
+
+	result := &models.GetSeasonalCalendarResponse{
+		Location:    calendarLocation(city, raw.Location),
+		Ingredients: make([]models.IngredientCalendar, 0, len(raw.Ingredients)),
+	}
+	for _, ing := range raw.Ingredients {
+		name := strings.TrimSpace(ing.Name)
+		if name == "" || len(seasonality.NormalizeMonths(ing.Months)) == 0 {
+			continue
+		}
+		result.Ingredients = append(result.Ingredients,
+			seasonality.Calendar(name, mapCategoryToCode(ing.Category), ing.Months, ing.PeakMonths))
+	}
+	result.Months = seasonality.Grid(result.Ingredients)
+
+	return result, nil
+}
+
+// GetIngredientCalendar returns the year-round availability of a single ingredient in a city
+func (s *Service) GetIngredientCalendar(ctx context.Context, ingredientID, ingredientName, city, lang string) (*models.GetIngredientCalendarResponse, error) {
+	if !s.provider.HasLLM() {
+		return nil, fmt.Errorf("没有可用的 LLM 服务，请配置 API Key")
+	}
+
+	response, err := s.callLLM(ctx, s.buildIngredientCalendarPrompt(ingredientName, city, lang), lang)
+	if err != nil {
+		return nil, fmt.Errorf("获取食材应季日历失败: %w", err)
+	}
+
+	var raw struct {
+		Location   rawCalendarLocation   `json:"location"`
+		Ingredient rawCalendarIngredient `json:"ingredient"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &raw); err != nil {
+		return nil, fmt.Errorf("解析食材应季日历响应失败: JSON 解析失败: %w", err)
+	}
+
+	calendar := seasonality.Calendar(ingredientName, mapCategoryToCode(raw.Ingredient.Category),
+		raw.Ingredient.Months, raw.Ingredient.PeakMonths)
+	calendar.ID = ingredientID
+
+	return &models.GetIngredientCalendarResponse{
+		Location:   calendarLocation(city, raw.Location),
+		Ingredient: calendar,
+	}, nil
+}
+
+// calendarLocation builds the location of a calendar for the current month
+func calendarLocation(city string, raw rawCalendarLocation) models.Location {
+	return models.Location{
+		InputName:   city,
+		MatchedName: raw.MatchedName,
+		Country:     raw.Country,
+		Month:       int(time.Now().Month()),
+	}
+}
+
+// buildCalendarPrompt builds the prompt for the year-round seasonal calendar of a city
+func (s *Service) buildCalendarPrompt(city string, lang string) string {
+	var sb strings.Builder
+
+	langInstruction := i18n.GetPrompt(lang, "language_instruction")
+	if langInstruction == "" {
+		langInstruction = "所有输出必须使用简体中文。"
+	}
+
+	if lang == "en" {
+		sb.WriteString("You are a professional nutritionist and ingredient expert with extensive knowledge of seasonal ingredients worldwide.\n\n")
+		sb.WriteString("## Task\n")
+		sb.WriteString(fmt.Sprintf("Please create a year-round seasonal produce calendar for: %s\n\n", city))
+
+		sb.WriteString("## Requirements\n")
+		sb.WriteString("1. List 30-50 ingredients with a clear season in this region, covering every month of the year\n")
+		sb.WriteString("2. Include vegetables, fruits, seafood and local specialties; exclude year-round staples (eggs, dairy, common meats, rice, potato, onion, etc.)\n")
+		sb.WriteString("3. months: all months (1-12) the ingredient is locally in season; peakMonths: the months it is at its best\n")
+		sb.WriteString("4. Take the hemisphere and climate of the region into account\n")
+		sb.WriteString(fmt.Sprintf("5. %s\n\n", langInstruction))
+
+		sb.WriteString("## JSON Output Format\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "location": {"matchedName": "matched city/region name", "country": "country (optional)"},
+  "ingredients": [
+    {"name": "ingredient name", "category": "vegetable", "months": [3, 4, 5], "peakMonths": [4]}
+  ]
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("category must be one of: meat, vegetable, fruit, seafood, other\n")
+		sb.WriteString("\nPlease output JSON only, no additional text.")
+	} else {
+		sb.WriteString("你是一位专业的营养师和食材专家，精通全球各地的应季食材知识。\n\n")
+		sb.WriteString("## 任务\n")
+		sb.WriteString(fmt.Sprintf("请为「%s」制作一份全年应季食材日历。\n\n", city))
+
+		sb.WriteString("## 要求\n")
+		sb.WriteString("1. 列出该地区 30-50 种季节性明显的食材，覆盖全年每个月份\n")
+		sb.WriteString("2. 包括蔬菜、水果、海鲜和地方特产；排除全年供应的常见食材（蛋奶、常规肉类、大米、土豆、洋葱等）\n")
+		sb.WriteString("3. months 为该食材在当地应季的全部月份（1-12），peakMonths 为品质最佳的高峰月份\n")
+		sb.WriteString("4. 考虑该地区所在半球和气候\n")
+		sb.WriteString(fmt.Sprintf("5. %s\n\n", langInstruction))
+
+		sb.WriteString("## JSON 输出格式\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "location": {"matchedName": "匹配到的实际城市/地区名", "country": "国家/地区（可选）"},
+  "ingredients": [
+    {"name": "食材名称", "category": "vegetable", "months": [3, 4, 5], "peakMonths": [4]}
+  ]
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("category 只能是：meat、vegetable、fruit、seafood、other\n")
+		sb.WriteString("\n请只输出 JSON，不要添加其他说明文字。")
+	}
+
+	return sb.String()
+}
+
+// buildIngredientCalendarPrompt builds the prompt for the year-round calendar of one ingredient
+func (s *Service) buildIngredientCalendarPrompt(ingredientName, city, lang string) string {
+	var sb strings.Builder
+
+	if lang == "en" {
+		sb.WriteString("You are a professional nutritionist and ingredient expert.\n\n")
+		sb.WriteString("## Task\n")
+		sb.WriteString(fmt.Sprintf("In which months is \"%s\" locally in season in %s, and when is it at its peak?\n\n", ingredientName, city))
+		sb.WriteString("## JSON Output Format\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "location": {"matchedName": "matched city/region name", "country": "country (optional)"},
+  "ingredient": {"category": "vegetable", "months": [3, 4, 5], "peakMonths": [4]}
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("months: all months (1-12) in season; peakMonths: the best months; category: meat, vegetable, fruit, seafood or other\n")
+		sb.WriteString("\nPlease output JSON only, no additional text.")
+	} else {
+		sb.WriteString("你是一位专业的营养师和食材专家。\n\n")
+		sb.WriteString("## 任务\n")
+		sb.WriteString(fmt.Sprintf("「%s」在「%s」当地哪些月份应季？哪些月份品质最佳？\n\n", ingredientName, city))
+		sb.WriteString("## JSON 输出格式\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "location": {"matchedName": "匹配到的实际城市/地区名", "country": "国家/地区（可选）"},
+  "ingredient": {"category": "vegetable", "months": [3, 4, 5], "peakMonths": [4]}
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("months 为应季的全部月份（1-12），peakMonths 为高峰月份，category 只能是 meat、vegetable、fruit、seafood、other\n")
+		sb.WriteString("\n请只输出 JSON，不要添加其他说明文字。")
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,128 @@
+// Package leftover scores recommended recipes by how completely they use up
+// the user's leftovers and estimates the food waste they avoid
+package leftover
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/services/shopping"
+)
+
+// Normalize trims leftovers and drops empty and duplicate names
+func Normalize(leftovers []models.Leftover) []models.Leftover {
+	result := make([]models.Leftover, 0, len(leftovers))
+	for _, l := range leftovers {
+		name := strings.TrimSpace(l.Name)
+		if name == "" || contains(result, name) {
+			continue
+		}
+		result = append(result, models.Leftover{Name: name, Amount: strings.TrimSpace(l.Amount)})
+	}
+	return result
+}
+
+// Names returns the names of leftovers
+func Names(leftovers []models.Leftover) []string {
+	names := make([]string, 0, len(leftovers))
+	for _, l := range leftovers {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// Fingerprint returns a stable cache key fragment for a list of leftovers
+func Fingerprint(leftovers []models.Leftover) string {
+	parts := make([]string, 0, len(leftovers))
+	for _, l := range leftovers {
+		parts = append(parts, catalog.Normalize(l.Name)+"="+catalog.Normalize(l.Amount))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Annotate matches the leftover usage reported by the model against the declared
+// leftovers, then sets the leftover score (the average share of each leftover used
+// up, unused leftovers count as 0) and the estimated waste avoided in grams.
+func Annotate(r *models.RecipeWithMatch, leftovers []models.Leftover) {
+	reported := r.LeftoverUsage
+	r.LeftoverUsage = nil
+	r.LeftoverScore = 0
+	r.WasteAvoidedGrams = 0
+	if len(leftovers) == 0 {
+		return
+	}
+
+	total, waste := 0.0, 0.0
+	for _, l := range leftovers {
+		usage, ok := findUsage(reported, l.Name)
+		if !ok {
+			// 模型未报告用量但菜谱用到了该食材，按用掉一半估算
+			if !usesIngredient(r, l.Name) {
+				continue
+			}
+			usage = models.LeftoverUsage{Fraction: 0.5}
+		}
+		usage.Name = l.Name
+		usage.Available = l.Amount
+		usage.Fraction = clamp(usage.Fraction)
+
+		// 剩余量可换算为克时，用量不超过剩余量；模型未给出克数时按比例估算
+		if grams, ok := shopping.Grams(l.Amount); ok {
+			if usage.UsedGrams <= 0 {
+				usage.UsedGrams = usage.Fraction * grams
+			}
+			usage.UsedGrams = math.Min(usage.UsedGrams, grams)
+			if usage.Fraction == 0 {
+				usage.Fraction = clamp(usage.UsedGrams / grams)
+			}
+		}
+		usage.UsedGrams = math.Round(math.Max(usage.UsedGrams, 0))
+		if usage.Fraction == 0 && usage.UsedGrams > 0 {
+			usage.Fraction = 0.5 // 只有克数、剩余量无法换算时同样按一半估算
+		}
+
+		total += usage.Fraction
+		waste += usage.UsedGrams
+		r.LeftoverUsage = append(r.LeftoverUsage, usage)
+	}
+
+	r.LeftoverScore = math.Round(total/float64(len(leftovers))*100) / 100
+	r.WasteAvoidedGrams = int(waste)
+}
+
+// findUsage returns the reported usage of a leftover
+func findUsage(reported []models.LeftoverUsage, name string) (models.LeftoverUsage, bool) {
+	for _, u := range reported {
+		if catalog.SameIngredient(u.Name, name) {
+			return u, true
+		}
+	}
+	return models.LeftoverUsage{}, false
+}
+
+// usesIngredient reports whether a recipe lists an ingredient
+func usesIngredient(r *models.RecipeWithMatch, name string) bool {
+	for _, ing := range r.Ingredients {
+		if catalog.SameIngredient(ing, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(leftovers []models.Leftover, name string) bool {
+	for _, l := range leftovers {
+		if catalog.SameIngredient(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp(fraction float64) float64 {
+	return math.Max(0, math.Min(1, fraction))
+}
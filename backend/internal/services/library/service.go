@@ -0,0 +1,132 @@
+// Package library keeps generated recipes in the persistent store, independent of cache
+// eviction, together with each user's favourites and viewing history
+package library
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+const (
+	// maxHistory 每个用户保留的浏览历史条数
+	maxHistory = 200
+	// defaultHistoryLimit 浏览历史默认返回条数
+	defaultHistoryLimit = 50
+	// maxFavourites 每个用户最多收藏的菜谱数
+	maxFavourites = 500
+)
+
+var (
+	// ErrRecipeNotFound 菜谱不存在（未生成过详情）
+	ErrRecipeNotFound = errors.New("recipe not found")
+	// ErrFavouriteNotFound 菜谱不在收藏中
+	ErrFavouriteNotFound = errors.New("favourite not found")
+	// ErrFavouritesFull 收藏数量达到上限
+	ErrFavouritesFull = errors.New("favourites are full")
+)
+
+// Service provides the recipe library
+type Service struct {
+	store *store.Store
+}
+
+// NewService creates a new library service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st}
+}
+
+// Save persists a generated recipe detail
+func (s *Service) Save(detail *models.NewRecipeDetail, lang string) error {
+	return s.store.SaveRecipe(detail, lang)
+}
+
+// Get returns a persisted recipe detail
+func (s *Service) Get(recipeID string) (*models.NewRecipeDetail, error) {
+	detail, _, err := s.store.GetRecipe(recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrRecipeNotFound
+	}
+	return detail, err
+}
+
+// RecordView persists a viewed recipe detail (a no-op when it is already stored
+// unchanged) and adds it to the user's history
+func (s *Service) RecordView(userID string, detail *models.NewRecipeDetail, lang string) error {
+	if err := s.store.SaveRecipe(detail, lang); err != nil {
+		return err
+	}
+	if userID == "" {
+		return nil
+	}
+	return s.store.RecordView(userID, detail.ID, maxHistory)
+}
+
+// AddFavourite saves a recipe detail and adds it to the user's favourites
+func (s *Service) AddFavourite(userID string, detail *models.NewRecipeDetail, note, lang string) (*models.FavouriteRecipe, error) {
+	if _, err := s.store.GetFavourite(userID, detail.ID); errors.Is(err, store.ErrNotFound) {
+		existing, err := s.store.ListFavourites(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) >= maxFavourites {
+			return nil, ErrFavouritesFull
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SaveRecipe(detail, lang); err != nil {
+		return nil, err
+	}
+	return s.store.SaveFavourite(userID, detail.ID, strings.TrimSpace(note))
+}
+
+// GetFavourite returns a favourite recipe of a user with its full detail
+func (s *Service) GetFavourite(userID, recipeID string) (*models.GetFavouriteResponse, error) {
+	favourite, err := s.store.GetFavourite(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrFavouriteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	detail, err := s.Get(recipeID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.GetFavouriteResponse{Favourite: *favourite, Recipe: *detail}, nil
+}
+
+// ListFavourites returns the favourites of a user, most recently saved first
+func (s *Service) ListFavourites(userID string) ([]models.FavouriteRecipe, error) {
+	return s.store.ListFavourites(userID)
+}
+
+// RemoveFavourite removes a recipe from the favourites of a user; the recipe itself stays
+// in the library
+func (s *Service) RemoveFavourite(userID, recipeID string) error {
+	err := s.store.DeleteFavourite(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrFavouriteNotFound
+	}
+	return err
+}
+
+// History returns the most recently viewed recipes of a user; limit <= 0 uses the default
+func (s *Service) History(userID string, limit int) ([]models.HistoryEntry, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistory {
+		limit = maxHistory
+	}
+	return s.store.ListHistory(userID, limit)
+}
+
+// ClearHistory deletes the viewing history of a user
+func (s *Service) ClearHistory(userID string) error {
+	return s.store.ClearHistory(userID)
+}
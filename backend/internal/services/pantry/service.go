@@ -0,0 +1,247 @@
+// Package pantry manages the per-user ingredient inventory and matches
+// recommended recipes against it
+package pantry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/google/uuid"
+)
+
+const (
+	dateLayout = "2006-01-02"
+	// urgentDays 距离过期不超过该天数的食材视为临期
+	urgentDays = 7
+	// maxPantryItems 每个用户最多保存的库存食材数
+	maxPantryItems = 200
+)
+
+var (
+	// ErrInvalidItem 库存食材参数无效
+	ErrInvalidItem = errors.New("invalid pantry item")
+	// ErrItemNotFound 库存食材不存在
+	ErrItemNotFound = errors.New("pantry item not found")
+	// ErrPantryFull 库存数量达到上限
+	ErrPantryFull = errors.New("pantry is full")
+)
+
+// Service provides pantry functionality
+type Service struct {
+	store *store.Store
+}
+
+// NewService creates a new pantry service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st}
+}
+
+// List returns the pantry items of a user, soonest expiry first
+func (s *Service) List(userID string) ([]models.PantryItem, error) {
+	items, err := s.store.ListPantryItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range items {
+		items[i].DaysLeft = daysLeft(items[i].ExpiresOn, now)
+	}
+	return items, nil
+}
+
+// Create adds an item to the pantry of a user
+func (s *Service) Create(userID string, req *models.CreatePantryItemRequest) (*models.PantryItem, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: 食材名称不能为空", ErrInvalidItem)
+	}
+	if err := validateDate(req.ExpiresOn); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.store.ListPantryItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxPantryItems {
+		return nil, ErrPantryFull
+	}
+
+	now := time.Now()
+	item := &models.PantryItem{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Quantity:  strings.TrimSpace(req.Quantity),
+		ExpiresOn: req.ExpiresOn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.SavePantryItem(userID, item); err != nil {
+		return nil, err
+	}
+	item.DaysLeft = daysLeft(item.ExpiresOn, now)
+	return item, nil
+}
+
+// Update changes the provided fields of a pantry item
+func (s *Service) Update(userID, id string, req *models.UpdatePantryItemRequest) (*models.PantryItem, error) {
+	item, err := s.store.GetPantryItem(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: 食材名称不能为空", ErrInvalidItem)
+		}
+		item.Name = name
+	}
+	if req.Quantity != nil {
+		item.Quantity = strings.TrimSpace(*req.Quantity)
+	}
+	if req.ExpiresOn != nil {
+		if err := validateDate(*req.ExpiresOn); err != nil {
+			return nil, err
+		}
+		item.ExpiresOn = *req.ExpiresOn
+	}
+
+	now := time.Now()
+	item.UpdatedAt = now
+	if err := s.store.SavePantryItem(userID, item); err != nil {
+		return nil, err
+	}
+	item.DaysLeft = daysLeft(item.ExpiresOn, now)
+	return item, nil
+}
+
+// Delete removes a pantry item
+func (s *Service) Delete(userID, id string) error {
+	err := s.store.DeletePantryItem(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrItemNotFound
+	}
+	return err
+}
+
+// Urgency rates how urgently an item should be used: higher for items expiring sooner.
+// Expired items are not worth cooking with and score 0; items without a date score 1.
+func Urgency(item models.PantryItem) float64 {
+	if item.DaysLeft == nil {
+		return 1
+	}
+	days := *item.DaysLeft
+	switch {
+	case days < 0:
+		return 0
+	case days <= urgentDays:
+		return float64(urgentDays-days) + 2
+	default:
+		return 1
+	}
+}
+
+// Usable returns the items that can still be cooked with, soonest expiry first
+func Usable(items []models.PantryItem) []models.PantryItem {
+	result := make([]models.PantryItem, 0, len(items))
+	for _, item := range items {
+		if item.DaysLeft != nil && *item.DaysLeft < 0 {
+			continue
+		}
+		result = append(result, item)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return Urgency(result[i]) > Urgency(result[j])
+	})
+	return result
+}
+
+// Names returns the names of pantry items
+func Names(items []models.PantryItem) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// Annotate fills the pantry and missing ingredients of a recommended recipe.
+// An ingredient is missing unless it was selected by the user, is in the pantry,
+// or is a basic seasoning. Returns the pantry urgency score of the recipe.
+func Annotate(r *models.RecipeWithMatch, selected []string, items []models.PantryItem) float64 {
+	r.PantryIngredients = nil
+	r.MissingIngredients = make([]string, 0)
+
+	score := 0.0
+	used := make(map[string]bool)
+	for _, ing := range r.Ingredients {
+		if catalog.IsStaple(ing) || containsSame(selected, ing) || containsSame(r.MatchedIngredients, ing) {
+			continue
+		}
+		if item, ok := findItem(items, ing); ok {
+			if !used[item.ID] {
+				used[item.ID] = true
+				r.PantryIngredients = append(r.PantryIngredients, item.Name)
+				score += Urgency(*item)
+			}
+			continue
+		}
+		r.MissingIngredients = append(r.MissingIngredients, ing)
+	}
+	r.MissingCount = len(r.MissingIngredients)
+	return score
+}
+
+// findItem returns the pantry item matching an ingredient name
+func findItem(items []models.PantryItem, name string) (*models.PantryItem, bool) {
+	for i := range items {
+		if catalog.SameIngredient(items[i].Name, name) {
+			return &items[i], true
+		}
+	}
+	return nil, false
+}
+
+func containsSame(list []string, name string) bool {
+	for _, x := range list {
+		if catalog.SameIngredient(x, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// daysLeft returns the number of days until the expiry date, or nil without a date
+func daysLeft(expiresOn string, now time.Time) *int {
+	if expiresOn == "" {
+		return nil
+	}
+	t, err := time.Parse(dateLayout, expiresOn)
+	if err != nil {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(t.Sub(today).Hours() / 24)
+	return &days
+}
+
+// validateDate checks an optional YYYY-MM-DD date
+func validateDate(date string) error {
+	if date == "" {
+		return nil
+	}
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return fmt.Errorf("%w: 过期日期格式应为 YYYY-MM-DD", ErrInvalidItem)
+	}
+	return nil
+}
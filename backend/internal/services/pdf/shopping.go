@@ -0,0 +1,149 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/signintech/gopdf"
+)
+
+// GenerateShoppingListPDF generates a printable PDF for a consolidated shopping list
+func (s *Service) GenerateShoppingListPDF(list *models.ShoppingList, lang string) (string, string, error) {
+	pdf := gopdf.GoPdf{}
+	pdf.Start(gopdf.Config{PageSize: *gopdf.PageSizeA4})
+
+	// A4 page dimensions: 595.28 x 841.89 points
+	pageWidth := 595.28
+	marginLeft := 50.0
+	marginRight := 50.0
+
+	fontPaths := []string{
+		"/Library/Fonts/Arial Unicode.ttf",
+		"/System/Library/Fonts/Supplemental/Arial Unicode.ttf",
+		"/System/Library/Fonts/STHeiti Light.ttc",
+		"/usr/share/fonts/truetype/noto/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+		"/usr/share/fonts/truetype/wqy/wqy-zenhei.ttc",
+	}
+
+	var fontLoaded bool
+	for _, fontPath := range fontPaths {
+		if err := pdf.AddTTFFont("default", fontPath); err == nil {
+			fontLoaded = true
+			break
+		}
+	}
+
+	if !fontLoaded {
+		return "", "", fmt.Errorf("无法加载中文字体，请确保系统已安装中文字体")
+	}
+
+	pdf.AddPage()
+	y := 40.0
+
+	// newLine moves down and starts a new page when needed
+	newLine := func(height float64) {
+		y += height
+		if y > 780 {
+			pdf.AddPage()
+			y = 40
+		}
+	}
+
+	// === Header ===
+	pdf.SetLineWidth(2)
+	pdf.SetStrokeColor(139, 154, 125) // #8B9A7D green
+	pdf.Line(marginLeft, y, pageWidth-marginRight, y)
+	y += 15
+
+	title := i18n.GetMessage(lang, "shopping_list_title")
+	if err := pdf.SetFont("default", "", 24); err != nil {
+		return "", "", err
+	}
+	pdf.SetTextColor(45, 45, 45)
+	pdf.SetX(marginLeft)
+	pdf.SetY(y)
+	pdf.Cell(nil, title)
+	y += 40
+
+	// === Recipes ===
+	if err := pdf.SetFont("default", "", 11); err != nil {
+		return "", "", err
+	}
+	pdf.SetTextColor(102, 102, 102)
+	servingsLabel := i18n.GetMessage(lang, "shopping_list_servings")
+	for _, r := range list.Recipes {
+		pdf.SetX(marginLeft)
+		pdf.SetY(y)
+		pdf.Cell(nil, fmt.Sprintf("· %s  (%s: %s)", r.Title, servingsLabel, r.Servings))
+		newLine(18)
+	}
+	newLine(10)
+
+	// === Sections ===
+	inSeason := i18n.GetMessage(lang, "shopping_list_in_season")
+	for _, section := range list.Sections {
+		if y > 720 {
+			pdf.AddPage()
+			y = 40
+		}
+		y = drawSectionHeader(&pdf, marginLeft, y, pageWidth-marginRight, section.Name)
+
+		pdf.SetFont("default", "", 12)
+		for _, item := range section.Items {
+			// Checkbox
+			pdf.SetLineWidth(0.8)
+			pdf.SetStrokeColor(150, 150, 150)
+			pdf.RectFromUpperLeftWithStyle(marginLeft, y+2, 10, 10, "D")
+
+			pdf.SetTextColor(45, 45, 45)
+			pdf.SetX(marginLeft + 20)
+			pdf.SetY(y)
+			pdf.Cell(nil, item.Name)
+
+			if item.InSeason {
+				pdf.SetTextColor(139, 154, 125)
+				pdf.SetX(marginLeft + 170)
+				pdf.SetY(y)
+				pdf.Cell(nil, inSeason)
+			}
+
+			lines := wrapText(item.Amount, 30)
+			pdf.SetTextColor(102, 102, 102)
+			for i, line := range lines {
+				if i > 0 {
+					newLine(18)
+				}
+				pdf.SetX(marginLeft + 250)
+				pdf.SetY(y)
+				pdf.Cell(nil, line)
+			}
+			newLine(22)
+		}
+		newLine(10)
+	}
+
+	if len(list.MissingRecipes) > 0 {
+		pdf.SetFont("default", "", 10)
+		pdf.SetTextColor(180, 80, 80)
+		pdf.SetX(marginLeft)
+		pdf.SetY(y)
+		pdf.Cell(nil, fmt.Sprintf("%s: %d", i18n.GetMessage(lang, "shopping_list_missing"), len(list.MissingRecipes)))
+		newLine(20)
+	}
+
+	// Write to buffer
+	var buf bytes.Buffer
+	if _, err := pdf.WriteTo(&buf); err != nil {
+		return "", "", fmt.Errorf("生成 PDF 失败: %w", err)
+	}
+
+	pdfBase64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+	fileName := fmt.Sprintf("%s_%s.pdf", title, time.Now().Format("2006-01-02"))
+
+	return pdfBase64, fileName, nil
+}
@@ -0,0 +1,42 @@
+package planner
+
+import "sync"
+
+// planLocks 按计划 ID 加锁，同一副本上对同一计划的修改依次进行；
+// 不再使用的锁会被移除，锁的数量不随计划数增长
+type planLocks struct {
+	mutex sync.Mutex
+	locks map[string]*planLock
+}
+
+type planLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newPlanLocks() *planLocks {
+	return &planLocks{locks: make(map[string]*planLock)}
+}
+
+// lock locks a plan and returns the function that unlocks it
+func (l *planLocks) lock(id string) func() {
+	l.mutex.Lock()
+	pl, ok := l.locks[id]
+	if !ok {
+		pl = &planLock{}
+		l.locks[id] = pl
+	}
+	pl.refs++
+	l.mutex.Unlock()
+
+	pl.Lock()
+	return func() {
+		pl.Unlock()
+		l.mutex.Lock()
+		pl.refs--
+		if pl.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mutex.Unlock()
+	}
+}
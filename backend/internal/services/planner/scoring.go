@@ -0,0 +1,144 @@
+package planner
+
+import (
+	"sort"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// Scoring weights
+const (
+	// seasonalWeight 每种应季食材的得分
+	seasonalWeight = 3.0
+	// reuseWeight 每种与近几天共用的食材的得分
+	reuseWeight = 2.0
+	// sameDayOverlapPenalty 与同一天其他餐共用主料时的扣分，避免一天吃两顿同样的东西
+	sameDayOverlapPenalty = 2.5
+	// reuseWindowDays 食材在多少天内再次使用算作「复用」
+	reuseWindowDays = 3
+)
+
+// candidate 候选菜谱及其预处理后的食材
+type candidate struct {
+	recipe models.RecipeWithMatch
+	// ingredients 食材键（目录 ID 或标准化名称）-> 原始名称
+	ingredients map[string]string
+	// staples 调味品等常备食材的键，不计入复用
+	staples map[string]bool
+	// seasonal 用到的应季食材（原始名称）
+	seasonal []string
+}
+
+// newCandidate pre-processes a recommended recipe for scoring
+func newCandidate(r models.RecipeWithMatch, seasonal []string) *candidate {
+	c := &candidate{
+		recipe:      r,
+		ingredients: make(map[string]string),
+		staples:     make(map[string]bool),
+	}
+
+	names := append(append([]string{}, r.Ingredients...), r.MatchedIngredients...)
+	for _, name := range names {
+		key, section := ingredientKey(name)
+		if key == "" {
+			continue
+		}
+		if _, ok := c.ingredients[key]; ok {
+			continue
+		}
+		c.ingredients[key] = name
+		if section == models.SectionPantry {
+			c.staples[key] = true
+		}
+		for _, s := range seasonal {
+			if catalog.SameIngredient(name, s) {
+				c.seasonal = append(c.seasonal, name)
+				break
+			}
+		}
+	}
+	return c
+}
+
+// ingredientKey returns the key used to compare ingredients across recipes
+func ingredientKey(name string) (string, models.StoreSection) {
+	if e, ok := catalog.Lookup(name); ok {
+		return e.ID, e.Section
+	}
+	normalized := catalog.Normalize(name)
+	if normalized == "" {
+		return "", ""
+	}
+	return "name:" + normalized, models.SectionOther
+}
+
+// dishKey returns the key used to detect repeated dishes
+func dishKey(title string) string {
+	return catalog.Normalize(title)
+}
+
+// usage 已安排的食材使用情况：食材键 -> 最近一次使用的日序号
+type usage map[string]int
+
+// record marks the ingredients of a candidate as used on the given day
+func (u usage) record(c *candidate, day int) {
+	for key := range c.ingredients {
+		if c.staples[key] {
+			continue
+		}
+		u[key] = day
+	}
+}
+
+// reused returns the ingredients of a candidate used on one of the previous reuseWindowDays days
+func (u usage) reused(c *candidate, day int) []string {
+	var result []string
+	for key, name := range c.ingredients {
+		if c.staples[key] {
+			continue
+		}
+		if last, ok := u[key]; ok && last < day && day-last <= reuseWindowDays {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// score rates a candidate for a slot on the given day
+func score(c *candidate, day int, used usage, sameDay []*candidate) float64 {
+	s := seasonalWeight * float64(len(c.seasonal))
+	s += reuseWeight * float64(len(used.reused(c, day)))
+
+	for _, other := range sameDay {
+		shared := 0
+		for key := range c.ingredients {
+			if c.staples[key] {
+				continue
+			}
+			if _, ok := other.ingredients[key]; ok {
+				shared++
+			}
+		}
+		if shared >= 2 {
+			s -= sameDayOverlapPenalty * float64(shared)
+		}
+	}
+	return s
+}
+
+// pickBest returns the index of the best unused candidate, or -1 if none is left
+func pickBest(pool []*candidate, usedDishes map[string]bool, day int, used usage, sameDay []*candidate) int {
+	best, bestScore := -1, 0.0
+	for i, c := range pool {
+		if usedDishes[dishKey(c.recipe.Title)] {
+			continue
+		}
+		sc := score(c, day, used, sameDay)
+		if best == -1 || sc > bestScore {
+			best, bestScore = i, sc
+		}
+	}
+	return best
+}
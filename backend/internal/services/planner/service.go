@@ -0,0 +1,511 @@
+// Package planner builds multi-day seasonal meal plans on top of the
+// ingredient and recipe services
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/ingredient"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/shopping"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/google/uuid"
+)
+
+// Planner limits
+const (
+	defaultPlanDays    = 7
+	maxPlanDays        = 14
+	defaultMealsPerDay = 2
+	// ingredientsPerBatch 每次推荐请求携带的应季食材数量
+	ingredientsPerBatch = 4
+	// recipesPerCall 每次推荐请求大约返回的菜谱数
+	recipesPerCall = 5
+	// maxRecommendationCalls 生成一个计划最多发起的推荐请求数
+	maxRecommendationCalls = 8
+	// llmConcurrency 并发调用 LLM 的数量
+	llmConcurrency = 3
+	// dateLayout 日期格式
+	dateLayout = "2006-01-02"
+)
+
+var (
+	// ErrInvalidRequest 请求参数无效
+	ErrInvalidRequest = errors.New("invalid meal plan request")
+	// ErrPlanNotFound 计划不存在
+	ErrPlanNotFound = errors.New("meal plan not found")
+	// ErrMealNotFound 计划中不存在该餐
+	ErrMealNotFound = errors.New("meal not found in plan")
+	// ErrNoCandidates 没有可用的候选菜谱
+	ErrNoCandidates = errors.New("no candidate recipes available")
+	// ErrServiceUnavailable 菜谱服务不可用
+	ErrServiceUnavailable = errors.New("recipe service unavailable")
+	// ErrPlanConflict 替换期间计划被其他请求修改
+	ErrPlanConflict = errors.New("meal plan was modified concurrently")
+)
+
+// Service provides meal planning functionality
+type Service struct {
+	ingredients *ingredient.Service
+	recipes     *recipe.Service
+	shopping    *shopping.Service
+	store       *store.Store
+	cache       *cache.Cache
+	locks       *planLocks
+}
+
+// NewService creates a new meal planner service.
+// ingredients and recipes may be nil when no LLM is configured; stored plans remain readable.
+func NewService(ingredients *ingredient.Service, recipes *recipe.Service, st *store.Store, c *cache.Cache) *Service {
+	return &Service{
+		ingredients: ingredients,
+		recipes:     recipes,
+		shopping:    shopping.NewService(),
+		store:       st,
+		cache:       c,
+		locks:       newPlanLocks(),
+	}
+}
+
+// Generate creates and persists a meal plan of a user (empty when anonymous) for the
+// requested date range
+func (s *Service) Generate(ctx context.Context, userID string, req *models.CreateMealPlanRequest, lang string) (*models.MealPlan, error) {
+	start, end, err := parseRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	if s.recipes == nil {
+		return nil, ErrServiceUnavailable
+	}
+
+	mealsPerDay := req.MealsPerDay
+	if mealsPerDay == 0 {
+		mealsPerDay = defaultMealsPerDay
+	}
+
+	seasonalResp, err := ingredient.LoadSeasonalIngredients(ctx, s.ingredients, req.City, lang)
+	if err != nil {
+		return nil, fmt.Errorf("获取应季食材失败: %w", err)
+	}
+
+	now := time.Now()
+	plan := &models.MealPlan{
+		ID:                  uuid.New().String(),
+		City:                req.City,
+		StartDate:           start.Format(dateLayout),
+		EndDate:             end.Format(dateLayout),
+		MealsPerDay:         mealsPerDay,
+		Servings:            req.Servings,
+		Preference:          req.Preference,
+		Allergens:           allergen.Normalize(req.Allergens),
+		SeasonalIngredients: ingredient.Names(seasonalResp),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	slots := slotsFor(mealsPerDay)
+	days := int(end.Sub(start).Hours()/24) + 1
+	need := days * len(slots)
+
+	recipes, err := s.fetchCandidates(ctx, plan, need*2, nil, lang)
+	if err != nil {
+		return nil, err
+	}
+	pool := make([]*candidate, 0, len(recipes))
+	for _, r := range recipes {
+		pool = append(pool, newCandidate(r, plan.SeasonalIngredients))
+	}
+
+	// 按天贪心选择：应季食材越多、与前几天共用食材越多得分越高，同一道菜不重复
+	used := make(usage)
+	usedDishes := make(map[string]bool)
+	for d := 0; d < days; d++ {
+		day := models.MealPlanDay{Date: start.AddDate(0, 0, d).Format(dateLayout)}
+		var sameDay []*candidate
+		for _, slot := range slots {
+			best := pickBest(pool, usedDishes, d, used, sameDay)
+			if best == -1 {
+				break
+			}
+			c := pool[best]
+			pool = append(pool[:best], pool[best+1:]...)
+			usedDishes[dishKey(c.recipe.Title)] = true
+			sameDay = append(sameDay, c)
+			day.Meals = append(day.Meals, models.PlannedMeal{Slot: slot, Recipe: c.recipe})
+		}
+		for _, c := range sameDay {
+			used.record(c, d)
+		}
+		plan.Days = append(plan.Days, day)
+	}
+
+	annotate(plan)
+	if plan.Stats.Meals < need {
+		log.Printf("[PlannerService] 候选菜谱不足，计划 %s 只安排了 %d/%d 餐", plan.ID, plan.Stats.Meals, need)
+	}
+
+	if err := s.store.CreateMealPlan(userID, plan, remaining(pool)); err != nil {
+		return nil, fmt.Errorf("保存膳食计划失败: %w", err)
+	}
+	return plan, nil
+}
+
+// Get returns a stored meal plan of a user; plans of other users are not found
+func (s *Service) Get(userID, id string) (*models.MealPlan, error) {
+	plan, _, _, err := s.store.GetMealPlan(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrPlanNotFound
+	}
+	return plan, err
+}
+
+// Delete deletes a stored meal plan of a user
+func (s *Service) Delete(userID, id string) error {
+	err := s.store.DeleteMealPlan(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrPlanNotFound
+	}
+	return err
+}
+
+// SwapMeal replaces a single meal of a user's plan with the best remaining candidate,
+// fetching new candidates when the stored pool is exhausted. Swaps of the same plan run
+// one at a time; a plan modified by another replica in the meantime is not overwritten.
+func (s *Service) SwapMeal(ctx context.Context, userID, id, date string, slot models.MealSlot, lang string) (*models.MealPlan, error) {
+	unlock := s.locks.lock(id)
+	defer unlock()
+
+	plan, recipes, version, err := s.store.GetMealPlan(userID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dayIndex, mealIndex := -1, -1
+	for d, day := range plan.Days {
+		if day.Date != date {
+			continue
+		}
+		for m, meal := range day.Meals {
+			if meal.Slot == slot {
+				dayIndex, mealIndex = d, m
+			}
+		}
+	}
+	if mealIndex == -1 {
+		return nil, ErrMealNotFound
+	}
+
+	// 重建除被替换餐之外的食材使用情况
+	used := make(usage)
+	usedDishes := make(map[string]bool)
+	var sameDay []*candidate
+	for d, day := range plan.Days {
+		for m, meal := range day.Meals {
+			usedDishes[dishKey(meal.Recipe.Title)] = true
+			if d == dayIndex && m == mealIndex {
+				continue
+			}
+			c := newCandidate(meal.Recipe, plan.SeasonalIngredients)
+			if d < dayIndex {
+				used.record(c, d)
+			}
+			if d == dayIndex {
+				sameDay = append(sameDay, c)
+			}
+		}
+	}
+
+	pool := make([]*candidate, 0, len(recipes))
+	for _, r := range recipes {
+		pool = append(pool, newCandidate(r, plan.SeasonalIngredients))
+	}
+
+	best := pickBest(pool, usedDishes, dayIndex, used, sameDay)
+	if best == -1 {
+		if s.recipes == nil {
+			return nil, ErrNoCandidates
+		}
+		exclude := make([]string, 0, len(usedDishes))
+		for _, day := range plan.Days {
+			for _, meal := range day.Meals {
+				exclude = append(exclude, meal.Recipe.Title)
+			}
+		}
+		fresh, err := s.fetchCandidates(ctx, plan, recipesPerCall, exclude, lang)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range fresh {
+			pool = append(pool, newCandidate(r, plan.SeasonalIngredients))
+		}
+		best = pickBest(pool, usedDishes, dayIndex, used, sameDay)
+		if best == -1 {
+			return nil, ErrNoCandidates
+		}
+	}
+
+	c := pool[best]
+	pool = append(pool[:best], pool[best+1:]...)
+	plan.Days[dayIndex].Meals[mealIndex] = models.PlannedMeal{Slot: slot, Recipe: c.recipe}
+	plan.UpdatedAt = time.Now()
+	annotate(plan)
+
+	err = s.store.UpdateMealPlan(userID, plan, remaining(pool), version)
+	if errors.Is(err, store.ErrVersionConflict) {
+		return nil, ErrPlanConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("保存膳食计划失败: %w", err)
+	}
+	return plan, nil
+}
+
+// ShoppingList builds the combined shopping list of every meal in a user's plan.
+// Recipe details are taken from the cache or generated when missing.
+func (s *Service) ShoppingList(ctx context.Context, userID, id, lang string) (*models.ShoppingList, error) {
+	plan, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var meals []models.RecipeWithMatch
+	seen := make(map[string]bool)
+	for _, day := range plan.Days {
+		for _, meal := range day.Meals {
+			if seen[meal.Recipe.ID] {
+				continue
+			}
+			seen[meal.Recipe.ID] = true
+			meals = append(meals, meal.Recipe)
+		}
+	}
+
+	details := make([]*models.NewRecipeDetail, len(meals))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, llmConcurrency)
+	for i, r := range meals {
+		wg.Add(1)
+		go func(i int, r models.RecipeWithMatch) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			details[i] = s.loadDetail(ctx, r, plan.Allergens, lang)
+		}(i, r)
+	}
+	wg.Wait()
+
+	inputs := make([]shopping.RecipeInput, 0, len(meals))
+	var missing []string
+	for i, detail := range details {
+		if detail == nil {
+			missing = append(missing, meals[i].Title)
+			continue
+		}
+		inputs = append(inputs, shopping.RecipeInput{Detail: detail, Servings: plan.Servings})
+	}
+
+	list := s.shopping.Build(inputs, plan.SeasonalIngredients, lang)
+	list.MissingRecipes = missing
+	return list, nil
+}
+
+// loadDetail returns the detail of a planned recipe, or nil if it cannot be obtained
+func (s *Service) loadDetail(ctx context.Context, r models.RecipeWithMatch, allergens []models.Allergen, lang string) *models.NewRecipeDetail {
+	if len(allergens) == 0 {
+		if detail, ok := recipe.FindCachedDetail(s.cache, r.ID, lang); ok {
+			return detail
+		}
+	}
+	if s.recipes == nil {
+		return nil
+	}
+	detail, err := s.recipes.LoadRecipeDetail(ctx, s.cache, r.ID, r.Title, recipe.DetailOptions{Allergens: allergens}, lang)
+	if err != nil {
+		log.Printf("[PlannerService] 获取菜谱详情失败: %s %v", r.Title, err)
+		return nil
+	}
+	return detail
+}
+
+// fetchCandidates asks the recipe service for recommendations, rotating through the
+// seasonal ingredients so the pool covers as many of them as possible
+func (s *Service) fetchCandidates(ctx context.Context, plan *models.MealPlan, want int, exclude []string, lang string) ([]models.RecipeWithMatch, error) {
+	calls := (want + recipesPerCall - 1) / recipesPerCall
+	if calls < 1 {
+		calls = 1
+	}
+	if calls > maxRecommendationCalls {
+		calls = maxRecommendationCalls
+	}
+
+	allergens := make([]string, 0, len(plan.Allergens))
+	for _, a := range plan.Allergens {
+		allergens = append(allergens, string(a))
+	}
+
+	// 每次请求偏移一批食材，避免重复推荐同样的菜
+	offset := len(exclude)
+	results := make([][]models.RecipeWithMatch, calls)
+	errs := make([]error, calls)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, llmConcurrency)
+	for i := 0; i < calls; i++ {
+		req := &models.GetRecipesByIngredientsRequest{
+			Ingredients: batch(plan.SeasonalIngredients, offset+i*ingredientsPerBatch, ingredientsPerBatch),
+			Preference:  plan.Preference,
+			Location:    plan.City,
+			Allergens:   allergens,
+		}
+		wg.Add(1)
+		go func(i int, req *models.GetRecipesByIngredientsRequest) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, err := s.recipes.GetRecipeRecommendations(ctx, req, recipe.RecommendOptions{}, lang)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = resp.Recipes
+		}(i, req)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, title := range exclude {
+		seen[dishKey(title)] = true
+	}
+	var recipes []models.RecipeWithMatch
+	for _, batch := range results {
+		for _, r := range batch {
+			key := dishKey(r.Title)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			recipes = append(recipes, r)
+		}
+	}
+
+	if len(recipes) == 0 {
+		for _, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("获取候选菜谱失败: %w", err)
+			}
+		}
+		return nil, ErrNoCandidates
+	}
+	return recipes, nil
+}
+
+// annotate recomputes the seasonal and reused ingredients of every meal and the plan stats
+func annotate(plan *models.MealPlan) {
+	used := make(usage)
+	distinct := make(map[string]bool)
+	stats := models.MealPlanStats{}
+
+	for d := range plan.Days {
+		var today []*candidate
+		for m := range plan.Days[d].Meals {
+			meal := &plan.Days[d].Meals[m]
+			c := newCandidate(meal.Recipe, plan.SeasonalIngredients)
+			meal.SeasonalIngredients = append([]string{}, c.seasonal...)
+			meal.ReusedIngredients = used.reused(c, d)
+			today = append(today, c)
+
+			stats.Meals++
+			stats.SeasonalIngredientUses += len(c.seasonal)
+			stats.ReusedIngredients += len(meal.ReusedIngredients)
+			for key := range c.ingredients {
+				if !c.staples[key] {
+					distinct[key] = true
+				}
+			}
+		}
+		for _, c := range today {
+			used.record(c, d)
+		}
+	}
+
+	stats.DistinctIngredients = len(distinct)
+	plan.Stats = stats
+}
+
+// parseRange parses and validates the plan date range
+func parseRange(startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if startDate != "" {
+		t, err := time.Parse(dateLayout, startDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: 起始日期格式应为 YYYY-MM-DD", ErrInvalidRequest)
+		}
+		start = t
+	}
+
+	end := start.AddDate(0, 0, defaultPlanDays-1)
+	if endDate != "" {
+		t, err := time.Parse(dateLayout, endDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: 结束日期格式应为 YYYY-MM-DD", ErrInvalidRequest)
+		}
+		end = t
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: 结束日期不能早于起始日期", ErrInvalidRequest)
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > maxPlanDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: 计划最长 %d 天", ErrInvalidRequest, maxPlanDays)
+	}
+	return start, end, nil
+}
+
+// slotsFor returns the meal slots for the given number of meals per day
+func slotsFor(mealsPerDay int) []models.MealSlot {
+	switch mealsPerDay {
+	case 1:
+		return []models.MealSlot{models.MealDinner}
+	case 3:
+		return []models.MealSlot{models.MealBreakfast, models.MealLunch, models.MealDinner}
+	default:
+		return []models.MealSlot{models.MealLunch, models.MealDinner}
+	}
+}
+
+// batch returns size items of list starting at offset, wrapping around
+func batch(list []string, offset, size int) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	if size > len(list) {
+		size = len(list)
+	}
+	result := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		result = append(result, list[(offset+i)%len(list)])
+	}
+	return result
+}
+
+// remaining returns the recipes of the unused candidates
+func remaining(pool []*candidate) []models.RecipeWithMatch {
+	result := make([]models.RecipeWithMatch, 0, len(pool))
+	for _, c := range pool {
+		result = append(result, c.recipe)
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+// ErrServiceUnavailable 缓存未命中且菜谱服务未初始化
+var ErrServiceUnavailable = errors.New("recipe service unavailable")
+
+// DetailCacheKey returns the two-tier cache key of a recipe detail.
+// Details generated under allergen constraints or in a given cuisine are cached separately.
+func DetailCacheKey(recipeID string, allergens []models.Allergen, cuisine models.Cuisine, lang string) string {
+	codes := make([]string, 0, len(allergens))
+	for _, a := range allergens {
+		codes = append(codes, string(a))
+	}
+	return cache.RecipeDetailKey(recipeID, lang, string(cuisine), codes)
+}
+
+// FindCachedDetail looks up a generated recipe detail by ID in the typed cache,
+// then in the two-tier cache under the language-specific key, and finally in the
+// persistent recipe library, which keeps details after the caches expire
+func FindCachedDetail(c *cache.Cache, recipeID, lang string) (*models.NewRecipeDetail, bool) {
+	if c != nil {
+		if detail, ok := c.GetNewRecipeDetail(recipeID); ok && detail != nil {
+			return detail, true
+		}
+	}
+	if detail, ok := cache.Load[*models.NewRecipeDetail](cache.DefaultManager, DetailCacheKey(recipeID, nil, "", lang)); ok && detail != nil {
+		return detail, true
+	}
+	if store.Default != nil {
+		detail, _, err := store.Default.GetRecipe(recipeID)
+		if err == nil {
+			return detail, true
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[RecipeService] 读取菜谱库失败: %v", err)
+		}
+	}
+	return nil, false
+}
+
+// FindUserDetail looks up the version of a recipe detail a user sees: their edited
+// version (or personal copy of a shared recipe) when there is one, otherwise the
+// generated detail as found by FindCachedDetail
+func FindUserDetail(c *cache.Cache, userID, recipeID, lang string) (*models.NewRecipeDetail, bool) {
+	if userID != "" && store.Default != nil {
+		_, detail, err := store.Default.GetUserRecipe(userID, recipeID)
+		if err == nil {
+			return detail, true
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			log.Printf("[RecipeService] 读取用户编辑的菜谱失败: %v", err)
+		}
+	}
+	return FindCachedDetail(c, recipeID, lang)
+}
+
+// LoadRecipeDetail returns a recipe detail from the cache, generating and caching it on a miss.
+// Concurrent loads of the same detail (e.g. a user request during a background prefetch)
+// share one generation; the result is cached even if the caller gives up waiting.
+func (s *Service) LoadRecipeDetail(ctx context.Context, c *cache.Cache, recipeID, recipeTitle string, opts DetailOptions, lang string) (*models.NewRecipeDetail, error) {
+	cacheKey := DetailCacheKey(recipeID, opts.Allergens, opts.Cuisine, lang)
+	if cached, ok := cache.Load[*models.NewRecipeDetail](cache.DefaultManager, cacheKey); ok && cached != nil {
+		return cached, nil
+	}
+
+	detail, shared, err := s.flights.do(ctx, cacheKey, func(ctx context.Context) (*models.NewRecipeDetail, error) {
+		detail, err := s.GetRecipeDetail(ctx, recipeID, recipeTitle, opts, lang)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cache.Store(cache.DefaultManager, cacheKey, detail); err != nil {
+			log.Printf("[RecipeService] 菜谱详情缓存写入失败: %v", err)
+		}
+		if c != nil {
+			c.SetNewRecipeDetail(recipeID, detail)
+		}
+		// 生成的详情（包括后台预取的）保存到菜谱库，缓存过期后仍可查看和搜索；
+		// 内容未变时不更新 updated_at，不会触发重新索引和向量化
+		if store.Default != nil {
+			if err := store.Default.SaveRecipe(detail, lang); err != nil {
+				log.Printf("[RecipeService] 菜谱详情保存到菜谱库失败: %v", err)
+			}
+		}
+		return detail, nil
+	})
+	if shared {
+		log.Printf("[RecipeService] 复用进行中的菜谱详情生成: %s", cacheKey)
+	}
+	return detail, err
+}
@@ -0,0 +1,94 @@
+package recipe
+
+import (
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// Contexts returns the context every recommended recipe was generated in, to be
+// persisted and reused when its detail is generated. The response must be ranked
+// so that the seasonal ingredients are set.
+func Contexts(resp *models.GetRecipesByIngredientsResponse, req *models.GetRecipesByIngredientsRequest, lang string) []models.RecommendationContext {
+	now := time.Now()
+	declared := allergen.Normalize(req.Allergens)
+	contexts := make([]models.RecommendationContext, 0, len(resp.Recipes))
+	for _, r := range resp.Recipes {
+		// 模型判断的菜系优先，无法判断时沿用请求的菜系
+		recipeCuisine := r.Cuisine
+		if (recipeCuisine == "" || recipeCuisine == models.CuisineOther) && resp.Cuisine != "" {
+			recipeCuisine = resp.Cuisine
+		}
+		contexts = append(contexts, models.RecommendationContext{
+			RecipeID:            r.ID,
+			Title:               r.Title,
+			Description:         r.Description,
+			Ingredients:         r.Ingredients,
+			SelectedIngredients: req.Ingredients,
+			MatchedIngredients:  r.MatchedIngredients,
+			SeasonalIngredients: r.SeasonalIngredients,
+			Location:            req.Location,
+			Preference:          req.Preference,
+			Allergens:           declared,
+			Cuisine:             recipeCuisine,
+			Leftovers:           req.Leftovers,
+			MaxCookingMinutes:   req.MaxCookingMinutes,
+			Lang:                lang,
+			CreatedAt:           now,
+		})
+	}
+	return contexts
+}
+
+// ContextOptions returns the detail options of a recommended recipe: the allergens
+// and cuisine it was recommended under, and the context itself
+func ContextOptions(rc *models.RecommendationContext) DetailOptions {
+	if rc == nil {
+		return DetailOptions{}
+	}
+	opts := DetailOptions{Allergens: rc.Allergens, Context: rc}
+	if rc.Cuisine != models.CuisineOther {
+		opts.Cuisine = rc.Cuisine
+	}
+	return opts
+}
+
+// RequiredIngredients returns the ingredients the detail must use: the selected and
+// in-season ingredients that justified the recommendation (staples excluded)
+func RequiredIngredients(rc *models.RecommendationContext) []string {
+	if rc == nil {
+		return nil
+	}
+	var required []string
+	for _, group := range [][]string{rc.MatchedIngredients, rc.SeasonalIngredients} {
+		for _, name := range group {
+			if catalog.IsStaple(name) || containsIngredient(required, name) {
+				continue
+			}
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+// MissingIngredients returns the required ingredients the detail does not list
+func MissingIngredients(detail *models.NewRecipeDetail, required []string) []string {
+	var missing []string
+	for _, name := range required {
+		if findRecipeIngredient(detail, name) == nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func containsIngredient(names []string, name string) bool {
+	for _, n := range names {
+		if catalog.SameIngredient(n, name) {
+			return true
+		}
+	}
+	return false
+}
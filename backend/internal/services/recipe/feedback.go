@@ -0,0 +1,156 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/services/cuisine"
+)
+
+const (
+	// cuisinePreference 喜欢（或不喜欢）的菜系对契合度的影响
+	cuisinePreference = 0.5
+	// ingredientPreference 每个喜欢（或不喜欢）的食材对契合度的影响
+	ingredientPreference = 0.25
+	// preferenceWeight 默认排序时契合度相对应季评分的权重
+	preferenceWeight = 0.3
+	// maxPromptRejectedTitles 提示词中最多列出的不再推荐的菜（最近拒绝的），
+	// 更早拒绝的菜仍由 ApplyFeedback 过滤
+	maxPromptRejectedTitles = 30
+)
+
+// ApplyFeedback personalizes recommendations with the taste profile of a user: dishes
+// the user rejected are dropped and the others are scored by how well they match the
+// liked and disliked cuisines and ingredients. A nil profile leaves resp unchanged.
+func ApplyFeedback(resp *models.GetRecipesByIngredientsResponse, profile *models.FeedbackProfile) {
+	if profile == nil {
+		return
+	}
+
+	rejected := make(map[string]bool, len(profile.RejectedTitles))
+	for _, title := range profile.RejectedTitles {
+		rejected[titleKey(title)] = true
+	}
+
+	recipes := make([]models.RecipeWithMatch, 0, len(resp.Recipes))
+	for _, r := range resp.Recipes {
+		if rejected[titleKey(r.Title)] {
+			continue
+		}
+		r.PreferenceScore = preferenceScore(&r, profile)
+		recipes = append(recipes, r)
+	}
+	resp.Recipes = recipes
+	resp.Personalized = true
+}
+
+// preferenceScore 菜谱与口味偏好的契合度，范围 -1 到 1
+func preferenceScore(r *models.RecipeWithMatch, profile *models.FeedbackProfile) float64 {
+	score := 0.0
+	for _, c := range profile.LikedCuisines {
+		if r.Cuisine == c {
+			score += cuisinePreference
+		}
+	}
+	for _, c := range profile.DislikedCuisines {
+		if r.Cuisine == c {
+			score -= cuisinePreference
+		}
+	}
+
+	ingredients := append(append([]string(nil), r.Ingredients...), r.MatchedIngredients...)
+	for _, name := range profile.LikedIngredients {
+		if containsIngredient(ingredients, name) {
+			score += ingredientPreference
+		}
+	}
+	for _, name := range profile.DislikedIngredients {
+		if containsIngredient(ingredients, name) {
+			score -= ingredientPreference
+		}
+	}
+	return max(-1, min(1, score))
+}
+
+// titleKey 菜名比较时忽略大小写、空白和括号中的注释
+func titleKey(title string) string {
+	return strings.ReplaceAll(catalog.Normalize(title), " ", "")
+}
+
+// writeFeedbackContext writes the taste profile derived from the user's past feedback
+// into the context section of the recommendation prompt
+func writeFeedbackContext(sb *strings.Builder, profile *models.FeedbackProfile, lang string) {
+	if profile == nil {
+		return
+	}
+
+	sep := "、"
+	if lang == "en" {
+		sep = ", "
+	}
+	cuisineNames := func(codes []models.Cuisine) string {
+		names := make([]string, 0, len(codes))
+		for _, code := range codes {
+			names = append(names, cuisine.Name(code, lang))
+		}
+		return strings.Join(names, sep)
+	}
+
+	var likes, dislikes []string
+	if lang == "en" {
+		if len(profile.LikedCuisines) > 0 {
+			likes = append(likes, "cuisines: "+cuisineNames(profile.LikedCuisines))
+		}
+		if len(profile.LikedIngredients) > 0 {
+			likes = append(likes, "ingredients: "+strings.Join(profile.LikedIngredients, sep))
+		}
+		if len(profile.DislikedCuisines) > 0 {
+			dislikes = append(dislikes, "cuisines: "+cuisineNames(profile.DislikedCuisines))
+		}
+		if len(profile.DislikedIngredients) > 0 {
+			dislikes = append(dislikes, "ingredients: "+strings.Join(profile.DislikedIngredients, sep))
+		}
+		if len(likes) > 0 {
+			sb.WriteString(fmt.Sprintf("- From the user's past ratings they enjoy these %s. Favour them where they fit the selection\n", strings.Join(likes, "; ")))
+		}
+		if len(dislikes) > 0 {
+			sb.WriteString(fmt.Sprintf("- From the user's past ratings they dislike these %s. Avoid them unless the user selected them\n", strings.Join(dislikes, "; ")))
+		}
+		if rejected := promptRejectedTitles(profile); len(rejected) > 0 {
+			sb.WriteString(fmt.Sprintf("- Never recommend these dishes the user rejected: %s\n", strings.Join(rejected, sep)))
+		}
+		return
+	}
+
+	if len(profile.LikedCuisines) > 0 {
+		likes = append(likes, "菜系："+cuisineNames(profile.LikedCuisines))
+	}
+	if len(profile.LikedIngredients) > 0 {
+		likes = append(likes, "食材："+strings.Join(profile.LikedIngredients, sep))
+	}
+	if len(profile.DislikedCuisines) > 0 {
+		dislikes = append(dislikes, "菜系："+cuisineNames(profile.DislikedCuisines))
+	}
+	if len(profile.DislikedIngredients) > 0 {
+		dislikes = append(dislikes, "食材："+strings.Join(profile.DislikedIngredients, sep))
+	}
+	if len(likes) > 0 {
+		sb.WriteString(fmt.Sprintf("- 根据用户以往的评价，用户喜欢以下%s。在符合所选食材的前提下优先推荐\n", strings.Join(likes, "；")))
+	}
+	if len(dislikes) > 0 {
+		sb.WriteString(fmt.Sprintf("- 根据用户以往的评价，用户不喜欢以下%s。除非用户选择了这些食材，否则请避免\n", strings.Join(dislikes, "；")))
+	}
+	if rejected := promptRejectedTitles(profile); len(rejected) > 0 {
+		sb.WriteString(fmt.Sprintf("- 以下菜用户明确不想再看到，不要推荐：%s\n", strings.Join(rejected, sep)))
+	}
+}
+
+// promptRejectedTitles 提示词中列出的最近拒绝的菜
+func promptRejectedTitles(profile *models.FeedbackProfile) []string {
+	if len(profile.RejectedTitles) > maxPromptRejectedTitles {
+		return profile.RejectedTitles[:maxPromptRejectedTitles]
+	}
+	return profile.RejectedTitles
+}
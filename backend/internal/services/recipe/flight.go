@@ -0,0 +1,100 @@
+package recipe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// detailFlight 正在进行的一次菜谱详情生成，相同缓存键的请求共享结果
+type detailFlight struct {
+	done     chan struct{}
+	detail   *models.NewRecipeDetail
+	err      error
+	waiters  int
+	cancel   context.CancelFunc
+	priority *atomic.Int32
+}
+
+// flightGroup 合并相同菜谱详情的并发生成：用户请求会附加到进行中的预取上并提升其优先级，
+// 所有等待者都离开后生成才被取消
+type flightGroup struct {
+	mutex   sync.Mutex
+	flights map[string]*detailFlight
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{flights: make(map[string]*detailFlight)}
+}
+
+// do runs fn once per key and waits for its result. fn runs in a context detached from
+// the caller's cancellation, which is cancelled once every waiter has given up.
+// shared reports whether the caller attached to a generation started by another caller.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*models.NewRecipeDetail, error)) (detail *models.NewRecipeDetail, shared bool, err error) {
+	level := priorityOf(ctx)
+
+	g.mutex.Lock()
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		if p := level.Load(); p > f.priority.Load() {
+			f.priority.Store(p)
+		}
+	} else {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		priority := new(atomic.Int32)
+		priority.Store(level.Load())
+		runCtx = context.WithValue(runCtx, priorityKey{}, priority)
+
+		f = &detailFlight{done: make(chan struct{}), waiters: 1, cancel: cancel, priority: priority}
+		g.flights[key] = f
+		go g.run(key, f, runCtx, fn)
+	}
+	g.mutex.Unlock()
+
+	select {
+	case <-f.done:
+		return f.detail, ok, f.err
+	case <-ctx.Done():
+		g.leave(key, f)
+		return nil, ok, ctx.Err()
+	}
+}
+
+func (g *flightGroup) run(key string, f *detailFlight, ctx context.Context, fn func(ctx context.Context) (*models.NewRecipeDetail, error)) {
+	f.detail, f.err = fn(ctx)
+	f.cancel()
+
+	g.mutex.Lock()
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+	g.mutex.Unlock()
+	close(f.done)
+}
+
+// leave removes a waiter; the last one to leave cancels the generation
+func (g *flightGroup) leave(key string, f *detailFlight) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	f.waiters--
+	if f.waiters > 0 {
+		return
+	}
+	f.cancel()
+	// 新的请求不再附加到已取消的生成上
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+// inFlight reports whether a detail is being generated under the key
+func (g *flightGroup) inFlight(key string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	_, ok := g.flights[key]
+	return ok
+}
@@ -0,0 +1,230 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// prefetchQueueSize 等待预取的菜谱数上限，队列满时丢弃新的预取
+const prefetchQueueSize = 64
+
+// PrefetchJob 预取一道推荐菜谱的详情
+type PrefetchJob struct {
+	RecipeID string
+	Title    string
+	Options  DetailOptions
+	Lang     string
+}
+
+// pendingPrefetch 排队中或进行中的预取
+type pendingPrefetch struct {
+	owner  string             // 发起推荐的用户，匿名时为空
+	cancel context.CancelFunc // 排队中的任务为 nil
+}
+
+// Prefetcher generates and caches the details of the top recommended recipes in the
+// background, so that opening one of them does not wait for the LLM. Prefetch calls are
+// queued with low priority; a user request for the same detail attaches to the
+// in-flight generation instead of starting another one.
+type Prefetcher struct {
+	service *Service
+	cache   *cache.Cache
+	topN    int
+	workers int
+	jobs    chan PrefetchJob
+
+	mutex   sync.Mutex
+	pending map[string]*pendingPrefetch // 缓存键 -> 排队中或进行中的预取
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewPrefetcher creates a prefetcher for the top N recipes of each recommendation
+func NewPrefetcher(service *Service, c *cache.Cache, topN, workers int) *Prefetcher {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Prefetcher{
+		service: service,
+		cache:   c,
+		topN:    topN,
+		workers: workers,
+		jobs:    make(chan PrefetchJob, prefetchQueueSize),
+		pending: make(map[string]*pendingPrefetch),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start starts the worker pool
+func (p *Prefetcher) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	log.Printf("[Prefetcher] 已启动: 预取前 %d 道菜谱, 并发 %d", p.topN, p.workers)
+}
+
+// Stop cancels all prefetches and waits for the workers to exit
+func (p *Prefetcher) Stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+}
+
+// TopN returns the number of recipes prefetched per recommendation
+func (p *Prefetcher) TopN() int {
+	if p == nil {
+		return 0
+	}
+	return p.topN
+}
+
+// Enqueue schedules prefetches for the recommendation of an owner (the user, empty when
+// anonymous); details that are cached, queued or being generated are skipped, and jobs
+// are dropped when the queue is full. The owner's pending prefetches of earlier
+// recommendations are superseded and cancelled.
+func (p *Prefetcher) Enqueue(owner string, jobs []PrefetchJob) {
+	if p == nil || p.service == nil {
+		return
+	}
+	if len(jobs) > p.topN {
+		jobs = jobs[:p.topN]
+	}
+	keys := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		keys[DetailCacheKey(job.RecipeID, job.Options.Allergens, job.Options.Cuisine, job.Lang)] = true
+	}
+	if owner != "" {
+		p.supersede(owner, keys)
+	}
+
+	for _, job := range jobs {
+		key := DetailCacheKey(job.RecipeID, job.Options.Allergens, job.Options.Cuisine, job.Lang)
+		if p.cached(key) || p.service.flights.inFlight(key) {
+			continue
+		}
+
+		p.mutex.Lock()
+		if _, ok := p.pending[key]; ok || p.ctx.Err() != nil {
+			p.mutex.Unlock()
+			continue
+		}
+		pending := &pendingPrefetch{owner: owner}
+		p.pending[key] = pending
+		p.mutex.Unlock()
+
+		select {
+		case p.jobs <- job:
+		default:
+			log.Printf("[Prefetcher] 预取队列已满，跳过: %s", job.Title)
+			p.finish(key, pending)
+		}
+	}
+}
+
+// Cancel drops the queued prefetch of a recipe detail that a foreground request is about
+// to generate itself. A running prefetch is kept: the request attaches to its generation.
+func (p *Prefetcher) Cancel(recipeID string, opts DetailOptions, lang string) {
+	if p == nil {
+		return
+	}
+	key := DetailCacheKey(recipeID, opts.Allergens, opts.Cuisine, lang)
+	p.cancelKeys(func(k string, pending *pendingPrefetch) bool {
+		return k == key && pending.cancel == nil
+	})
+}
+
+// supersede 取消用户之前推荐的、不在新推荐中的预取
+func (p *Prefetcher) supersede(owner string, keep map[string]bool) {
+	p.cancelKeys(func(key string, pending *pendingPrefetch) bool {
+		return pending.owner == owner && !keep[key]
+	})
+}
+
+// cancelKeys 取消符合条件的预取：排队中的任务被跳过，进行中的生成被取消
+func (p *Prefetcher) cancelKeys(match func(key string, pending *pendingPrefetch) bool) {
+	var cancels []context.CancelFunc
+	p.mutex.Lock()
+	for key, pending := range p.pending {
+		if !match(key, pending) {
+			continue
+		}
+		delete(p.pending, key)
+		if pending.cancel != nil {
+			cancels = append(cancels, pending.cancel)
+		}
+	}
+	p.mutex.Unlock()
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+}
+
+// work runs prefetch jobs until the prefetcher is stopped
+func (p *Prefetcher) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case job := <-p.jobs:
+			p.run(job)
+		}
+	}
+}
+
+func (p *Prefetcher) run(job PrefetchJob) {
+	key := DetailCacheKey(job.RecipeID, job.Options.Allergens, job.Options.Cuisine, job.Lang)
+
+	ctx, cancel := context.WithCancel(WithPriority(p.ctx, PriorityLow))
+	defer cancel()
+
+	p.mutex.Lock()
+	pending, ok := p.pending[key]
+	if !ok {
+		// 排队期间已被取消
+		p.mutex.Unlock()
+		return
+	}
+	pending.cancel = cancel
+	p.mutex.Unlock()
+	defer p.finish(key, pending)
+
+	_, err := p.service.LoadRecipeDetail(ctx, p.cache, job.RecipeID, job.Title, job.Options, job.Lang)
+	switch {
+	case err == nil:
+		log.Printf("[Prefetcher] 菜谱详情预取完成: %s", job.Title)
+	case errors.Is(err, context.Canceled):
+		log.Printf("[Prefetcher] 菜谱详情预取已取消: %s", job.Title)
+	default:
+		log.Printf("[Prefetcher] 菜谱详情预取失败: %s %v", job.Title, err)
+	}
+}
+
+// finish 移除已结束的预取；取消后同一缓存键可能已重新入队，只移除自己的记录
+func (p *Prefetcher) finish(key string, pending *pendingPrefetch) {
+	p.mutex.Lock()
+	if p.pending[key] == pending {
+		delete(p.pending, key)
+	}
+	p.mutex.Unlock()
+}
+
+func (p *Prefetcher) cached(key string) bool {
+	if cache.DefaultManager == nil {
+		return false
+	}
+	var cached models.NewRecipeDetail
+	return cache.DefaultManager.GetJSON(key, &cached)
+}
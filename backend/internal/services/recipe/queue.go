@@ -0,0 +1,107 @@
+package recipe
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Priority LLM 调用的优先级，用户请求优先于后台预取
+type Priority int32
+
+const (
+	PriorityLow  Priority = 0 // 后台预取
+	PriorityHigh Priority = 1 // 用户请求
+)
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose LLM calls are queued with the given priority.
+// Calls without a priority are treated as user requests.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	level := new(atomic.Int32)
+	level.Store(int32(p))
+	return context.WithValue(ctx, priorityKey{}, level)
+}
+
+// priorityOf returns the (possibly raised later) priority level of a context
+func priorityOf(ctx context.Context) *atomic.Int32 {
+	if level, ok := ctx.Value(priorityKey{}).(*atomic.Int32); ok {
+		return level
+	}
+	level := new(atomic.Int32)
+	level.Store(int32(PriorityHigh))
+	return level
+}
+
+// llmQueue 限制同时进行的 LLM 调用数，空闲槽位优先分配给高优先级的等待者
+type llmQueue struct {
+	mutex   sync.Mutex
+	slots   int
+	active  int
+	waiting []*queueWaiter
+}
+
+type queueWaiter struct {
+	ready    chan struct{}
+	priority *atomic.Int32
+}
+
+func newLLMQueue(slots int) *llmQueue {
+	if slots < 1 {
+		slots = 1
+	}
+	return &llmQueue{slots: slots}
+}
+
+// acquire waits for a free slot; the slot must be released with release
+func (q *llmQueue) acquire(ctx context.Context) error {
+	q.mutex.Lock()
+	if q.active < q.slots && len(q.waiting) == 0 {
+		q.active++
+		q.mutex.Unlock()
+		return nil
+	}
+	w := &queueWaiter{ready: make(chan struct{}), priority: priorityOf(ctx)}
+	q.waiting = append(q.waiting, w)
+	q.mutex.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		q.mutex.Lock()
+		for i, other := range q.waiting {
+			if other == w {
+				q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+				q.mutex.Unlock()
+				return ctx.Err()
+			}
+		}
+		q.mutex.Unlock()
+		// 取消的同时已分配到槽位，交给下一个等待者
+		q.release()
+		return ctx.Err()
+	}
+}
+
+// release hands the slot to the highest-priority waiter (first come first served
+// within the same priority), or frees it
+func (q *llmQueue) release() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.waiting) == 0 {
+		q.active--
+		return
+	}
+	next := 0
+	for i, w := range q.waiting {
+		if w.priority.Load() > q.waiting[next].priority.Load() {
+			next = i
+		}
+	}
+	w := q.waiting[next]
+	q.waiting = append(q.waiting[:next], q.waiting[next+1:]...)
+	close(w.ready)
+}
@@ -0,0 +1,92 @@
+package recipe
+
+import (
+	"sort"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/leftover"
+	"github.com/eat-only-in-season/backend/internal/services/seasonality"
+	"github.com/eat-only-in-season/backend/internal/services/timing"
+)
+
+// ValidSort reports whether a recommendation sort order is supported
+func ValidSort(sortBy models.RecipeSort) bool {
+	switch sortBy {
+	case models.RecipeSortDefault, models.RecipeSortLeftovers, models.RecipeSortSeasonality, models.RecipeSortCookingTime:
+		return true
+	}
+	return false
+}
+
+// Rank verifies the matched and in-season ingredients of recommendations, scores their
+// seasonality for the given month, then applies the filters and sort order of a request.
+// Recipes whose cooking time cannot be parsed are kept and sorted last.
+//
+// Without an explicit sort order recipes are sorted by how completely they use up the
+// leftovers when there are any, otherwise by seasonality boosted by how well they match
+// the user's taste profile (see ApplyFeedback), except when the pantry was used, in
+// which case the pantry order of the service is kept.
+func Rank(resp *models.GetRecipesByIngredientsResponse, req *models.GetRecipesByIngredientsRequest, seasonal []models.SeasonalIngredient, month int) {
+	limit := req.MaxCookingMinutes * 60
+	recipes := make([]models.RecipeWithMatch, 0, len(resp.Recipes))
+	for _, r := range resp.Recipes {
+		// 兼容缓存中尚未解析时长的旧数据
+		if r.CookingTimeSeconds == 0 {
+			r.CookingTimeSeconds, _ = timing.Parse(r.CookingTime)
+		}
+		if limit > 0 && r.CookingTimeSeconds > limit {
+			continue
+		}
+		seasonality.Score(&r, req.Ingredients, seasonal, month)
+		leftover.Annotate(&r, req.Leftovers)
+		if r.SeasonalityScore < req.MinSeasonality {
+			continue
+		}
+		recipes = append(recipes, r)
+	}
+
+	sortBy := req.SortBy
+	boost := 0.0
+	if sortBy == models.RecipeSortDefault {
+		switch {
+		case len(req.Leftovers) > 0:
+			sortBy = models.RecipeSortLeftovers
+		case !req.UsePantry:
+			sortBy = models.RecipeSortSeasonality
+			boost = preferenceWeight
+		}
+	}
+	switch sortBy {
+	case models.RecipeSortLeftovers:
+		// 用掉剩余食材越多越靠前，相同时优先更应季的菜
+		sort.SliceStable(recipes, func(i, j int) bool {
+			a, b := recipes[i], recipes[j]
+			if a.LeftoverScore != b.LeftoverScore {
+				return a.LeftoverScore > b.LeftoverScore
+			}
+			if a.WasteAvoidedGrams != b.WasteAvoidedGrams {
+				return a.WasteAvoidedGrams > b.WasteAvoidedGrams
+			}
+			return a.SeasonalityScore > b.SeasonalityScore
+		})
+	case models.RecipeSortSeasonality:
+		sort.SliceStable(recipes, func(i, j int) bool {
+			a, b := recipes[i], recipes[j]
+			return a.SeasonalityScore+boost*a.PreferenceScore > b.SeasonalityScore+boost*b.PreferenceScore
+		})
+	case models.RecipeSortCookingTime:
+		sort.SliceStable(recipes, func(i, j int) bool {
+			a, b := recipes[i].CookingTimeSeconds, recipes[j].CookingTimeSeconds
+			if a == 0 || b == 0 {
+				return b == 0 && a != 0
+			}
+			return a < b
+		})
+	}
+	resp.Recipes = recipes
+
+	resp.WasteAvoidedGrams = 0
+	if len(recipes) > 0 {
+		resp.WasteAvoidedGrams = recipes[0].WasteAvoidedGrams
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 
 	"github.com/ahhsitt/helloagents-go/pkg/agents"
@@ -12,6 +14,11 @@ import (
 	"github.com/eat-only-in-season/backend/internal/i18n"
 	"github.com/eat-only-in-season/backend/internal/models"
 	"github.com/eat-only-in-season/backend/internal/services/ai"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/cuisine"
+	"github.com/eat-only-in-season/backend/internal/services/leftover"
+	"github.com/eat-only-in-season/backend/internal/services/pantry"
+	"github.com/eat-only-in-season/backend/internal/services/timing"
 	"github.com/eat-only-in-season/backend/pkg/config"
 	"github.com/google/uuid"
 )
@@ -21,6 +28,8 @@ type Service struct {
 	config   *config.Config
 	provider *ai.ProviderManager
 	llm      llm.Provider
+	queue    *llmQueue
+	flights  *flightGroup
 }
 
 // NewService creates a new recipe service
@@ -28,6 +37,8 @@ func NewService(cfg *config.Config, provider *ai.ProviderManager) (*Service, err
 	s := &Service{
 		config:   cfg,
 		provider: provider,
+		queue:    newLLMQueue(cfg.LLMConcurrency),
+		flights:  newFlightGroup(),
 	}
 
 	if err := s.initLLM(); err != nil {
@@ -79,50 +90,201 @@ func (s *Service) initLLM() error {
 	return fmt.Errorf("没有配置任何 LLM 服务，请设置 API Key 环境变量")
 }
 
-// GetRecipeRecommendations returns recipe recommendations based on selected ingredients
-func (s *Service) GetRecipeRecommendations(ctx context.Context, req *models.GetRecipesByIngredientsRequest, lang string) (*models.GetRecipesByIngredientsResponse, error) {
+// Allergen enforcement limits
+const (
+	// maxAllergenAttempts 推荐或详情含过敏原（详情还包括缺少推荐依据的食材）时最多生成的次数（含首次）
+	maxAllergenAttempts = 2
+	// minSafeRecipes 过滤后安全菜谱少于该数量时重新生成
+	minSafeRecipes = 3
+)
+
+// DetailOptions 生成菜谱详情时的附加约束
+type DetailOptions struct {
+	// Allergens 用户声明的过敏原，详情中不得出现
+	Allergens []models.Allergen
+	// Cuisine 推荐时确定的菜系，详情保持相同的菜系风格，为空表示不限
+	Cuisine models.Cuisine
+	// Context 推荐该菜谱时的上下文，详情沿用其食材、城市和偏好，并须用到推荐依据的应季食材
+	Context *models.RecommendationContext
+}
+
+// RecommendOptions 推荐菜谱时的附加上下文
+type RecommendOptions struct {
+	// Pantry 用户库存中可用的食材（按临期排序），为空表示不结合库存
+	Pantry []models.PantryItem
+	// Feedback 由用户历史评价归纳出的口味偏好，为空表示不做个性化
+	Feedback *models.FeedbackProfile
+}
+
+// AllergenConflictError 多次生成后菜谱仍包含用户声明的过敏原
+type AllergenConflictError struct {
+	Conflicts []models.AllergenConflict
+}
+
+func (e *AllergenConflictError) Error() string {
+	allergens := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		allergens = append(allergens, string(c.Allergen))
+	}
+	return fmt.Sprintf("菜谱包含过敏原: %s", strings.Join(allergens, ", "))
+}
+
+// GetRecipeRecommendations returns recipe recommendations based on selected ingredients.
+// Recipes containing any of the declared allergens are dropped, and the model is asked
+// again when too few safe recipes remain; when asking again fails, the safe recipes
+// found so far are returned. With pantry items, recipes using the items
+// that expire soonest and missing the fewest ingredients are ranked first.
+func (s *Service) GetRecipeRecommendations(ctx context.Context, req *models.GetRecipesByIngredientsRequest, opts RecommendOptions, lang string) (*models.GetRecipesByIngredientsResponse, error) {
 	if !s.provider.HasLLM() {
 		return nil, fmt.Errorf("没有可用的 LLM 服务，请配置 API Key")
 	}
 
-	prompt := s.buildRecipeRecommendationPrompt(req, lang)
+	declared := allergen.Normalize(req.Allergens)
+	result := &models.GetRecipesByIngredientsResponse{
+		Recipes:          make([]models.RecipeWithMatch, 0),
+		CheckedAllergens: declared,
+	}
 
-	response, err := s.callLLM(ctx, prompt, lang)
-	if err != nil {
-		return nil, fmt.Errorf("获取菜谱推荐失败: %w", err)
+	var rejected []string
+	seen := make(map[string]bool)
+	for attempt := 1; attempt <= maxAllergenAttempts; attempt++ {
+		prompt := s.buildRecipeRecommendationPrompt(req, opts, declared, rejected, lang)
+
+		// 重新请求失败时返回之前已得到的不含过敏原的菜谱
+		response, err := s.callLLM(ctx, prompt, lang)
+		if err != nil {
+			if len(result.Recipes) > 0 {
+				log.Printf("[RecipeService] 重新获取菜谱推荐失败，使用之前的 %d 道菜谱: %v", len(result.Recipes), err)
+				break
+			}
+			return nil, fmt.Errorf("获取菜谱推荐失败: %w", err)
+		}
+
+		parsed, err := s.parseRecipeRecommendationResponse(response, req.Ingredients, lang)
+		if err != nil {
+			if len(result.Recipes) > 0 {
+				log.Printf("[RecipeService] 解析重新获取的菜谱推荐失败，使用之前的 %d 道菜谱: %v", len(result.Recipes), err)
+				break
+			}
+			return nil, fmt.Errorf("解析菜谱推荐响应失败: %w", err)
+		}
+
+		for _, r := range parsed.Recipes {
+			if seen[r.Title] {
+				continue
+			}
+			seen[r.Title] = true
+
+			if conflicts := allergen.RecipeConflicts(&r, declared); len(conflicts) > 0 {
+				log.Printf("[RecipeService] 过滤含过敏原的菜谱: %s %v", r.Title, conflicts)
+				rejected = append(rejected, r.Title)
+				continue
+			}
+			result.Recipes = append(result.Recipes, r)
+		}
+
+		if len(declared) == 0 || len(result.Recipes) >= minSafeRecipes {
+			break
+		}
 	}
 
-	result, err := s.parseRecipeRecommendationResponse(response, req.Ingredients, lang)
-	if err != nil {
-		return nil, fmt.Errorf("解析菜谱推荐响应失败: %w", err)
+	// 标注库存食材与缺少的食材（剩余食材不算缺少）
+	selected := append(append([]string(nil), req.Ingredients...), leftover.Names(req.Leftovers)...)
+	scores := make(map[string]float64, len(result.Recipes))
+	for i := range result.Recipes {
+		r := &result.Recipes[i]
+		scores[r.ID] = pantry.Annotate(r, selected, opts.Pantry)
+	}
+	if len(opts.Pantry) > 0 {
+		result.PantryItems = pantry.Names(opts.Pantry)
+		sort.SliceStable(result.Recipes, func(i, j int) bool {
+			a, b := result.Recipes[i], result.Recipes[j]
+			if scores[a.ID] != scores[b.ID] {
+				return scores[a.ID] > scores[b.ID]
+			}
+			return a.MissingCount < b.MissingCount
+		})
 	}
 
 	return result, nil
 }
 
-// GetRecipeDetail returns detailed recipe information
-func (s *Service) GetRecipeDetail(ctx context.Context, recipeID string, recipeTitle string, lang string) (*models.NewRecipeDetail, error) {
+// GetRecipeDetail returns detailed recipe information.
+// The detail is regenerated when it contains a declared allergen or leaves out
+// ingredients the recommendation was based on, up to maxAllergenAttempts in total.
+// The allergen-free attempt missing the fewest of those ingredients is returned, with
+// the ones still missing in MissingSeasonalIngredients; when every attempt contains a
+// declared allergen an *AllergenConflictError is returned.
+func (s *Service) GetRecipeDetail(ctx context.Context, recipeID string, recipeTitle string, opts DetailOptions, lang string) (*models.NewRecipeDetail, error) {
 	if !s.provider.HasLLM() {
 		return nil, fmt.Errorf("没有可用的 LLM 服务，请配置 API Key")
 	}
 
-	prompt := s.buildRecipeDetailPrompt(recipeTitle, lang)
+	required := RequiredIngredients(opts.Context)
+	var conflicts []models.AllergenConflict
+	var missing []string
+	// best 目前缺少食材最少的不含过敏原的详情
+	var best *models.NewRecipeDetail
+	for attempt := 1; attempt <= maxAllergenAttempts; attempt++ {
+		prompt := s.buildRecipeDetailPrompt(recipeTitle, opts, conflicts, missing, lang)
 
-	response, err := s.callLLM(ctx, prompt, lang)
-	if err != nil {
-		return nil, fmt.Errorf("获取菜谱详情失败: %w", err)
-	}
+		response, err := s.callLLM(ctx, prompt, lang)
+		if err != nil {
+			if best != nil {
+				log.Printf("[RecipeService] 重新生成菜谱详情失败，使用之前的结果: %s %v", recipeTitle, err)
+				break
+			}
+			return nil, fmt.Errorf("获取菜谱详情失败: %w", err)
+		}
 
-	detail, err := s.parseRecipeDetailResponse(response, recipeID, recipeTitle, lang)
-	if err != nil {
-		return nil, fmt.Errorf("解析菜谱详情响应失败: %w", err)
+		detail, err := s.parseRecipeDetailResponse(response, recipeID, recipeTitle, lang)
+		if err != nil {
+			if best != nil {
+				log.Printf("[RecipeService] 解析重新生成的菜谱详情失败，使用之前的结果: %s %v", recipeTitle, err)
+				break
+			}
+			return nil, fmt.Errorf("解析菜谱详情响应失败: %w", err)
+		}
+
+		if opts.Cuisine != "" {
+			detail.Cuisine = opts.Cuisine
+		}
+		allergen.TagDetail(detail)
+		detailConflicts := allergen.DetailConflicts(detail, opts.Allergens)
+		detailMissing := MissingIngredients(detail, required)
+		if len(detailConflicts) > 0 {
+			log.Printf("[RecipeService] 菜谱详情含过敏原 (第 %d 次): %s %v", attempt, recipeTitle, detailConflicts)
+			// 继续要求补上之前缺少的食材
+			conflicts = detailConflicts
+			if best == nil {
+				missing = detailMissing
+			}
+			continue
+		}
+
+		conflicts = nil
+		if best == nil || len(detailMissing) < len(best.MissingSeasonalIngredients) {
+			detail.MissingSeasonalIngredients = detailMissing
+			best = detail
+		}
+		missing = best.MissingSeasonalIngredients
+		if len(missing) == 0 {
+			break
+		}
+		log.Printf("[RecipeService] 菜谱详情缺少推荐依据的食材 (第 %d 次): %s %v", attempt, recipeTitle, detailMissing)
 	}
 
-	return detail, nil
+	if best != nil {
+		if len(best.MissingSeasonalIngredients) > 0 {
+			log.Printf("[RecipeService] 菜谱详情仍未用到推荐依据的食材: %s %v", recipeTitle, best.MissingSeasonalIngredients)
+		}
+		return best, nil
+	}
+	return nil, &AllergenConflictError{Conflicts: conflicts}
 }
 
 // buildRecipeRecommendationPrompt builds the prompt for recipe recommendations
-func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngredientsRequest, lang string) string {
+func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngredientsRequest, opts RecommendOptions, declared []models.Allergen, rejected []string, lang string) string {
 	var sb strings.Builder
 
 	langInstruction := i18n.GetPrompt(lang, "language_instruction")
@@ -147,6 +309,14 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
 		if req.Preference != "" {
 			sb.WriteString(fmt.Sprintf("- User preferences: %s\n", req.Preference))
 		}
+		if req.MaxCookingMinutes > 0 {
+			sb.WriteString(fmt.Sprintf("- Total cooking time must not exceed %d minutes\n", req.MaxCookingMinutes))
+		}
+		writePantryContext(&sb, opts.Pantry, lang)
+		writeLeftoverContext(&sb, req.Leftovers, lang)
+		writeCuisineContext(&sb, req.Cuisine, req.Location, lang)
+		writeFeedbackContext(&sb, opts.Feedback, lang)
+		writeAllergenContext(&sb, declared, rejected, lang)
 
 		sb.WriteString("\n## Requirements\n")
 		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
@@ -154,7 +324,9 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
 		sb.WriteString("3. Prioritize recipes that use the selected ingredients\n")
 		sb.WriteString("4. Partial matching is allowed\n")
 		sb.WriteString("5. Respect user preferences (e.g., no spicy, light flavor)\n")
-		sb.WriteString("6. Difficulty levels: Easy, Medium, Hard\n\n")
+		sb.WriteString("6. Difficulty levels: Easy, Medium, Hard\n")
+		sb.WriteString("7. List all main ingredients and key condiments of each recipe in \"ingredients\", and the allergen codes they contain in \"allergens\"\n")
+		sb.WriteString(fmt.Sprintf("8. Set \"cuisine\" to the cuisine code of each recipe, one of: %s\n\n", cuisine.Codes()))
 
 		sb.WriteString("## Output Format\n")
 		sb.WriteString("Please output in JSON format:\n")
@@ -164,9 +336,12 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
     {
       "title": "Recipe name",
       "description": "Brief description (under 100 words)",
+      "ingredients": ["ingredient1", "ingredient2", "soy sauce"],
       "matchedIngredients": ["ingredient1", "ingredient2"],
+      "allergens": ["soy", "wheat"],
       "cookingTime": "30 minutes",
       "difficulty": "easy",
+      "cuisine": "italian",
       "tags": ["tag1", "tag2"]
     }
   ]
@@ -191,6 +366,14 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
 		if req.Preference != "" {
 			sb.WriteString(fmt.Sprintf("- 用户偏好：%s\n", req.Preference))
 		}
+		if req.MaxCookingMinutes > 0 {
+			sb.WriteString(fmt.Sprintf("- 总烹饪时间不超过 %d 分钟\n", req.MaxCookingMinutes))
+		}
+		writePantryContext(&sb, opts.Pantry, lang)
+		writeLeftoverContext(&sb, req.Leftovers, lang)
+		writeCuisineContext(&sb, req.Cuisine, req.Location, lang)
+		writeFeedbackContext(&sb, opts.Feedback, lang)
+		writeAllergenContext(&sb, declared, rejected, lang)
 
 		sb.WriteString("\n## 要求\n")
 		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
@@ -198,7 +381,9 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
 		sb.WriteString("3. 如果用户选择了食材，优先推荐能用到这些食材的菜\n")
 		sb.WriteString("4. 允许部分匹配（即菜谱不必用到所有选择的食材）\n")
 		sb.WriteString("5. 如果用户有偏好（如不吃辣、清淡等），请严格遵守\n")
-		sb.WriteString("6. 难度标注为：easy、medium、hard\n\n")
+		sb.WriteString("6. 难度标注为：easy、medium、hard\n")
+		sb.WriteString("7. 在 ingredients 中列出每道菜的全部主料和关键调料，在 allergens 中列出其包含的过敏原代码\n")
+		sb.WriteString(fmt.Sprintf("8. cuisine 填写每道菜所属菜系的代码，取值为：%s\n\n", cuisine.Codes()))
 
 		sb.WriteString("## 输出格式\n")
 		sb.WriteString("请以 JSON 格式输出：\n")
@@ -208,9 +393,12 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
     {
       "title": "菜名",
       "description": "简短描述（100字内）",
+      "ingredients": ["食材1", "食材2", "酱油"],
       "matchedIngredients": ["匹配的食材1", "匹配的食材2"],
+      "allergens": ["soy", "wheat"],
       "cookingTime": "30分钟",
       "difficulty": "easy",
+      "cuisine": "cantonese",
       "tags": ["标签1", "标签2"]
     }
   ]
@@ -224,7 +412,7 @@ func (s *Service) buildRecipeRecommendationPrompt(req *models.GetRecipesByIngred
 }
 
 // buildRecipeDetailPrompt builds the prompt for recipe detail
-func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) string {
+func (s *Service) buildRecipeDetailPrompt(recipeTitle string, opts DetailOptions, conflicts []models.AllergenConflict, missing []string, lang string) string {
 	var sb strings.Builder
 
 	langInstruction := i18n.GetPrompt(lang, "language_instruction")
@@ -236,13 +424,22 @@ func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) strin
 		sb.WriteString("You are a professional chef and food writer, skilled at writing detailed and easy-to-follow recipe tutorials.\n\n")
 		sb.WriteString("## Task\n")
 		sb.WriteString(fmt.Sprintf("Please generate a detailed cooking tutorial for the dish: %s\n\n", recipeTitle))
+		writeRecommendationContext(&sb, opts.Context, lang)
 
 		sb.WriteString("## Requirements\n")
 		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
 		sb.WriteString("2. Ingredient list should be precise with amounts (e.g., \"2 eggs\", \"5g salt\")\n")
 		sb.WriteString("3. Steps should be clear and detailed, suitable for beginners\n")
 		sb.WriteString("4. Provide practical cooking tips\n")
-		sb.WriteString("5. Difficulty levels: easy, medium, hard\n\n")
+		sb.WriteString("5. Difficulty levels: easy, medium, hard\n")
+		index := 6
+		if opts.Cuisine != "" {
+			sb.WriteString(fmt.Sprintf("%d. Keep the dish in %s style: techniques, seasonings and presentation should be consistent with this cuisine\n", index, cuisine.Name(opts.Cuisine, lang)))
+			index++
+		}
+		index = writeAllergenRequirement(&sb, index, opts.Allergens, conflicts, lang)
+		writeRequiredIngredients(&sb, index, RequiredIngredients(opts.Context), missing, lang)
+		sb.WriteString("\n")
 
 		sb.WriteString("## Output Format\n")
 		sb.WriteString("Please output in JSON format:\n")
@@ -259,6 +456,7 @@ func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) strin
   "cookingTime": "total time",
   "servings": "2-3 servings",
   "difficulty": "easy",
+  "cuisine": "cuisine code",
   "tags": ["tag"],
   "tips": "cooking tips"
 }
@@ -269,13 +467,22 @@ func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) strin
 		sb.WriteString("你是一位专业的厨师和美食作家，擅长编写详细易懂的菜谱教程。\n\n")
 		sb.WriteString("## 任务\n")
 		sb.WriteString(fmt.Sprintf("请为「%s」这道菜生成详细的制作教程。\n\n", recipeTitle))
+		writeRecommendationContext(&sb, opts.Context, lang)
 
 		sb.WriteString("## 要求\n")
 		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
 		sb.WriteString("2. 食材清单精确到用量（如「鸡蛋 2个」「盐 5克」）\n")
 		sb.WriteString("3. 制作步骤详细清晰，适合厨房新手\n")
 		sb.WriteString("4. 提供实用的烹饪小贴士\n")
-		sb.WriteString("5. 难度标注为：easy、medium、hard\n\n")
+		sb.WriteString("5. 难度标注为：easy、medium、hard\n")
+		index := 6
+		if opts.Cuisine != "" {
+			sb.WriteString(fmt.Sprintf("%d. 保持%s的风格：烹饪手法、调味和摆盘都要符合该菜系\n", index, cuisine.Name(opts.Cuisine, lang)))
+			index++
+		}
+		index = writeAllergenRequirement(&sb, index, opts.Allergens, conflicts, lang)
+		writeRequiredIngredients(&sb, index, RequiredIngredients(opts.Context), missing, lang)
+		sb.WriteString("\n")
 
 		sb.WriteString("## 输出格式\n")
 		sb.WriteString("请以 JSON 格式输出：\n")
@@ -292,6 +499,7 @@ func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) strin
   "cookingTime": "总时长",
   "servings": "2-3人份",
   "difficulty": "easy",
+  "cuisine": "菜系代码",
   "tags": ["标签"],
   "tips": "烹饪小贴士"
 }
@@ -303,6 +511,229 @@ func (s *Service) buildRecipeDetailPrompt(recipeTitle string, lang string) strin
 	return sb.String()
 }
 
+// writeRecommendationContext writes the context the recipe was recommended in, so the
+// detail stays faithful to the selected and seasonal ingredients that justified it
+func writeRecommendationContext(sb *strings.Builder, rc *models.RecommendationContext, lang string) {
+	if rc == nil {
+		return
+	}
+
+	if lang == "en" {
+		sb.WriteString("## Context\n")
+		if rc.Description != "" {
+			sb.WriteString(fmt.Sprintf("- Recommended as: %s\n", rc.Description))
+		}
+		if len(rc.Ingredients) > 0 {
+			sb.WriteString(fmt.Sprintf("- Ingredients in the recommendation: %s\n", strings.Join(rc.Ingredients, ", ")))
+		}
+		if len(rc.SelectedIngredients) > 0 {
+			sb.WriteString(fmt.Sprintf("- Ingredients selected by the user: %s\n", strings.Join(rc.SelectedIngredients, ", ")))
+		}
+		if rc.Location != "" {
+			sb.WriteString(fmt.Sprintf("- User location: %s\n", rc.Location))
+		}
+		if rc.Preference != "" {
+			sb.WriteString(fmt.Sprintf("- User preferences (must be respected): %s\n", rc.Preference))
+		}
+		if rc.MaxCookingMinutes > 0 {
+			sb.WriteString(fmt.Sprintf("- Total cooking time must not exceed %d minutes\n", rc.MaxCookingMinutes))
+		}
+	} else {
+		sb.WriteString("## 上下文信息\n")
+		if rc.Description != "" {
+			sb.WriteString(fmt.Sprintf("- 推荐时的描述：%s\n", rc.Description))
+		}
+		if len(rc.Ingredients) > 0 {
+			sb.WriteString(fmt.Sprintf("- 推荐时列出的食材：%s\n", strings.Join(rc.Ingredients, "、")))
+		}
+		if len(rc.SelectedIngredients) > 0 {
+			sb.WriteString(fmt.Sprintf("- 用户选择的食材：%s\n", strings.Join(rc.SelectedIngredients, "、")))
+		}
+		if rc.Location != "" {
+			sb.WriteString(fmt.Sprintf("- 用户所在城市：%s\n", rc.Location))
+		}
+		if rc.Preference != "" {
+			sb.WriteString(fmt.Sprintf("- 用户偏好（必须遵守）：%s\n", rc.Preference))
+		}
+		if rc.MaxCookingMinutes > 0 {
+			sb.WriteString(fmt.Sprintf("- 总烹饪时间不超过 %d 分钟\n", rc.MaxCookingMinutes))
+		}
+	}
+	if len(rc.Leftovers) > 0 {
+		parts := make([]string, 0, len(rc.Leftovers))
+		for _, l := range rc.Leftovers {
+			parts = append(parts, strings.TrimSpace(l.Name+" "+l.Amount))
+		}
+		if lang == "en" {
+			sb.WriteString(fmt.Sprintf("- Leftovers to use up: %s\n", strings.Join(parts, ", ")))
+		} else {
+			sb.WriteString(fmt.Sprintf("- 需要用完的剩余食材：%s\n", strings.Join(parts, "、")))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// writeRequiredIngredients writes the ingredients the detail must list; when a previous
+// attempt left some out, they are named explicitly
+func writeRequiredIngredients(sb *strings.Builder, index int, required, missing []string, lang string) {
+	if len(required) == 0 {
+		return
+	}
+
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("%d. The dish was recommended for these in-season ingredients, the ingredient list must include all of them: %s\n",
+			index, strings.Join(required, ", ")))
+		if len(missing) > 0 {
+			sb.WriteString(fmt.Sprintf("%d. The previous version left out: %s. Add them\n", index+1, strings.Join(missing, ", ")))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("%d. 这道菜是根据以下应季食材推荐的，食材清单必须全部包含：%s\n",
+			index, strings.Join(required, "、")))
+		if len(missing) > 0 {
+			sb.WriteString(fmt.Sprintf("%d. 上一版本漏掉了：%s，请补上\n", index+1, strings.Join(missing, "、")))
+		}
+	}
+}
+
+// maxPantryPromptItems 提示词中最多列出的库存食材数
+const maxPantryPromptItems = 20
+
+// writePantryContext writes the user's pantry, soonest expiry first, into the prompt context
+func writePantryContext(sb *strings.Builder, items []models.PantryItem, lang string) {
+	if len(items) == 0 {
+		return
+	}
+	if len(items) > maxPantryPromptItems {
+		items = items[:maxPantryPromptItems]
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		part := item.Name
+		if item.Quantity != "" {
+			part += " " + item.Quantity
+		}
+		if item.DaysLeft != nil {
+			if lang == "en" {
+				part += fmt.Sprintf(" (expires in %d days)", *item.DaysLeft)
+			} else {
+				part += fmt.Sprintf("（%d天后过期）", *item.DaysLeft)
+			}
+		}
+		parts = append(parts, part)
+	}
+
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("- Ingredients the user already has at home (soonest expiry first): %s. Prefer recipes that use the items expiring soonest and need as few extra ingredients as possible\n",
+			strings.Join(parts, ", ")))
+	} else {
+		sb.WriteString(fmt.Sprintf("- 用户家中已有的食材（按临期先后排序）：%s。优先推荐能用掉即将过期食材、且需要额外购买的食材尽量少的菜\n",
+			strings.Join(parts, "、")))
+	}
+}
+
+// writeLeftoverContext writes the leftovers to use up and asks the model to report
+// how much of each leftover every recipe uses
+func writeLeftoverContext(sb *strings.Builder, leftovers []models.Leftover, lang string) {
+	if len(leftovers) == 0 {
+		return
+	}
+
+	parts := make([]string, 0, len(leftovers))
+	for _, l := range leftovers {
+		parts = append(parts, strings.TrimSpace(l.Name+" "+l.Amount))
+	}
+
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("- Leftovers to use up (approximate amounts): %s. Top priority is using these up completely to avoid food waste, combined with the selected seasonal ingredients. "+
+			"For each recipe add \"leftoverUsage\": [{\"name\": \"leftover\", \"usedAmount\": \"amount used\", \"usedGrams\": 200, \"percentUsed\": 100}]\n",
+			strings.Join(parts, ", ")))
+	} else {
+		sb.WriteString(fmt.Sprintf("- 需要用完的剩余食材（大致剩余量）：%s。首要目标是把这些食材全部用完、避免浪费，并搭配所选的应季食材。"+
+			"每道菜请增加 \"leftoverUsage\": [{\"name\": \"剩余食材\", \"usedAmount\": \"用量\", \"usedGrams\": 200, \"percentUsed\": 100}]\n",
+			strings.Join(parts, "、")))
+	}
+}
+
+// writeCuisineContext writes the preferred cuisine; "local" asks for the local cuisine
+// of the user's city when it could not be resolved to a known cuisine
+func writeCuisineContext(sb *strings.Builder, code, location, lang string) {
+	if code == "" {
+		return
+	}
+	if models.Cuisine(code) == models.CuisineLocal {
+		if lang == "en" {
+			sb.WriteString(fmt.Sprintf("- Preferred cuisine: the local cuisine of %s, all recipes should be typical local home cooking\n", location))
+		} else {
+			sb.WriteString(fmt.Sprintf("- 偏好菜系：%s当地菜系，所有菜谱都应是当地有代表性的家常做法\n", location))
+		}
+		return
+	}
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("- Preferred cuisine: %s, all recipes should be in this style\n", cuisine.Name(models.Cuisine(code), lang)))
+	} else {
+		sb.WriteString(fmt.Sprintf("- 偏好菜系：%s，所有菜谱都应采用该菜系的风格\n", cuisine.Name(models.Cuisine(code), lang)))
+	}
+}
+
+// writeAllergenContext writes the declared allergens and previously rejected recipes
+// into the context section of the recommendation prompt
+func writeAllergenContext(sb *strings.Builder, declared []models.Allergen, rejected []string, lang string) {
+	if len(declared) == 0 {
+		return
+	}
+
+	names := allergen.Names(declared, lang)
+	examples := allergen.ForbiddenIngredients(declared, lang, 12)
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("- User allergies (strict, life-threatening): %s. Recipes must not contain any ingredient or condiment containing them, e.g. %s\n",
+			strings.Join(names, ", "), strings.Join(examples, ", ")))
+		if len(rejected) > 0 {
+			sb.WriteString(fmt.Sprintf("- Do NOT recommend these recipes again, they contain the allergens: %s\n", strings.Join(rejected, ", ")))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("- 用户过敏原（严格禁止，可能危及生命）：%s。菜谱不得包含任何含有这些过敏原的食材或调料，例如：%s\n",
+			strings.Join(names, "、"), strings.Join(examples, "、")))
+		if len(rejected) > 0 {
+			sb.WriteString(fmt.Sprintf("- 以下菜谱含有过敏原，不要再推荐：%s\n", strings.Join(rejected, "、")))
+		}
+	}
+}
+
+// writeAllergenRequirement writes the allergen requirement of the detail prompt and
+// returns the next requirement index.
+// When a previous attempt conflicted, the offending ingredients are named explicitly.
+func writeAllergenRequirement(sb *strings.Builder, index int, declared []models.Allergen, conflicts []models.AllergenConflict, lang string) int {
+	if len(declared) == 0 {
+		return index
+	}
+
+	names := allergen.Names(declared, lang)
+	examples := allergen.ForbiddenIngredients(declared, lang, 12)
+	var offending []string
+	for _, c := range conflicts {
+		offending = append(offending, c.Ingredients...)
+	}
+
+	if lang == "en" {
+		sb.WriteString(fmt.Sprintf("%d. The user is allergic to: %s. Do not use any ingredient or condiment containing them (e.g. %s); adapt the recipe with safe substitutes\n",
+			index, strings.Join(names, ", "), strings.Join(examples, ", ")))
+		if len(offending) > 0 {
+			sb.WriteString(fmt.Sprintf("%d. The previous version used forbidden ingredients: %s. Replace them\n", index+1, strings.Join(offending, ", ")))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("%d. 用户对以下过敏原过敏：%s。不得使用任何含有这些过敏原的食材或调料（例如：%s），请用安全的替代食材改编菜谱\n",
+			index, strings.Join(names, "、"), strings.Join(examples, "、")))
+		if len(offending) > 0 {
+			sb.WriteString(fmt.Sprintf("%d. 上一版本使用了禁止的食材：%s，请替换\n", index+1, strings.Join(offending, "、")))
+		}
+	}
+	if len(offending) > 0 {
+		return index + 2
+	}
+	return index + 1
+}
+
 // callLLM calls the LLM with the given prompt
 func (s *Service) callLLM(ctx context.Context, prompt string, lang string) (string, error) {
 	var systemPrompt string
@@ -324,6 +755,12 @@ func (s *Service) callLLM(ctx context.Context, prompt string, lang string) (stri
 		Query: prompt,
 	}
 
+	// 排队等待 LLM 槽位，用户请求优先于后台预取
+	if err := s.queue.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer s.queue.release()
+
 	output, err := agent.Run(ctx, input)
 	if err != nil {
 		return "", err
@@ -340,10 +777,19 @@ func (s *Service) parseRecipeRecommendationResponse(response string, selectedIng
 		Recipes []struct {
 			Title              string   `json:"title"`
 			Description        string   `json:"description"`
+			Ingredients        []string `json:"ingredients"`
 			MatchedIngredients []string `json:"matchedIngredients"`
+			Allergens          []string `json:"allergens"`
 			CookingTime        string   `json:"cookingTime"`
 			Difficulty         string   `json:"difficulty"`
+			Cuisine            string   `json:"cuisine"`
 			Tags               []string `json:"tags"`
+			LeftoverUsage      []struct {
+				Name        string  `json:"name"`
+				UsedAmount  string  `json:"usedAmount"`
+				UsedGrams   float64 `json:"usedGrams"`
+				PercentUsed float64 `json:"percentUsed"`
+			} `json:"leftoverUsage"`
 		} `json:"recipes"`
 	}
 
@@ -357,26 +803,44 @@ func (s *Service) parseRecipeRecommendationResponse(response string, selectedIng
 
 	for _, r := range raw.Recipes {
 		// Translate difficulty if needed
-		difficultyDisplay := i18n.GetDifficulty(lang, mapDifficultyToCode(r.Difficulty))
+		difficultyDisplay := i18n.GetDifficulty(lang, DifficultyCode(r.Difficulty))
 
 		recipe := models.RecipeWithMatch{
 			ID:                 uuid.New().String(),
 			Title:              r.Title,
 			Description:        r.Description,
+			Ingredients:        r.Ingredients,
 			MatchedIngredients: r.MatchedIngredients,
 			MatchCount:         len(r.MatchedIngredients),
 			CookingTime:        r.CookingTime,
+			CookingTimeSeconds: parseSeconds(r.CookingTime),
 			Difficulty:         difficultyDisplay,
+			Cuisine:            cuisine.FromModel(r.Cuisine),
 			Tags:               r.Tags,
 		}
+		for _, u := range r.LeftoverUsage {
+			recipe.LeftoverUsage = append(recipe.LeftoverUsage, models.LeftoverUsage{
+				Name:       u.Name,
+				UsedAmount: u.UsedAmount,
+				UsedGrams:  u.UsedGrams,
+				Fraction:   u.PercentUsed / 100,
+			})
+		}
+		allergen.TagRecipe(&recipe, r.Allergens)
 		result.Recipes = append(result.Recipes, recipe)
 	}
 
 	return result, nil
 }
 
-// mapDifficultyToCode maps difficulty display name to code
-func mapDifficultyToCode(name string) string {
+// parseSeconds parses a localized duration into seconds, 0 if unknown
+func parseSeconds(text string) int {
+	seconds, _ := timing.Parse(text)
+	return seconds
+}
+
+// DifficultyCode maps a difficulty display name (either language) to its code
+func DifficultyCode(name string) string {
 	switch name {
 	case "简单", "easy", "Easy":
 		return "easy"
@@ -409,6 +873,7 @@ func (s *Service) parseRecipeDetailResponse(response string, recipeID string, re
 		CookingTime string   `json:"cookingTime"`
 		Servings    string   `json:"servings"`
 		Difficulty  string   `json:"difficulty"`
+		Cuisine     string   `json:"cuisine"`
 		Tags        []string `json:"tags"`
 		Tips        string   `json:"tips"`
 	}
@@ -418,7 +883,7 @@ func (s *Service) parseRecipeDetailResponse(response string, recipeID string, re
 	}
 
 	// Translate difficulty if needed
-	difficultyDisplay := i18n.GetDifficulty(lang, mapDifficultyToCode(raw.Difficulty))
+	difficultyDisplay := i18n.GetDifficulty(lang, DifficultyCode(raw.Difficulty))
 
 	detail := &models.NewRecipeDetail{
 		ID:          recipeID,
@@ -427,6 +892,7 @@ func (s *Service) parseRecipeDetailResponse(response string, recipeID string, re
 		CookingTime: raw.CookingTime,
 		Servings:    raw.Servings,
 		Difficulty:  difficultyDisplay,
+		Cuisine:     cuisine.FromModel(raw.Cuisine),
 		Tags:        raw.Tags,
 		Tips:        raw.Tips,
 		Ingredients: make([]models.RecipeIngredient, 0, len(raw.Ingredients)),
@@ -448,6 +914,7 @@ func (s *Service) parseRecipeDetailResponse(response string, recipeID string, re
 			Duration:    step.Duration,
 		})
 	}
+	timing.Annotate(detail)
 
 	return detail, nil
 }
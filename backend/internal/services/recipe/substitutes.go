@@ -0,0 +1,356 @@
+package recipe
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/cache"
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+	"github.com/eat-only-in-season/backend/internal/services/seasonality"
+	"github.com/eat-only-in-season/backend/internal/services/shopping"
+)
+
+// maxSeasonalHints 提示词中列出的应季食材上限
+const maxSeasonalHints = 30
+
+// SubstituteOptions is the context of a substitution request
+type SubstituteOptions struct {
+	// Detail 食材所在的菜谱（可选），用于判断烹饪作用和换算用量
+	Detail *models.NewRecipeDetail
+	// City 用户所在城市的规范形式（见 city.Canonical），为空表示未提供城市
+	City string
+	// Seasonal 用户所在城市的应季食材，排序时优先
+	Seasonal []models.SeasonalIngredient
+	Month    int
+	// Allergens 用户声明的过敏原，含这些过敏原的替代品不推荐
+	Allergens []models.Allergen
+}
+
+// LoadSubstitutes returns ranked substitutes for an ingredient. The suggestions of the
+// model are cached per city, recipe and ingredient; ranking by season is applied on every call.
+func (s *Service) LoadSubstitutes(ctx context.Context, ingredientName string, opts SubstituteOptions, lang string) (*models.GetSubstitutesResponse, error) {
+	recipeID := ""
+	if opts.Detail != nil {
+		recipeID = opts.Detail.ID
+	}
+	cacheKey := cache.SubstitutesKey(recipeID, catalog.Normalize(ingredientName), opts.City, lang)
+
+	var result *models.GetSubstitutesResponse
+	if cache.DefaultManager != nil {
+		var refresh cache.RefreshFunc
+		if s != nil {
+			refresh = func(ctx context.Context) (any, error) {
+				return s.GetSubstitutes(ctx, ingredientName, opts, lang)
+			}
+		}
+		var cached models.GetSubstitutesResponse
+		if cache.DefaultManager.GetJSONRefresh(cacheKey, &cached, refresh) {
+			log.Printf("[RecipeService] 替代品缓存命中: %s", cacheKey)
+			result = &cached
+		}
+	}
+
+	if result == nil {
+		if s == nil {
+			return nil, ErrServiceUnavailable
+		}
+		var err error
+		result, err = s.GetSubstitutes(ctx, ingredientName, opts, lang)
+		if err != nil {
+			return nil, err
+		}
+		if cache.DefaultManager != nil {
+			if err := cache.DefaultManager.SetJSON(cacheKey, result); err != nil {
+				log.Printf("[RecipeService] 替代品缓存写入失败: %v", err)
+			}
+		}
+	}
+
+	// 过敏原过滤在缓存之后进行，缓存的建议与用户无关
+	if len(opts.Allergens) > 0 {
+		safe := result.Substitutes[:0]
+		for _, sub := range result.Substitutes {
+			if len(allergen.Conflicts(allergen.Detect(sub.Name), opts.Allergens)) == 0 {
+				safe = append(safe, sub)
+			}
+		}
+		result.Substitutes = safe
+	}
+	RankSubstitutes(result, opts.Seasonal, opts.Month)
+	return result, nil
+}
+
+// GetSubstitutes asks the model for substitutes of an ingredient, optionally in the context of a recipe
+func (s *Service) GetSubstitutes(ctx context.Context, ingredientName string, opts SubstituteOptions, lang string) (*models.GetSubstitutesResponse, error) {
+	if !s.provider.HasLLM() {
+		return nil, fmt.Errorf("没有可用的 LLM 服务，请配置 API Key")
+	}
+
+	response, err := s.callLLM(ctx, s.buildSubstitutesPrompt(ingredientName, opts, lang), lang)
+	if err != nil {
+		return nil, fmt.Errorf("获取替代食材失败: %w", err)
+	}
+
+	var raw struct {
+		Role        string `json:"role"`
+		Substitutes []struct {
+			Name  string  `json:"name"`
+			Role  string  `json:"role"`
+			Ratio float64 `json:"ratio"`
+			Notes string  `json:"notes"`
+		} `json:"substitutes"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(response)), &raw); err != nil {
+		return nil, fmt.Errorf("解析替代食材响应失败: JSON 解析失败: %w", err)
+	}
+
+	result := &models.GetSubstitutesResponse{
+		Ingredient:  ingredientName,
+		Role:        parseRole(raw.Role),
+		Substitutes: make([]models.Substitute, 0, len(raw.Substitutes)),
+	}
+	var original *models.RecipeIngredient
+	if opts.Detail != nil {
+		result.RecipeID = opts.Detail.ID
+		if original = findRecipeIngredient(opts.Detail, ingredientName); original != nil {
+			result.Amount = original.Amount
+		}
+	}
+
+	for _, sub := range raw.Substitutes {
+		name := strings.TrimSpace(sub.Name)
+		if name == "" || catalog.SameIngredient(name, ingredientName) {
+			continue
+		}
+		ratio := sub.Ratio
+		if ratio <= 0 {
+			ratio = 1
+		}
+		substitute := models.Substitute{
+			Name:  name,
+			Role:  parseRole(sub.Role),
+			Ratio: ratio,
+			Notes: sub.Notes,
+		}
+		if original != nil {
+			substitute.Amount, _ = shopping.ScaleAmount(original.Amount, ratio)
+		}
+		result.Substitutes = append(result.Substitutes, substitute)
+	}
+
+	return result, nil
+}
+
+// RankSubstitutes sorts substitutes by culinary role first (same role as the original),
+// then by how close they are to their seasonal peak; the model's order breaks ties
+func RankSubstitutes(resp *models.GetSubstitutesResponse, seasonal []models.SeasonalIngredient, month int) {
+	weights := make(map[string]float64, len(resp.Substitutes))
+	for i := range resp.Substitutes {
+		sub := &resp.Substitutes[i]
+		sub.RoleMatch = sub.Role == resp.Role && sub.Role != models.RoleOther
+		sub.InSeason = false
+		for _, ing := range seasonal {
+			if catalog.SameIngredient(ing.Name, sub.Name) {
+				if w := seasonality.PeakWeight(ing.SeasonMonths, month); w > 0 {
+					sub.InSeason = true
+					weights[sub.Name] = w
+				}
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(resp.Substitutes, func(i, j int) bool {
+		a, b := resp.Substitutes[i], resp.Substitutes[j]
+		if a.RoleMatch != b.RoleMatch {
+			return a.RoleMatch
+		}
+		return weights[a.Name] > weights[b.Name]
+	})
+}
+
+// ApplySubstitution returns a copy of a recipe detail with an ingredient replaced: the
+// amount is scaled by the substitute's ratio, and the steps, title and tips mention the
+// substitute. The copy gets its own ID so it can be cached next to the original.
+// ok is false when the recipe does not use the ingredient.
+func ApplySubstitution(detail *models.NewRecipeDetail, ingredientName string, sub models.Substitute, lang string) (*models.NewRecipeDetail, bool) {
+	original := findRecipeIngredient(detail, ingredientName)
+	if original == nil {
+		return nil, false
+	}
+	originalName := original.Name
+
+	modified := *detail
+	modified.ID = SubstitutedID(detail.ID, originalName, sub.Name)
+	modified.Title = strings.ReplaceAll(detail.Title, originalName, sub.Name)
+	modified.Tags = append([]string(nil), detail.Tags...)
+
+	modified.Ingredients = make([]models.RecipeIngredient, len(detail.Ingredients))
+	copy(modified.Ingredients, detail.Ingredients)
+	for i := range modified.Ingredients {
+		ing := &modified.Ingredients[i]
+		if ing.Name != originalName {
+			continue
+		}
+		ing.Name = sub.Name
+		ing.Amount, _ = shopping.ScaleAmount(ing.Amount, sub.Ratio)
+		ing.Note = sub.Notes
+	}
+
+	modified.Steps = make([]models.NewCookingStep, len(detail.Steps))
+	copy(modified.Steps, detail.Steps)
+	for i := range modified.Steps {
+		modified.Steps[i].Instruction = strings.ReplaceAll(modified.Steps[i].Instruction, originalName, sub.Name)
+	}
+
+	var note string
+	if lang == "en" {
+		note = fmt.Sprintf("Substitution: %s replaces %s.", sub.Name, originalName)
+	} else {
+		note = fmt.Sprintf("替换说明：用%s代替%s。", sub.Name, originalName)
+	}
+	if sub.Notes != "" {
+		if lang == "en" {
+			note += " "
+		}
+		note += sub.Notes
+	}
+	modified.Tips = strings.TrimSpace(detail.Tips + "\n" + note)
+
+	allergen.TagDetail(&modified)
+	return &modified, true
+}
+
+// SubstitutedID derives the ID of a recipe with one ingredient replaced
+func SubstitutedID(recipeID, ingredientName, substituteName string) string {
+	h := sha1.Sum([]byte(catalog.Normalize(ingredientName) + "->" + catalog.Normalize(substituteName)))
+	return recipeID + "-sub-" + hex.EncodeToString(h[:])[:8]
+}
+
+// findRecipeIngredient returns the recipe ingredient matching a name
+func findRecipeIngredient(detail *models.NewRecipeDetail, name string) *models.RecipeIngredient {
+	for i := range detail.Ingredients {
+		if catalog.SameIngredient(detail.Ingredients[i].Name, name) {
+			return &detail.Ingredients[i]
+		}
+	}
+	return nil
+}
+
+// parseRole maps the role returned by the model to a known culinary role
+func parseRole(role string) models.CulinaryRole {
+	switch r := models.CulinaryRole(strings.ToLower(strings.TrimSpace(role))); r {
+	case models.RoleAcid, models.RoleAromatic, models.RoleProtein, models.RoleThickener, models.RoleFat,
+		models.RoleSweetener, models.RoleUmami, models.RoleVegetable, models.RoleStarch:
+		return r
+	}
+	return models.RoleOther
+}
+
+// buildSubstitutesPrompt builds the prompt for ingredient substitutes
+func (s *Service) buildSubstitutesPrompt(ingredientName string, opts SubstituteOptions, lang string) string {
+	var sb strings.Builder
+
+	langInstruction := i18n.GetPrompt(lang, "language_instruction")
+	if langInstruction == "" {
+		langInstruction = "所有输出必须使用简体中文。"
+	}
+
+	seasonal := make([]string, 0, maxSeasonalHints)
+	for _, ing := range opts.Seasonal {
+		if len(seasonal) == maxSeasonalHints {
+			break
+		}
+		seasonal = append(seasonal, ing.Name)
+	}
+
+	if lang == "en" {
+		sb.WriteString("You are a professional chef who is an expert in ingredient substitutions.\n\n")
+		sb.WriteString("## Task\n")
+		sb.WriteString(fmt.Sprintf("Suggest substitutes for \"%s\".\n\n", ingredientName))
+
+		sb.WriteString("## Context\n")
+		if d := opts.Detail; d != nil {
+			sb.WriteString(fmt.Sprintf("- Recipe: %s\n", d.Title))
+			sb.WriteString(fmt.Sprintf("- Recipe ingredients: %s\n", joinRecipeIngredients(d)))
+		}
+		if len(seasonal) > 0 {
+			sb.WriteString(fmt.Sprintf("- Ingredients in season for the user: %s\n", strings.Join(seasonal, ", ")))
+		}
+		sb.WriteString("\n")
+
+		sb.WriteString("## Requirements\n")
+		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
+		sb.WriteString("2. Identify the culinary role of the ingredient (in the recipe, when given)\n")
+		sb.WriteString("3. Suggest 4-8 substitutes, preferring ones with the same role and ones in season for the user\n")
+		sb.WriteString("4. ratio: amount of substitute per unit of the original (e.g. 0.5 = half as much)\n")
+		sb.WriteString("5. notes: how to adjust quantities, timing or technique\n")
+		sb.WriteString("6. role must be one of: acid, aromatic, protein, thickener, fat, sweetener, umami, vegetable, starch, other\n\n")
+
+		sb.WriteString("## Output Format\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "role": "acid",
+  "substitutes": [
+    {"name": "substitute name", "role": "acid", "ratio": 1, "notes": "how to adjust"}
+  ]
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("\nPlease output JSON only, no additional text.")
+	} else {
+		sb.WriteString("你是一位精通食材替换的专业厨师。\n\n")
+		sb.WriteString("## 任务\n")
+		sb.WriteString(fmt.Sprintf("请为「%s」推荐替代食材。\n\n", ingredientName))
+
+		sb.WriteString("## 上下文信息\n")
+		if d := opts.Detail; d != nil {
+			sb.WriteString(fmt.Sprintf("- 菜谱：%s\n", d.Title))
+			sb.WriteString(fmt.Sprintf("- 菜谱食材：%s\n", joinRecipeIngredients(d)))
+		}
+		if len(seasonal) > 0 {
+			sb.WriteString(fmt.Sprintf("- 用户所在地的应季食材：%s\n", strings.Join(seasonal, "、")))
+		}
+		sb.WriteString("\n")
+
+		sb.WriteString("## 要求\n")
+		sb.WriteString(fmt.Sprintf("1. %s\n", langInstruction))
+		sb.WriteString("2. 判断该食材（在菜谱中）的烹饪作用\n")
+		sb.WriteString("3. 推荐 4-8 种替代食材，优先选择作用相同、且为用户所在地应季的食材\n")
+		sb.WriteString("4. ratio 为替代品与原食材的用量比例（如 0.5 表示用量减半）\n")
+		sb.WriteString("5. notes 说明用量、火候或做法需要如何调整\n")
+		sb.WriteString("6. role 只能是：acid、aromatic、protein、thickener、fat、sweetener、umami、vegetable、starch、other\n\n")
+
+		sb.WriteString("## 输出格式\n")
+		sb.WriteString("```json\n")
+		sb.WriteString(`{
+  "role": "acid",
+  "substitutes": [
+    {"name": "替代食材", "role": "acid", "ratio": 1, "notes": "调整说明"}
+  ]
+}
+`)
+		sb.WriteString("```\n")
+		sb.WriteString("\n请只输出 JSON，不要添加其他说明文字。")
+	}
+
+	return sb.String()
+}
+
+// joinRecipeIngredients lists the ingredients of a recipe with their amounts
+func joinRecipeIngredients(detail *models.NewRecipeDetail) string {
+	parts := make([]string, 0, len(detail.Ingredients))
+	for _, ing := range detail.Ingredients {
+		parts = append(parts, strings.TrimSpace(ing.Name+" "+ing.Amount))
+	}
+	return strings.Join(parts, ", ")
+}
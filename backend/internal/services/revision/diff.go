@@ -0,0 +1,150 @@
+package revision
+
+import (
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// Compare returns the differences between two versions of a recipe: changed text
+// fields, ingredients matched by name, steps aligned by instruction, and tags
+func Compare(from, to *models.NewRecipeDetail) *models.RecipeDiff {
+	diff := &models.RecipeDiff{
+		Fields:      compareFields(from, to),
+		Ingredients: compareIngredients(from.Ingredients, to.Ingredients),
+		Steps:       compareSteps(from.Steps, to.Steps),
+	}
+	diff.TagsAdded = missing(to.Tags, from.Tags)
+	diff.TagsRemoved = missing(from.Tags, to.Tags)
+	return diff
+}
+
+func compareFields(from, to *models.NewRecipeDetail) []models.FieldChange {
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"cookingTime", from.CookingTime, to.CookingTime},
+		{"servings", from.Servings, to.Servings},
+		{"difficulty", from.Difficulty, to.Difficulty},
+		{"tips", from.Tips, to.Tips},
+	}
+
+	changes := make([]models.FieldChange, 0)
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, models.FieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+// compareIngredients matches ingredients by normalized name: removed ones in their old
+// order first, then added and modified ones in their new order
+func compareIngredients(from, to []models.RecipeIngredient) []models.IngredientChange {
+	old := make(map[string]models.RecipeIngredient, len(from))
+	for _, ing := range from {
+		old[catalog.Normalize(ing.Name)] = ing
+	}
+	next := make(map[string]bool, len(to))
+	for _, ing := range to {
+		next[catalog.Normalize(ing.Name)] = true
+	}
+
+	changes := make([]models.IngredientChange, 0)
+	for _, ing := range from {
+		if !next[catalog.Normalize(ing.Name)] {
+			removed := ing
+			changes = append(changes, models.IngredientChange{Op: models.ChangeRemoved, Name: ing.Name, From: &removed})
+		}
+	}
+	for _, ing := range to {
+		added := ing
+		prev, ok := old[catalog.Normalize(ing.Name)]
+		switch {
+		case !ok:
+			changes = append(changes, models.IngredientChange{Op: models.ChangeAdded, Name: ing.Name, To: &added})
+		case prev.Amount != ing.Amount || prev.Note != ing.Note:
+			changes = append(changes, models.IngredientChange{Op: models.ChangeModified, Name: ing.Name, From: &prev, To: &added})
+		}
+	}
+	return changes
+}
+
+// compareSteps aligns steps by their longest common subsequence of instructions.
+// Between two aligned steps, removed and added steps are paired up as modified ones;
+// aligned steps whose duration changed are modified too.
+func compareSteps(from, to []models.NewCookingStep) []models.StepChange {
+	key := func(step models.NewCookingStep) string { return strings.TrimSpace(step.Instruction) }
+
+	// lcs[i][j]: from[i:] 与 to[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if key(from[i]) == key(to[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := make([]models.StepChange, 0)
+	var removed, added []models.NewCookingStep
+	flush := func() {
+		n := min(len(removed), len(added))
+		for k := 0; k < n; k++ {
+			changes = append(changes, models.StepChange{Op: models.ChangeModified, From: &removed[k], To: &added[k]})
+		}
+		for k := n; k < len(removed); k++ {
+			changes = append(changes, models.StepChange{Op: models.ChangeRemoved, From: &removed[k]})
+		}
+		for k := n; k < len(added); k++ {
+			changes = append(changes, models.StepChange{Op: models.ChangeAdded, To: &added[k]})
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && key(from[i]) == key(to[j]):
+			flush()
+			if from[i].Duration != to[j].Duration {
+				a, b := from[i], to[j]
+				changes = append(changes, models.StepChange{Op: models.ChangeModified, From: &a, To: &b})
+			}
+			i++
+			j++
+		case j < len(to) && (i == len(from) || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, to[j])
+			j++
+		default:
+			removed = append(removed, from[i])
+			i++
+		}
+	}
+	flush()
+	return changes
+}
+
+// missing returns the values of a that are not in b
+func missing(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, v := range b {
+		set[v] = true
+	}
+	var result []string
+	for _, v := range a {
+		if !set[v] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,321 @@
+// Package revision lets users edit recipes into a personal version that keeps every
+// revision, so revisions can be listed, compared and restored, and fork shared recipe
+// snapshots into personal copies
+package revision
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/allergen"
+	"github.com/eat-only-in-season/backend/internal/services/timing"
+	"github.com/eat-only-in-season/backend/internal/store"
+	"github.com/google/uuid"
+)
+
+// maxRevisions 每个菜谱最多保留的修订数
+const maxRevisions = 200
+
+var (
+	// ErrRecipeNotEdited 用户没有该菜谱的个人版本
+	ErrRecipeNotEdited = errors.New("recipe has no personal version")
+	// ErrRevisionNotFound 修订不存在
+	ErrRevisionNotFound = errors.New("revision not found")
+	// ErrRevisionConflict 编辑所基于的修订不是当前修订
+	ErrRevisionConflict = errors.New("recipe was changed since the base revision")
+	// ErrNoChanges 编辑或恢复后内容与当前修订相同
+	ErrNoChanges = errors.New("no changes")
+	// ErrRevisionsFull 修订数量达到上限
+	ErrRevisionsFull = errors.New("revisions are full")
+	// ErrInvalidRecipe 编辑后的菜谱缺少食材名称或步骤说明
+	ErrInvalidRecipe = errors.New("invalid recipe")
+)
+
+// Service provides recipe editing and revision history
+type Service struct {
+	store *store.Store
+}
+
+// NewService creates a new revision service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st}
+}
+
+// Current returns the current revision of a user's version of a recipe
+func (s *Service) Current(userID, recipeID string) (*models.UserRecipeResponse, error) {
+	recipe, detail, err := s.store.GetUserRecipe(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrRecipeNotEdited
+	}
+	if err != nil {
+		return nil, err
+	}
+	return response(recipe, detail), nil
+}
+
+// Edit applies an edit to a user's version of a recipe. The first edit of a generated
+// recipe keeps original (the generated detail, in lang) as revision 1, before the
+// edited one; later edits apply to the current revision.
+func (s *Service) Edit(userID string, original *models.NewRecipeDetail, lang string, req *models.EditRecipeRequest) (*models.UserRecipeResponse, error) {
+	base, revision := original, 0
+	recipe, current, err := s.store.GetUserRecipe(userID, original.ID)
+	switch {
+	case err == nil:
+		base, revision, lang = current, recipe.Revision, recipe.Recipe.Lang
+	case !errors.Is(err, store.ErrNotFound):
+		return nil, err
+	}
+	if req.BaseRevision > 0 && req.BaseRevision != revision {
+		return nil, ErrRevisionConflict
+	}
+	if revision >= maxRevisions {
+		return nil, ErrRevisionsFull
+	}
+
+	edited, err := apply(base, req)
+	if err != nil {
+		return nil, err
+	}
+	if equal(edited, base) {
+		return nil, ErrNoChanges
+	}
+
+	revisions := []models.RecipeRevision{{Recipe: edited, Message: strings.TrimSpace(req.Message)}}
+	if revision == 0 {
+		initial := *original
+		initial.Steps = append([]models.NewCookingStep(nil), original.Steps...)
+		timing.Annotate(&initial)
+		revisions = append([]models.RecipeRevision{{Recipe: &initial}}, revisions...)
+	}
+	recipe, err = s.store.AddRecipeRevisions(userID, lang, "", revisions...)
+	if err != nil {
+		return nil, err
+	}
+	return response(recipe, edited), nil
+}
+
+// Fork creates a personal copy of a shared recipe snapshot under a new recipe id
+func (s *Service) Fork(userID string, snapshot *models.SharedRecipe) (*models.UserRecipeResponse, error) {
+	detail := snapshot.Recipe
+	detail.ID = uuid.New().String()
+
+	recipe, err := s.store.AddRecipeRevisions(userID, snapshot.Lang, snapshot.Slug, models.RecipeRevision{Recipe: &detail})
+	if err != nil {
+		return nil, err
+	}
+	return response(recipe, &detail), nil
+}
+
+// List returns the recipes a user edited or forked
+func (s *Service) List(userID string) (*models.ListUserRecipesResponse, error) {
+	recipes, err := s.store.ListUserRecipes(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListUserRecipesResponse{Recipes: recipes}, nil
+}
+
+// Revisions returns the revision history of a user's version of a recipe
+func (s *Service) Revisions(userID, recipeID string) (*models.ListRevisionsResponse, error) {
+	recipe, _, err := s.store.GetUserRecipe(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrRecipeNotEdited
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.store.ListRecipeRevisions(userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ListRevisionsResponse{RecipeID: recipeID, Current: recipe.Revision, Revisions: revisions}, nil
+}
+
+// Revision returns one revision of a user's version of a recipe with its detail
+func (s *Service) Revision(userID, recipeID string, revision int) (*models.RecipeRevision, error) {
+	rev, err := s.store.GetRecipeRevision(userID, recipeID, revision)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// Diff compares two revisions; from defaults to the revision before to, and to
+// defaults to the current revision
+func (s *Service) Diff(userID, recipeID string, from, to int) (*models.RecipeDiff, error) {
+	if to <= 0 {
+		current, err := s.Current(userID, recipeID)
+		if err != nil {
+			return nil, err
+		}
+		to = current.Revision
+	}
+	if from <= 0 {
+		from = max(to-1, 1)
+	}
+
+	a, err := s.Revision(userID, recipeID, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.Revision(userID, recipeID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := Compare(a.Recipe, b.Recipe)
+	diff.RecipeID = recipeID
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+// Restore creates a new revision with the content of an earlier one
+func (s *Service) Restore(userID, recipeID string, revision int) (*models.UserRecipeResponse, error) {
+	recipe, current, err := s.store.GetUserRecipe(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrRecipeNotEdited
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recipe.Revision >= maxRevisions {
+		return nil, ErrRevisionsFull
+	}
+	rev, err := s.Revision(userID, recipeID, revision)
+	if err != nil {
+		return nil, err
+	}
+	if equal(rev.Recipe, current) {
+		return nil, ErrNoChanges
+	}
+
+	recipe, err = s.store.AddRecipeRevisions(userID, recipe.Recipe.Lang, recipe.ForkedFrom,
+		models.RecipeRevision{Recipe: rev.Recipe, RestoredFrom: revision})
+	if err != nil {
+		return nil, err
+	}
+	return response(recipe, rev.Recipe), nil
+}
+
+// Discard deletes a user's version of a recipe with its history; an edited generated
+// recipe falls back to the generated detail, a fork is gone
+func (s *Service) Discard(userID, recipeID string) error {
+	err := s.store.DeleteUserRecipe(userID, recipeID)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrRecipeNotEdited
+	}
+	return err
+}
+
+// apply returns a copy of base with the edit applied, re-deriving step numbers,
+// durations and allergens from the edited content
+func apply(base *models.NewRecipeDetail, req *models.EditRecipeRequest) (*models.NewRecipeDetail, error) {
+	edited := *base
+	setText(&edited.Title, req.Title)
+	setText(&edited.Description, req.Description)
+	setText(&edited.CookingTime, req.CookingTime)
+	setText(&edited.Servings, req.Servings)
+	setText(&edited.Difficulty, req.Difficulty)
+	setText(&edited.Tips, req.Tips)
+
+	if req.Ingredients != nil {
+		edited.Ingredients = make([]models.RecipeIngredient, 0, len(req.Ingredients))
+		for _, ing := range req.Ingredients {
+			name := strings.TrimSpace(ing.Name)
+			if name == "" {
+				return nil, ErrInvalidRecipe
+			}
+			edited.Ingredients = append(edited.Ingredients, models.RecipeIngredient{
+				Name:   name,
+				Amount: strings.TrimSpace(ing.Amount),
+				Note:   strings.TrimSpace(ing.Note),
+			})
+		}
+	} else {
+		edited.Ingredients = append([]models.RecipeIngredient(nil), base.Ingredients...)
+	}
+
+	if req.Steps != nil {
+		edited.Steps = make([]models.NewCookingStep, 0, len(req.Steps))
+		for _, step := range req.Steps {
+			instruction := strings.TrimSpace(step.Instruction)
+			if instruction == "" {
+				return nil, ErrInvalidRecipe
+			}
+			edited.Steps = append(edited.Steps, models.NewCookingStep{
+				StepNumber:  len(edited.Steps) + 1,
+				Instruction: instruction,
+				Duration:    strings.TrimSpace(step.Duration),
+			})
+		}
+	} else {
+		edited.Steps = append([]models.NewCookingStep(nil), base.Steps...)
+	}
+
+	if req.Tags != nil {
+		edited.Tags = make([]string, 0, len(req.Tags))
+		for _, tag := range req.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				edited.Tags = append(edited.Tags, tag)
+			}
+		}
+	}
+
+	if edited.Title == "" || len(edited.Ingredients) == 0 || len(edited.Steps) == 0 {
+		return nil, ErrInvalidRecipe
+	}
+
+	timing.Annotate(&edited)
+	allergen.TagDetail(&edited)
+	return &edited, nil
+}
+
+func setText(field *string, value *string) {
+	if value != nil {
+		*field = strings.TrimSpace(*value)
+	}
+}
+
+// equal reports whether two recipe details have the same user-editable content;
+// derived fields (durations, allergens) are ignored
+func equal(a, b *models.NewRecipeDetail) bool {
+	x, errX := json.Marshal(editable(a))
+	y, errY := json.Marshal(editable(b))
+	return errX == nil && errY == nil && string(x) == string(y)
+}
+
+// editable returns a copy of the user-editable content of a recipe detail
+func editable(d *models.NewRecipeDetail) models.EditRecipeRequest {
+	req := models.EditRecipeRequest{
+		Title:       &d.Title,
+		Description: &d.Description,
+		CookingTime: &d.CookingTime,
+		Servings:    &d.Servings,
+		Difficulty:  &d.Difficulty,
+		Tips:        &d.Tips,
+		Tags:        d.Tags,
+	}
+	for _, ing := range d.Ingredients {
+		req.Ingredients = append(req.Ingredients, models.RecipeIngredient{Name: ing.Name, Amount: ing.Amount, Note: ing.Note})
+	}
+	for _, step := range d.Steps {
+		req.Steps = append(req.Steps, models.NewCookingStep{Instruction: step.Instruction, Duration: step.Duration})
+	}
+	if len(req.Tags) == 0 {
+		req.Tags = nil
+	}
+	return req
+}
+
+func response(recipe *models.UserRecipe, detail *models.NewRecipeDetail) *models.UserRecipeResponse {
+	return &models.UserRecipeResponse{
+		Recipe:     *detail,
+		Revision:   recipe.Revision,
+		ForkedFrom: recipe.ForkedFrom,
+		UpdatedAt:  recipe.UpdatedAt,
+	}
+}
@@ -0,0 +1,207 @@
+// Package search provides full-text search over the recipes in the recipe library,
+// using an SQLite FTS5 index of titles, descriptions, ingredients, steps and tags.
+// Chinese text is indexed as single characters and character pairs, English as words.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/recipe"
+	"github.com/eat-only-in-season/backend/internal/services/season"
+	"github.com/eat-only-in-season/backend/internal/services/timing"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+const (
+	// defaultLimit 默认返回的结果数
+	defaultLimit = 20
+	// maxLimit 单次最多返回的结果数
+	maxLimit = 50
+	// syncBatch 每批建立索引的菜谱数
+	syncBatch = 200
+)
+
+// ErrEmptyQuery 查询中没有可搜索的文字
+var ErrEmptyQuery = errors.New("query has no searchable terms")
+
+// Query 搜索条件，过滤条件为空时不过滤
+type Query struct {
+	Text    string
+	Cuisine models.Cuisine
+	// Difficulty 难度代码（easy、medium、hard）
+	Difficulty string
+	MaxMinutes int
+	Season     models.SeasonID
+	Limit      int
+	Offset     int
+}
+
+// Service provides recipe search
+type Service struct {
+	store   *store.Store
+	seasons *season.Calculator
+	// mutex 同一时间只进行一次索引同步
+	mutex sync.Mutex
+}
+
+// NewService creates a new search service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st, seasons: season.NewCalculator()}
+}
+
+// Sync indexes the recipes added to or updated in the recipe library since the last
+// sync and returns how many were indexed. Search syncs first, so the index never lags.
+// A cancelled ctx stops the sync between batches.
+func (s *Service) Sync(ctx context.Context) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	indexed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return indexed, err
+		}
+		recipes, err := s.store.UnindexedRecipes(syncBatch)
+		if err != nil {
+			return indexed, err
+		}
+		if len(recipes) == 0 {
+			return indexed, nil
+		}
+
+		docs := make([]store.SearchDocument, 0, len(recipes))
+		for _, r := range recipes {
+			docs = append(docs, s.document(r))
+		}
+		if err := s.store.IndexRecipes(docs); err != nil {
+			return indexed, err
+		}
+		indexed += len(docs)
+	}
+}
+
+// SyncInBackground indexes the recipe library on startup, until done or ctx is
+// cancelled (on shutdown, before the store is closed). It blocks; run it in a goroutine.
+func (s *Service) SyncInBackground(ctx context.Context) {
+	n, err := s.Sync(ctx)
+	switch {
+	case ctx.Err() != nil:
+		log.Printf("[SearchService] 索引同步已停止，已为 %d 道菜谱建立索引", n)
+	case err != nil:
+		log.Printf("[SearchService] 索引同步失败: %v", err)
+	case n > 0:
+		log.Printf("[SearchService] 已为 %d 道菜谱建立索引", n)
+	}
+}
+
+// Search returns the recipes matching a query, most relevant first
+func (s *Service) Search(q Query) (*models.SearchRecipesResponse, error) {
+	match := matchExpression(q.Text)
+	if match == "" {
+		return nil, ErrEmptyQuery
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	q.Limit = min(q.Limit, maxLimit)
+
+	// 索引同步失败时仍按已有索引搜索
+	if _, err := s.Sync(context.Background()); err != nil {
+		log.Printf("[SearchService] 索引同步失败: %v", err)
+	}
+
+	hits, total, err := s.store.SearchRecipes(store.SearchFilter{
+		Match:      match,
+		Cuisine:    string(q.Cuisine),
+		Difficulty: q.Difficulty,
+		Season:     string(q.Season),
+		MaxSeconds: q.MaxMinutes * 60,
+		Limit:      q.Limit,
+		Offset:     max(q.Offset, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.RecipeSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, models.RecipeSearchResult{
+			Recipe:             hit.Recipe,
+			CookingTimeSeconds: hit.CookingSeconds,
+			Season:             models.SeasonID(hit.Season),
+			Score:              -hit.Rank,
+		})
+	}
+	return &models.SearchRecipesResponse{Query: q.Text, Total: total, Results: results}, nil
+}
+
+// ParseSeason parses a season filter given as its ID or its Chinese name
+func ParseSeason(name string) (models.SeasonID, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "fall" {
+		return models.SeasonAutumn, true
+	}
+	for id, zh := range season.SeasonNames {
+		if name == string(id) || name == zh || name == strings.TrimSuffix(zh, "季") {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// ParseDifficulty parses a difficulty filter given as its code
+func ParseDifficulty(name string) (string, bool) {
+	switch code := strings.ToLower(strings.TrimSpace(name)); code {
+	case "easy", "medium", "hard":
+		return code, true
+	}
+	return "", false
+}
+
+// document builds the index document of a stored recipe. A recipe that cannot be
+// decoded is indexed without text, so it is not retried on every sync.
+func (s *Service) document(r store.StoredRecipe) store.SearchDocument {
+	doc := store.SearchDocument{
+		RecipeID:        r.ID,
+		Lang:            r.Lang,
+		Season:          string(s.seasons.GetSeason(0, r.GeneratedAt).ID),
+		SourceUpdatedAt: r.UpdatedAt,
+	}
+
+	var detail models.NewRecipeDetail
+	if err := json.Unmarshal([]byte(r.Data), &detail); err != nil {
+		log.Printf("[SearchService] 菜谱 %s 解析失败，跳过索引: %v", r.ID, err)
+		return doc
+	}
+
+	doc.Cuisine = string(detail.Cuisine)
+	if detail.Difficulty != "" {
+		doc.Difficulty = recipe.DifficultyCode(detail.Difficulty)
+	}
+	doc.CookingSeconds = detail.CookingTimeSeconds
+	if doc.CookingSeconds == 0 {
+		doc.CookingSeconds, _ = timing.Parse(detail.CookingTime)
+	}
+
+	ingredients := make([]string, 0, len(detail.Ingredients))
+	for _, ing := range detail.Ingredients {
+		ingredients = append(ingredients, ing.Name, ing.Note)
+	}
+	steps := make([]string, 0, len(detail.Steps))
+	for _, step := range detail.Steps {
+		steps = append(steps, step.Instruction)
+	}
+
+	doc.Title = indexText(detail.Title)
+	doc.Description = indexText(detail.Description)
+	doc.Ingredients = indexText(ingredients...)
+	doc.Steps = indexText(steps...)
+	doc.Tags = indexText(detail.Tags...)
+	return doc
+}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxQueryTerms 查询最多使用的词项数
+const maxQueryTerms = 32
+
+// segment 一段连续的中日韩文字，或一段连续的其他字母和数字
+type segment struct {
+	runes []rune
+	cjk   bool
+}
+
+// isCJK reports whether r belongs to a script written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// segments splits lower-cased text into runs of CJK characters and runs of other
+// letters and digits; everything else separates runs
+func segments(text string) []segment {
+	var result []segment
+	var current segment
+	flush := func() {
+		if len(current.runes) > 0 {
+			result = append(result, current)
+		}
+		current = segment{}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			if !current.cjk {
+				flush()
+				current.cjk = true
+			}
+			current.runes = append(current.runes, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if current.cjk {
+				flush()
+			}
+			current.runes = append(current.runes, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return result
+}
+
+// indexText returns the space-separated tokens indexed for texts: words as they are
+// (the FTS5 tokenizer stems English ones), and every character and every pair of
+// adjacent characters of CJK runs, so queries of any length match without a dictionary
+func indexText(texts ...string) string {
+	var tokens []string
+	for _, text := range texts {
+		for _, seg := range segments(text) {
+			if !seg.cjk {
+				tokens = append(tokens, string(seg.runes))
+				continue
+			}
+			for i := range seg.runes {
+				tokens = append(tokens, string(seg.runes[i]))
+				if i+1 < len(seg.runes) {
+					tokens = append(tokens, string(seg.runes[i:i+2]))
+				}
+			}
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// matchExpression builds an FTS5 query matching documents that contain every term of
+// q: its words, the last one as a prefix (search as you type), and the pairs of
+// adjacent characters of CJK runs (the character itself for a single one).
+// It returns "" when q has no searchable terms.
+func matchExpression(q string) string {
+	segs := segments(q)
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] && len(terms) < maxQueryTerms {
+			seen[term] = true
+			terms = append(terms, `"`+term+`"`)
+		}
+	}
+
+	for i, seg := range segs {
+		switch {
+		case !seg.cjk && i == len(segs)-1:
+			term := string(seg.runes)
+			if !seen[term] && len(terms) < maxQueryTerms {
+				seen[term] = true
+				terms = append(terms, `"`+term+`"*`)
+			}
+		case !seg.cjk:
+			add(string(seg.runes))
+		case len(seg.runes) == 1:
+			add(string(seg.runes))
+		default:
+			for j := 0; j+1 < len(seg.runes); j++ {
+				add(string(seg.runes[j : j+2]))
+			}
+		}
+	}
+	return strings.Join(terms, " ")
+}
@@ -2,6 +2,7 @@
 package season
 
 import (
+	"math"
 	"time"
 
 	"github.com/eat-only-in-season/backend/internal/models"
@@ -110,3 +111,15 @@ func (c *Calculator) invertSeason(season models.SeasonID) models.SeasonID {
 func (c *Calculator) GetCurrentSeason(latitude float64) models.Season {
 	return c.GetSeason(latitude, time.Now())
 }
+
+// LocalTime converts now to the local time of a city. The city's time zone is used
+// when known, otherwise the offset is estimated from its longitude (15° per hour).
+func (c *Calculator) LocalTime(city *models.City, now time.Time) time.Time {
+	if city.Timezone != "" {
+		if loc, err := time.LoadLocation(city.Timezone); err == nil {
+			return now.In(loc)
+		}
+	}
+	offset := int(math.Round(city.Longitude/15)) * 3600
+	return now.In(time.FixedZone("", offset))
+}
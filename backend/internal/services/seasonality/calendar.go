@@ -0,0 +1,106 @@
+package seasonality
+
+import (
+	"sort"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// peakThreshold 未给出高峰月份时，PeakWeight 不低于该值的月份视为高峰
+const peakThreshold = 0.75
+
+// NormalizeMonths drops invalid and duplicate months and sorts the rest
+func NormalizeMonths(months []int) []int {
+	seen := make(map[int]bool, len(months))
+	result := make([]int, 0, len(months))
+	for _, m := range months {
+		if m < 1 || m > 12 || seen[m] {
+			continue
+		}
+		seen[m] = true
+		result = append(result, m)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// PeakMonths derives the peak months of a season from its middle
+func PeakMonths(months []int) []int {
+	peak := make([]int, 0)
+	if len(months) == 12 {
+		return peak // 全年供应，没有高峰
+	}
+	for _, m := range months {
+		if PeakWeight(months, m) >= peakThreshold {
+			peak = append(peak, m)
+		}
+	}
+	return peak
+}
+
+// Calendar builds the year-round calendar of an ingredient. Peak months outside of
+// the season are dropped; without peak months they are derived from the season.
+func Calendar(name string, category models.IngredientCategory, months, peak []int) models.IngredientCalendar {
+	months = NormalizeMonths(months)
+	inSeason := make(map[int]bool, len(months))
+	for _, m := range months {
+		inSeason[m] = true
+	}
+
+	peakMonths := make([]int, 0, len(peak))
+	for _, m := range NormalizeMonths(peak) {
+		if inSeason[m] {
+			peakMonths = append(peakMonths, m)
+		}
+	}
+	if len(peakMonths) == 0 {
+		peakMonths = PeakMonths(months)
+	}
+	isPeak := make(map[int]bool, len(peakMonths))
+	for _, m := range peakMonths {
+		isPeak[m] = true
+	}
+
+	profile := make([]models.MonthAvailability, 0, 12)
+	for m := 1; m <= 12; m++ {
+		availability := models.AvailabilityNone
+		switch {
+		case isPeak[m]:
+			availability = models.AvailabilityPeak
+		case inSeason[m]:
+			availability = models.AvailabilityAvailable
+		}
+		profile = append(profile, models.MonthAvailability{Month: m, Availability: availability})
+	}
+
+	return models.IngredientCalendar{
+		Name:       name,
+		Category:   category,
+		Months:     months,
+		PeakMonths: peakMonths,
+		Profile:    profile,
+	}
+}
+
+// Grid lists, for each month of the year, the ingredients at peak and otherwise available
+func Grid(ingredients []models.IngredientCalendar) []models.CalendarMonth {
+	grid := make([]models.CalendarMonth, 12)
+	for i := range grid {
+		grid[i] = models.CalendarMonth{Month: i + 1, Peak: make([]string, 0), Available: make([]string, 0)}
+	}
+	for _, ing := range ingredients {
+		for _, p := range ing.Profile {
+			if p.Month < 1 || p.Month > 12 {
+				continue
+			}
+			month := &grid[p.Month-1]
+			switch p.Availability {
+			case models.AvailabilityPeak:
+				month.Peak = append(month.Peak, ing.Name)
+			case models.AvailabilityAvailable:
+				month.Available = append(month.Available, ing.Name)
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,127 @@
+package seasonality
+
+import (
+	"sort"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// Window returns the first and last day of the season containing date.
+// ok is false when date is out of season or the ingredient is available all year.
+func Window(months []int, date time.Time) (start, end time.Time, ok bool) {
+	in := monthSet(months)
+	month := int(date.Month())
+	if len(in) == 12 || !in[month] {
+		return time.Time{}, time.Time{}, false
+	}
+
+	before, after := 0, 0
+	for in[wrap(month-before-1)] {
+		before++
+	}
+	for in[wrap(month+after+1)] {
+		after++
+	}
+	start = time.Date(date.Year(), time.Month(month-before), 1, 0, 0, 0, 0, date.Location())
+	end = time.Date(date.Year(), time.Month(month+after+1), 1, 0, 0, 0, 0, date.Location()).AddDate(0, 0, -1)
+	return start, end, true
+}
+
+// NextStart returns the first day of the next season starting after date
+func NextStart(months []int, date time.Time) (time.Time, bool) {
+	in := monthSet(months)
+	if len(in) == 0 || len(in) == 12 {
+		return time.Time{}, false
+	}
+	month := int(date.Month())
+	for k := 1; k <= 12; k++ {
+		m := wrap(month + k)
+		if in[m] && !in[wrap(m-1)] {
+			return time.Date(date.Year(), time.Month(month+k), 1, 0, 0, 0, 0, date.Location()), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Phase classifies where date falls within the season: the first third of the
+// season is arriving, the last third ending and the middle the peak. It is empty
+// out of season and for ingredients available all year.
+func Phase(months []int, date time.Time) models.SeasonPhase {
+	start, end, ok := Window(months, date)
+	if !ok {
+		return ""
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	progress := (day.Sub(start).Hours()/24 + 0.5) / (end.Sub(start).Hours()/24 + 1)
+	switch {
+	case progress < 1.0/3:
+		return models.PhaseArriving
+	case progress > 2.0/3:
+		return models.PhaseEnding
+	default:
+		return models.PhasePeak
+	}
+}
+
+// DaysBetween returns the number of calendar days from a to b
+func DaysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func monthSet(months []int) map[int]bool {
+	in := make(map[int]bool, len(months))
+	for _, m := range NormalizeMonths(months) {
+		in[m] = true
+	}
+	return in
+}
+
+// AnnotatePhases sets the season phase of each seasonal ingredient for date
+func AnnotatePhases(groups []models.IngredientCategoryGroup, date time.Time) {
+	for i := range groups {
+		for j := range groups[i].Ingredients {
+			ing := &groups[i].Ingredients[j]
+			ing.Phase = Phase(ing.SeasonMonths, date)
+		}
+	}
+}
+
+// Transitions lists the ingredients whose season starts (arriving) or ends (leaving)
+// within the given number of weeks after date, soonest first
+func Transitions(ingredients []models.IngredientCalendar, date time.Time, weeks int) (arriving, leaving []models.SeasonTransition) {
+	arriving = make([]models.SeasonTransition, 0)
+	leaving = make([]models.SeasonTransition, 0)
+	horizon := weeks * 7
+
+	for _, ing := range ingredients {
+		if _, end, ok := Window(ing.Months, date); ok {
+			if days := DaysBetween(date, end); days <= horizon {
+				leaving = append(leaving, transition(ing, end, days))
+			}
+			// 当前应季期内不会再有新的上市
+			continue
+		}
+		if start, ok := NextStart(ing.Months, date); ok {
+			if days := DaysBetween(date, start); days <= horizon {
+				arriving = append(arriving, transition(ing, start, days))
+			}
+		}
+	}
+
+	sort.SliceStable(arriving, func(i, j int) bool { return arriving[i].InDays < arriving[j].InDays })
+	sort.SliceStable(leaving, func(i, j int) bool { return leaving[i].InDays < leaving[j].InDays })
+	return arriving, leaving
+}
+
+func transition(ing models.IngredientCalendar, date time.Time, days int) models.SeasonTransition {
+	return models.SeasonTransition{
+		Name:     ing.Name,
+		Category: ing.Category,
+		Date:     date.Format("2006-01-02"),
+		InDays:   days,
+		Months:   ing.Months,
+	}
+}
@@ -0,0 +1,129 @@
+// Package seasonality verifies which recipe ingredients are in season and
+// scores recipes by how much of them is in season and near its peak
+package seasonality
+
+import (
+	"math"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+const (
+	// edgeWeight 应季期首尾月份的权重，应季期中间（高峰）为 1
+	edgeWeight = 0.5
+	// unknownWeight 缺少应季月份信息的应季食材权重
+	unknownWeight = 0.75
+)
+
+// PeakWeight rates how close month is to the peak of a season given by its months:
+// 1 in the middle of the season, falling to 0.5 at its first and last month, and 0
+// outside of it. Seasons may wrap around the new year (e.g. 11, 12, 1, 2);
+// year-round ingredients have no peak and always weigh 0.5.
+func PeakWeight(months []int, month int) float64 {
+	if len(months) == 0 {
+		return unknownWeight
+	}
+	in := make(map[int]bool, len(months))
+	for _, m := range months {
+		in[m] = true
+	}
+	if !in[month] {
+		return 0
+	}
+
+	// 向前、向后数连续的应季月份
+	before, after := 0, 0
+	for before < 11 && in[wrap(month-before-1)] {
+		before++
+	}
+	for after < 11-before && in[wrap(month+after+1)] {
+		after++
+	}
+	half := float64(before+after) / 2
+	if half == 0 {
+		return 1
+	}
+	distance := math.Abs(float64(before-after)) / 2
+	return 1 - (1-edgeWeight)*distance/half
+}
+
+// wrap maps a month offset back into 1-12
+func wrap(month int) int {
+	return ((month-1)%12+12)%12 + 1
+}
+
+// Score recomputes the matched and in-season ingredients of a recommended recipe
+// and sets its seasonality score: the peak-weighted share of its ingredients
+// (basic seasonings excluded) that are in season.
+//
+// Ingredients selected by the user count as in season, since the selection is made
+// from the seasonal list; their weight comes from the list when they appear in it.
+// When the model left the ingredient list empty, the selected ingredients it reported
+// as matched are scored instead, so the recipe is not ranked as out of season.
+func Score(r *models.RecipeWithMatch, selected []string, seasonal []models.SeasonalIngredient, month int) {
+	ingredients := r.Ingredients
+	if len(ingredients) == 0 {
+		ingredients = make([]string, 0, len(r.MatchedIngredients))
+		for _, ing := range r.MatchedIngredients {
+			if containsSame(selected, ing) && !containsSame(ingredients, ing) {
+				ingredients = append(ingredients, ing)
+			}
+		}
+	}
+	r.MatchedIngredients = make([]string, 0)
+	r.SeasonalIngredients = make([]string, 0)
+
+	total, weight := 0, 0.0
+	for _, ing := range ingredients {
+		if catalog.IsStaple(ing) {
+			continue
+		}
+		total++
+
+		isSelected := containsSame(selected, ing)
+		if isSelected {
+			r.MatchedIngredients = append(r.MatchedIngredients, ing)
+		}
+
+		w := 0.0
+		if item, ok := find(seasonal, ing); ok {
+			w = PeakWeight(item.SeasonMonths, month)
+			// 用户从应季列表中选择的食材，月份信息不准时仍按应季处理
+			if w == 0 && isSelected {
+				w = edgeWeight
+			}
+		} else if isSelected {
+			w = unknownWeight
+		}
+		if w > 0 {
+			r.SeasonalIngredients = append(r.SeasonalIngredients, ing)
+			weight += w
+		}
+	}
+	r.MatchCount = len(r.MatchedIngredients)
+
+	r.SeasonalityScore = 0
+	if total > 0 {
+		r.SeasonalityScore = math.Round(weight/float64(total)*100) / 100
+	}
+}
+
+// find returns the seasonal ingredient matching an ingredient name
+func find(seasonal []models.SeasonalIngredient, name string) (*models.SeasonalIngredient, bool) {
+	for i := range seasonal {
+		if catalog.SameIngredient(seasonal[i].Name, name) {
+			return &seasonal[i], true
+		}
+	}
+	return nil, false
+}
+
+func containsSame(list []string, name string) bool {
+	for _, x := range list {
+		if catalog.SameIngredient(x, name) {
+			return true
+		}
+	}
+	return false
+}
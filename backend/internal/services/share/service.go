@@ -0,0 +1,209 @@
+// Package share publishes immutable, content-addressed snapshots of recipe details
+// (with their image and recommendation context) under short slugs, so shared links
+// keep working regardless of cache expiry
+package share
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/store"
+)
+
+const (
+	// minSlugLength 短链接的最小长度（50 位），冲突时逐步加长
+	minSlugLength = 10
+	// maxImageBytes 快照中保存的图片大小上限
+	maxImageBytes = 10 << 20
+	// imageFetchTimeout 下载图片的超时时间
+	imageFetchTimeout = 30 * time.Second
+)
+
+var (
+	// ErrSnapshotNotFound 分享快照不存在
+	ErrSnapshotNotFound = errors.New("shared recipe not found")
+	// ErrImageNotFound 分享快照没有图片
+	ErrImageNotFound = errors.New("shared image not found")
+)
+
+// slugEncoding 小写、无填充的 base32，适合放在链接中
+var slugEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Image 快照中保存的菜谱图片
+type Image struct {
+	Data        []byte
+	ContentType string
+}
+
+// Service provides recipe sharing functionality
+type Service struct {
+	store  *store.Store
+	client *http.Client
+}
+
+// NewService creates a new share service
+func NewService(st *store.Store) *Service {
+	return &Service{store: st, client: &http.Client{Timeout: imageFetchTimeout}}
+}
+
+// ImagePath returns the API path serving the image of a snapshot
+func ImagePath(slug string) string {
+	return "/api/v1/shared/" + slug + "/image"
+}
+
+// Publish freezes a recipe detail, the context it was recommended in (optional) and its
+// image (optional) into a snapshot. Publishing the same content again returns the
+// existing snapshot with created set to false.
+func (s *Service) Publish(detail *models.NewRecipeDetail, rc *models.RecommendationContext, img *Image, lang, userID string) (*models.SharedRecipe, bool, error) {
+	snapshot := &models.SharedRecipe{Recipe: *detail, Context: rc, Lang: lang}
+	// 图片服务返回的地址会过期，快照只引用自己保存的图片
+	snapshot.Recipe.ImageUrl = ""
+
+	hash, err := contentHash(snapshot, img)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing, err := s.store.GetSharedRecipeByHash(hash); err == nil {
+		return existing, false, nil
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, false, err
+	}
+
+	slug, err := s.freeSlug(hash)
+	if err != nil {
+		return nil, false, err
+	}
+	snapshot.Slug = slug
+	snapshot.ContentHash = hash
+	snapshot.PublishedAt = time.Now()
+
+	var data []byte
+	var contentType string
+	if img != nil {
+		data, contentType = img.Data, img.ContentType
+		snapshot.ImageURL = ImagePath(slug)
+		snapshot.Recipe.ImageUrl = snapshot.ImageURL
+	}
+
+	if err := s.store.SaveSharedRecipe(snapshot, data, contentType, userID); err != nil {
+		// 并发发布了相同内容
+		if errors.Is(err, store.ErrConflict) {
+			if existing, err := s.store.GetSharedRecipeByHash(hash); err == nil {
+				return existing, false, nil
+			}
+		}
+		return nil, false, err
+	}
+	return snapshot, true, nil
+}
+
+// Get returns a published snapshot
+func (s *Service) Get(slug string) (*models.SharedRecipe, error) {
+	snapshot, err := s.store.GetSharedRecipe(strings.ToLower(slug))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrSnapshotNotFound
+	}
+	return snapshot, err
+}
+
+// Image returns the image saved with a snapshot
+func (s *Service) Image(slug string) (*Image, error) {
+	data, contentType, err := s.store.GetSharedImage(strings.ToLower(slug))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Image{Data: data, ContentType: contentType}, nil
+}
+
+// FetchImage downloads an image to be saved with a snapshot
+func (s *Service) FetchImage(ctx context.Context, url string) (*Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("获取图片失败，状态码: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("图片超过 %d MB", maxImageBytes>>20)
+	}
+	return newImage(data, resp.Header.Get("Content-Type"))
+}
+
+// DecodeImage decodes a base64 image as kept in the image cache
+func DecodeImage(encoded string) (*Image, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return newImage(data, "")
+}
+
+func newImage(data []byte, contentType string) (*Image, error) {
+	if contentType == "" || !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("不是有效的图片: %s", contentType)
+	}
+	return &Image{Data: data, ContentType: contentType}, nil
+}
+
+// freeSlug returns the shortest prefix (at least minSlugLength) of the encoded content
+// hash that is not taken by another snapshot
+func (s *Service) freeSlug(hash string) (string, error) {
+	raw, err := hex.DecodeString(hash)
+	if err != nil {
+		return "", err
+	}
+	encoded := slugEncoding.EncodeToString(raw)
+	for n := minSlugLength; n <= len(encoded); n += 2 {
+		taken, err := s.store.SharedRecipeExists(encoded[:n])
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return encoded[:n], nil
+		}
+	}
+	return "", fmt.Errorf("无法为快照分配短链接")
+}
+
+// contentHash 快照内容（菜谱、上下文、语言）与图片字节的 SHA-256 摘要
+func contentHash(snapshot *models.SharedRecipe, img *Image) (string, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	if img != nil {
+		h.Write([]byte{0})
+		h.Write(img.Data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,310 @@
+package shopping
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// unitKind 计量单位的类别，同类别的用量才能相加
+type unitKind string
+
+const (
+	kindMass   unitKind = "mass"   // 基准单位：克
+	kindVolume unitKind = "volume" // 基准单位：毫升
+	kindCount  unitKind = "count"  // 计数单位，只有同一单位才能相加
+)
+
+// unitDef 单位定义
+type unitDef struct {
+	kind   unitKind
+	factor float64 // 换算为基准单位的系数
+}
+
+// units 已知的质量和体积单位
+var units = map[string]unitDef{
+	"g":           {kind: kindMass, factor: 1},
+	"gram":        {kind: kindMass, factor: 1},
+	"grams":       {kind: kindMass, factor: 1},
+	"克":           {kind: kindMass, factor: 1},
+	"kg":          {kind: kindMass, factor: 1000},
+	"kilogram":    {kind: kindMass, factor: 1000},
+	"kilograms":   {kind: kindMass, factor: 1000},
+	"千克":          {kind: kindMass, factor: 1000},
+	"公斤":          {kind: kindMass, factor: 1000},
+	"斤":           {kind: kindMass, factor: 500},
+	"两":           {kind: kindMass, factor: 50},
+	"lb":          {kind: kindMass, factor: 453.6},
+	"lbs":         {kind: kindMass, factor: 453.6},
+	"pound":       {kind: kindMass, factor: 453.6},
+	"pounds":      {kind: kindMass, factor: 453.6},
+	"oz":          {kind: kindMass, factor: 28.35},
+	"ounce":       {kind: kindMass, factor: 28.35},
+	"ounces":      {kind: kindMass, factor: 28.35},
+	"ml":          {kind: kindVolume, factor: 1},
+	"毫升":          {kind: kindVolume, factor: 1},
+	"l":           {kind: kindVolume, factor: 1000},
+	"升":           {kind: kindVolume, factor: 1000},
+	"liter":       {kind: kindVolume, factor: 1000},
+	"liters":      {kind: kindVolume, factor: 1000},
+	"litre":       {kind: kindVolume, factor: 1000},
+	"litres":      {kind: kindVolume, factor: 1000},
+	"tbsp":        {kind: kindVolume, factor: 15},
+	"tablespoon":  {kind: kindVolume, factor: 15},
+	"tablespoons": {kind: kindVolume, factor: 15},
+	"汤匙":          {kind: kindVolume, factor: 15},
+	"大勺":          {kind: kindVolume, factor: 15},
+	"汤勺":          {kind: kindVolume, factor: 15},
+	"勺":           {kind: kindVolume, factor: 15},
+	"tsp":         {kind: kindVolume, factor: 5},
+	"teaspoon":    {kind: kindVolume, factor: 5},
+	"teaspoons":   {kind: kindVolume, factor: 5},
+	"茶匙":          {kind: kindVolume, factor: 5},
+	"小勺":          {kind: kindVolume, factor: 5},
+	"cup":         {kind: kindVolume, factor: 240},
+	"cups":        {kind: kindVolume, factor: 240},
+	"杯":           {kind: kindVolume, factor: 240},
+}
+
+// countAliases 英文计数单位的复数 -> 单数
+var countAliases = map[string]string{
+	"pcs":     "pc",
+	"pieces":  "piece",
+	"cloves":  "clove",
+	"slices":  "slice",
+	"stalks":  "stalk",
+	"bunches": "bunch",
+	"heads":   "head",
+	"cans":    "can",
+	"sprigs":  "sprig",
+	"leaves":  "leaf",
+}
+
+// chineseDigits 中文数字
+var chineseDigits = map[rune]float64{
+	'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5,
+	'六': 6, '七': 7, '八': 8, '九': 9, '十': 10, '半': 0.5,
+}
+
+// vulgarFractions Unicode 分数字符
+var vulgarFractions = map[rune]float64{
+	'½': 0.5, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 0.25, '¾': 0.75,
+}
+
+var (
+	// mixedFraction 带分数，如 "1 1/2"
+	mixedFraction = regexp.MustCompile(`^(\d+)\s+(\d+)\s*/\s*(\d+)`)
+	// simpleFraction 分数，如 "1/2"
+	simpleFraction = regexp.MustCompile(`^(\d+)\s*/\s*(\d+)`)
+	// decimalNumber 数字及可选的范围上限，如 "200"、"2-3"、"1.5~2"
+	decimalNumber = regexp.MustCompile(`^(\d+(?:\.\d+)?)(?:\s*(?:-|~|～|到|至|—)\s*(\d+(?:\.\d+)?))?`)
+)
+
+// quantity 解析后的用量
+type quantity struct {
+	kind  unitKind
+	value float64 // 基准单位下的数值
+	unit  string  // 计数单位名称（仅 kindCount）
+}
+
+// parseAmount parses an amount string such as "200克", "2-3个", "1/2 cup" or "一个半".
+// Ranges use their upper bound so the list never falls short.
+// ok is false for amounts that cannot be quantified (如「适量」、"to taste").
+func parseAmount(amount string) (quantity, bool) {
+	text := catalog.Normalize(toHalfWidth(amount))
+	for _, prefix := range []string{"约", "大约", "about ", "approx. ", "approx ", "~"} {
+		text = strings.TrimSpace(strings.TrimPrefix(text, prefix))
+	}
+	if text == "" {
+		return quantity{}, false
+	}
+
+	value, rest, ok := parseNumber(text)
+	if !ok || value <= 0 {
+		return quantity{}, false
+	}
+
+	// 「一个半」「1个半」：单位后跟「半」
+	unit := strings.TrimSpace(rest)
+	if strings.HasSuffix(unit, "半") && len([]rune(unit)) > 1 {
+		unit = strings.TrimSuffix(unit, "半")
+		value += 0.5
+	}
+	unit = strings.TrimSuffix(unit, ".")
+
+	if def, ok := units[unit]; ok {
+		return quantity{kind: def.kind, value: value * def.factor}, true
+	}
+	if alias, ok := countAliases[unit]; ok {
+		unit = alias
+	}
+	return quantity{kind: kindCount, value: value, unit: unit}, true
+}
+
+// parseNumber parses the leading number of text and returns the remaining text
+func parseNumber(text string) (float64, string, bool) {
+	if m := mixedFraction.FindStringSubmatch(text); m != nil {
+		whole, _ := strconv.ParseFloat(m[1], 64)
+		num, _ := strconv.ParseFloat(m[2], 64)
+		den, _ := strconv.ParseFloat(m[3], 64)
+		if den == 0 {
+			return 0, "", false
+		}
+		return whole + num/den, text[len(m[0]):], true
+	}
+	if m := simpleFraction.FindStringSubmatch(text); m != nil {
+		num, _ := strconv.ParseFloat(m[1], 64)
+		den, _ := strconv.ParseFloat(m[2], 64)
+		if den == 0 {
+			return 0, "", false
+		}
+		return num / den, text[len(m[0]):], true
+	}
+	if m := decimalNumber.FindStringSubmatch(text); m != nil {
+		value, _ := strconv.ParseFloat(m[1], 64)
+		if m[2] != "" {
+			value, _ = strconv.ParseFloat(m[2], 64)
+		}
+		rest := text[len(m[0]):]
+		// "1½"
+		if r, size := utf8.DecodeRuneInString(rest); size > 0 {
+			if f, ok := vulgarFractions[r]; ok {
+				value += f
+				rest = rest[size:]
+			}
+		}
+		return value, rest, true
+	}
+
+	runes := []rune(text)
+	if f, ok := vulgarFractions[runes[0]]; ok {
+		return f, string(runes[1:]), true
+	}
+	return parseChineseNumber(runes)
+}
+
+// parseChineseNumber parses a leading Chinese numeral such as 「三」「十二」「两」「半」
+func parseChineseNumber(runes []rune) (float64, string, bool) {
+	i := 0
+	value := 0.0
+	current := 0.0
+	for ; i < len(runes); i++ {
+		d, ok := chineseDigits[runes[i]]
+		if !ok {
+			break
+		}
+		// 数字后的「两」是质量单位（如「二两」）
+		if runes[i] == '两' && i > 0 {
+			break
+		}
+		switch {
+		case runes[i] == '十':
+			if current == 0 {
+				current = 1
+			}
+			value += current * 10
+			current = 0
+		case runes[i] == '半':
+			// 「半」只作为独立数字出现（如「半个」），「一个半」由调用方处理
+			if i > 0 {
+				return 0, "", false
+			}
+			current = d
+		default:
+			current = d
+		}
+	}
+	if i == 0 {
+		return 0, "", false
+	}
+	return value + current, string(runes[i:]), true
+}
+
+// toHalfWidth converts full-width digits and punctuation to half-width
+func toHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '０' && r <= '９' {
+			return r - '０' + '0'
+		}
+		switch r {
+		case '．':
+			return '.'
+		case '／':
+			return '/'
+		case '－':
+			return '-'
+		}
+		return r
+	}, s)
+}
+
+// parseServings extracts the number of servings from text such as "2人份", "2-3人", "serves 4".
+// Returns 0 when no number is found.
+func parseServings(servings string) float64 {
+	text := catalog.Normalize(toHalfWidth(servings))
+	for i, r := range text {
+		if unicode.IsDigit(r) {
+			if m := decimalNumber.FindStringSubmatch(text[i:]); m != nil {
+				// 范围取下限，避免放大倍数偏小
+				value, _ := strconv.ParseFloat(m[1], 64)
+				return value
+			}
+		}
+		if _, ok := chineseDigits[r]; ok && r != '半' {
+			if value, _, ok := parseChineseNumber([]rune(text[i:])); ok {
+				return value
+			}
+		}
+	}
+	return 0
+}
+
+// Grams estimates the weight of an amount in grams. Volumes count as grams of water;
+// ok is false for counted (如「半个」) and unquantifiable amounts.
+func Grams(amount string) (float64, bool) {
+	q, ok := parseAmount(amount)
+	if !ok || q.kind == kindCount {
+		return 0, false
+	}
+	return q.value, true
+}
+
+// ScaleAmount multiplies the leading number of an amount by factor and keeps the
+// rest of the text, e.g. "200克" x 1.5 = "300克". ok is false for amounts without
+// a number (如「适量」), which are returned unchanged.
+func ScaleAmount(amount string, factor float64) (string, bool) {
+	text := strings.TrimSpace(toHalfWidth(amount))
+	if text == "" || factor <= 0 {
+		return amount, false
+	}
+	value, rest, ok := parseNumber(text)
+	if !ok || value <= 0 {
+		return amount, false
+	}
+	// 「一个半」：单位后跟「半」
+	if strings.HasSuffix(rest, "半") && len([]rune(rest)) > 1 {
+		rest = strings.TrimSuffix(rest, "半")
+		value += 0.5
+	}
+	scaled := roundTo(value*factor, 1)
+	if scaled == 0 {
+		scaled = roundTo(value*factor, 2)
+	}
+	return formatNumber(scaled) + rest, true
+}
+
+// roundTo rounds a value to the given number of decimals
+func roundTo(value float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(value*p) / p
+}
+
+// formatNumber formats a number without trailing zeros
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
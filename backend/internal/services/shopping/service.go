@@ -0,0 +1,322 @@
+// Package shopping merges the ingredients of several recipes into one
+// consolidated shopping list grouped by store section
+package shopping
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/i18n"
+	"github.com/eat-only-in-season/backend/internal/models"
+	"github.com/eat-only-in-season/backend/internal/services/catalog"
+)
+
+// sectionOrder 购物清单中区域的显示顺序（按逛超市的常见路线）
+var sectionOrder = []models.StoreSection{
+	models.SectionProduce,
+	models.SectionSeafood,
+	models.SectionMeat,
+	models.SectionDairy,
+	models.SectionPantry,
+	models.SectionOther,
+}
+
+// RecipeInput 参与合并的一道菜谱
+type RecipeInput struct {
+	Detail *models.NewRecipeDetail
+	// Servings 目标份数，0 表示按菜谱原份量
+	Servings int
+	// Scale 用量倍数，同一菜谱出现多次时为各次倍数之和（见 ScaleFor）；0 表示按 Servings 换算
+	Scale float64
+}
+
+// Service builds shopping lists
+type Service struct{}
+
+// NewService creates a new shopping list service
+func NewService() *Service {
+	return &Service{}
+}
+
+// aggregate 合并中的一种食材
+type aggregate struct {
+	name       string
+	section    models.StoreSection
+	variants   []string
+	mass       float64
+	volume     float64
+	counts     map[string]float64
+	countOrder []string
+	texts      []string
+	recipes    []string
+}
+
+// Build merges the ingredients of the given recipes. Identical canonical ingredients
+// are summed when their units are compatible; seasonal is the list of ingredient
+// names in season for the user's city and may be empty.
+func (s *Service) Build(inputs []RecipeInput, seasonal []string, lang string) *models.ShoppingList {
+	list := &models.ShoppingList{
+		Recipes:  make([]models.ShoppingListRecipeSummary, 0, len(inputs)),
+		Sections: []models.ShoppingSection{},
+	}
+
+	items := make(map[string]*aggregate)
+	var order []string
+
+	for _, in := range inputs {
+		detail := in.Detail
+		scale := in.Scale
+		if scale <= 0 {
+			scale = ScaleFor(detail, in.Servings)
+		}
+		servings := detail.Servings
+		if in.Scale > 0 {
+			// 菜谱未写明份数时保留原文，倍数见 Scale
+			if base := parseServings(detail.Servings); base > 0 {
+				servings = formatNumber(roundTo(base*scale, 1))
+			}
+		} else if in.Servings > 0 {
+			servings = fmt.Sprintf("%d", in.Servings)
+		}
+		list.Recipes = append(list.Recipes, models.ShoppingListRecipeSummary{
+			ID:       detail.ID,
+			Title:    detail.Title,
+			Servings: servings,
+			Scale:    roundTo(scale, 2),
+		})
+
+		for _, ing := range detail.Ingredients {
+			name := strings.TrimSpace(ing.Name)
+			if name == "" {
+				continue
+			}
+
+			key := "name:" + catalog.Normalize(name)
+			canonical := name
+			section := models.SectionOther
+			if e, ok := catalog.Lookup(name); ok {
+				key = e.ID
+				canonical = e.DisplayName(lang)
+				section = e.Section
+			}
+
+			agg, ok := items[key]
+			if !ok {
+				agg = &aggregate{name: canonical, section: section, counts: make(map[string]float64)}
+				items[key] = agg
+				order = append(order, key)
+			}
+			if name != agg.name && !containsString(agg.variants, name) {
+				agg.variants = append(agg.variants, name)
+			}
+			if !containsString(agg.recipes, detail.Title) {
+				agg.recipes = append(agg.recipes, detail.Title)
+			}
+			agg.add(ing.Amount, scale)
+		}
+	}
+
+	bySection := make(map[models.StoreSection][]models.ShoppingItem)
+	for _, key := range order {
+		agg := items[key]
+		item := agg.item(lang)
+		item.InSeason = isInSeason(agg, seasonal)
+		bySection[agg.section] = append(bySection[agg.section], item)
+	}
+
+	for _, section := range sectionOrder {
+		if len(bySection[section]) == 0 {
+			continue
+		}
+		list.Sections = append(list.Sections, models.ShoppingSection{
+			Section: section,
+			Name:    i18n.GetSection(lang, string(section)),
+			Items:   bySection[section],
+		})
+	}
+
+	return list
+}
+
+// add adds a scaled amount to the aggregate
+func (a *aggregate) add(amount string, scale float64) {
+	q, ok := parseAmount(amount)
+	if !ok {
+		text := strings.TrimSpace(amount)
+		if text != "" && !containsString(a.texts, text) {
+			a.texts = append(a.texts, text)
+		}
+		return
+	}
+
+	switch q.kind {
+	case kindMass:
+		a.mass += q.value * scale
+	case kindVolume:
+		a.volume += q.value * scale
+	default:
+		if _, ok := a.counts[q.unit]; !ok {
+			a.countOrder = append(a.countOrder, q.unit)
+		}
+		a.counts[q.unit] += q.value * scale
+	}
+}
+
+// item converts the aggregate into a shopping item
+func (a *aggregate) item(lang string) models.ShoppingItem {
+	quantities := make([]models.ShoppingQuantity, 0, 2+len(a.countOrder)+len(a.texts))
+	if a.mass > 0 {
+		quantities = append(quantities, massQuantity(a.mass, lang))
+	}
+	if a.volume > 0 {
+		quantities = append(quantities, volumeQuantity(a.volume, lang))
+	}
+	for _, unit := range a.countOrder {
+		value := roundTo(a.counts[unit], 1)
+		quantities = append(quantities, models.ShoppingQuantity{
+			Value: value,
+			Unit:  unit,
+			Text:  joinValueUnit(value, unit, lang),
+		})
+	}
+	for _, text := range a.texts {
+		quantities = append(quantities, models.ShoppingQuantity{Text: text})
+	}
+
+	parts := make([]string, 0, len(quantities))
+	for _, q := range quantities {
+		parts = append(parts, q.Text)
+	}
+
+	return models.ShoppingItem{
+		Name:       a.name,
+		Amount:     strings.Join(parts, " + "),
+		Quantities: quantities,
+		Variants:   a.variants,
+		Recipes:    a.recipes,
+	}
+}
+
+// massQuantity formats grams, switching to kilograms from 1000 g
+func massQuantity(grams float64, lang string) models.ShoppingQuantity {
+	value, unit := roundTo(grams, 0), "g"
+	if grams >= 1000 {
+		value, unit = roundTo(grams/1000, 2), "kg"
+	}
+	return models.ShoppingQuantity{Value: value, Unit: unit, Text: joinValueUnit(value, localUnit(unit, lang), lang)}
+}
+
+// volumeQuantity formats millilitres, switching to litres from 1000 ml
+func volumeQuantity(ml float64, lang string) models.ShoppingQuantity {
+	value, unit := roundTo(ml, 0), "ml"
+	if ml >= 1000 {
+		value, unit = roundTo(ml/1000, 2), "L"
+	}
+	return models.ShoppingQuantity{Value: value, Unit: unit, Text: joinValueUnit(value, localUnit(unit, lang), lang)}
+}
+
+// localUnit returns the display name of a metric unit
+func localUnit(unit, lang string) string {
+	if lang == "en" {
+		return unit
+	}
+	switch unit {
+	case "g":
+		return "克"
+	case "kg":
+		return "千克"
+	case "ml":
+		return "毫升"
+	case "L":
+		return "升"
+	}
+	return unit
+}
+
+// joinValueUnit joins a number and a unit; English separates them with a space
+func joinValueUnit(value float64, unit, lang string) string {
+	if unit == "" {
+		return formatNumber(value)
+	}
+	if lang == "en" {
+		return formatNumber(value) + " " + unit
+	}
+	return formatNumber(value) + unit
+}
+
+// ScaleFor returns the factor to scale a recipe from its own servings to the wanted
+// servings: 1 when no servings are wanted or the recipe's servings carry no number
+func ScaleFor(detail *models.NewRecipeDetail, wanted int) float64 {
+	if wanted <= 0 {
+		return 1
+	}
+	base := parseServings(detail.Servings)
+	if base <= 0 {
+		return 1
+	}
+	return float64(wanted) / base
+}
+
+// isInSeason reports whether an ingredient (or any of its variants) is in the seasonal list
+func isInSeason(a *aggregate, seasonal []string) bool {
+	for _, s := range seasonal {
+		if catalog.SameIngredient(a.name, s) {
+			return true
+		}
+		for _, v := range a.variants {
+			if catalog.SameIngredient(v, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatText renders a shopping list as plain text with one checkbox per item
+func (s *Service) FormatText(list *models.ShoppingList, lang string) string {
+	var sb strings.Builder
+
+	sb.WriteString(i18n.GetMessage(lang, "shopping_list_title"))
+	sb.WriteString("\n\n")
+
+	if len(list.Recipes) > 0 {
+		sb.WriteString(i18n.GetMessage(lang, "shopping_list_recipes"))
+		sb.WriteString(":\n")
+		for _, r := range list.Recipes {
+			sb.WriteString(fmt.Sprintf("- %s (%s: %s)\n", r.Title, i18n.GetMessage(lang, "shopping_list_servings"), r.Servings))
+		}
+		sb.WriteString("\n")
+	}
+
+	inSeason := i18n.GetMessage(lang, "shopping_list_in_season")
+	for _, section := range list.Sections {
+		sb.WriteString(fmt.Sprintf("[%s]\n", section.Name))
+		for _, item := range section.Items {
+			line := "- [ ] " + item.Name
+			if item.Amount != "" {
+				line += "  " + item.Amount
+			}
+			if item.InSeason {
+				line += "  (" + inSeason + ")"
+			}
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(list.MissingRecipes) > 0 {
+		sb.WriteString(i18n.GetMessage(lang, "shopping_list_missing"))
+		sb.WriteString(": " + strings.Join(list.MissingRecipes, ", ") + "\n")
+	}
+
+	return sb.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+// Package timing parses the localized cooking durations returned by the LLM
+// into seconds and classifies recipe steps as active or passive
+package timing
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// Consistency tolerance between the step durations and the total cooking time
+const (
+	// minToleranceSeconds 允许的最小误差
+	minToleranceSeconds = 5 * 60
+	// toleranceRatio 允许的相对误差
+	toleranceRatio = 0.25
+	// overnightSeconds 「一夜」「overnight」按 8 小时计
+	overnightSeconds = 8 * 3600
+)
+
+// unitSeconds 时间单位 -> 秒数，较长的写法在前，保证正则优先匹配
+var unitSeconds = []struct {
+	name    string
+	seconds float64
+}{
+	{"个小时", 3600}, {"个钟头", 3600}, {"小时", 3600}, {"钟头", 3600}, {"hours", 3600}, {"hour", 3600}, {"hrs", 3600}, {"hr", 3600}, {"h", 3600},
+	{"分钟", 60}, {"分", 60}, {"minutes", 60}, {"minute", 60}, {"mins", 60}, {"min", 60}, {"m", 60},
+	{"秒钟", 1}, {"秒", 1}, {"seconds", 1}, {"second", 1}, {"secs", 1}, {"sec", 1}, {"s", 1},
+	{"天", 86400}, {"days", 86400}, {"day", 86400},
+}
+
+var (
+	// amountPattern 数字（含范围，如 "20-30"、"1.5"）或中文数字后跟时间单位
+	amountPattern *regexp.Regexp
+	// notMinuteSuffixes 跟在「分」后说明是程度或比例而不是分钟：七分熟、八分满、三分之一、三分肥七分瘦
+	notMinuteSuffixes = "熟满之肥瘦饱干"
+	// chineseNumber 中文数字
+	chineseNumber = map[rune]float64{
+		'一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5,
+		'六': 6, '七': 7, '八': 8, '九': 9, '十': 10, '半': 0.5,
+	}
+)
+
+func init() {
+	names := make([]string, 0, len(unitSeconds))
+	for _, u := range unitSeconds {
+		names = append(names, regexp.QuoteMeta(u.name))
+	}
+	amountPattern = regexp.MustCompile(
+		`(\d+(?:\.\d+)?|[一二两三四五六七八九十半]+)\s*(?:(?:-|~|～|到|至|—)\s*(\d+(?:\.\d+)?|[一二两三四五六七八九十]+)\s*)?` +
+			`(` + strings.Join(names, "|") + `)(半)?`)
+}
+
+// Parse parses a duration such as "30分钟", "1小时20分", "半小时", "about 1 hour",
+// "1 hr 15 mins" or "20-30分钟" into seconds. Ranges use their upper bound.
+// ok is false when no duration is found.
+func Parse(text string) (int, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return 0, false
+	}
+
+	if strings.Contains(text, "overnight") || strings.Contains(text, "一夜") ||
+		strings.Contains(text, "一晚") || strings.Contains(text, "过夜") {
+		return overnightSeconds, true
+	}
+
+	// 「一个半小时」->「一个小时半」，便于按「数字+单位+半」解析
+	text = strings.NewReplacer("个半小时", "个小时半", "个半钟头", "个钟头半").Replace(text)
+
+	total := 0.0
+	found := false
+	for _, idx := range amountPattern.FindAllStringSubmatchIndex(text, -1) {
+		// 英文单位后紧跟字母说明不是单位（如 "5 steps" 中的 "s"）
+		if end := idx[1]; end < len(text) && text[end] >= 'a' && text[end] <= 'z' {
+			continue
+		}
+		m := make([]string, len(idx)/2)
+		for i := range m {
+			if idx[2*i] >= 0 {
+				m[i] = text[idx[2*i]:idx[2*i+1]]
+			}
+		}
+		if m[3] == "分" && m[4] == "" && notMinute(text[idx[1]:]) {
+			continue
+		}
+
+		value, ok := parseNumber(m[1])
+		if !ok {
+			continue
+		}
+		if m[2] != "" {
+			if upper, ok := parseNumber(m[2]); ok && upper > value {
+				value = upper
+			}
+		}
+		if m[4] != "" {
+			value += 0.5 // 「一个半小时」
+		}
+		total += value * secondsOf(m[3])
+		found = true
+	}
+
+	if !found {
+		return 0, false
+	}
+	return int(math.Round(total)), true
+}
+
+// notMinute reports whether the text following a bare 「分」 marks it as a degree or
+// fraction rather than minutes
+func notMinute(rest string) bool {
+	for _, r := range rest {
+		return strings.ContainsRune(notMinuteSuffixes, r)
+	}
+	return false
+}
+
+// parseNumber parses an Arabic or Chinese number
+func parseNumber(s string) (float64, bool) {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, true
+	}
+
+	value, current := 0.0, 0.0
+	for _, r := range s {
+		d, ok := chineseNumber[r]
+		if !ok {
+			return 0, false
+		}
+		if r == '十' {
+			if current == 0 {
+				current = 1
+			}
+			value += current * 10
+			current = 0
+			continue
+		}
+		current = d
+	}
+	value += current
+	return value, value > 0
+}
+
+func secondsOf(unit string) float64 {
+	for _, u := range unitSeconds {
+		if u.name == unit {
+			return u.seconds
+		}
+	}
+	return 0
+}
+
+// passiveKeywords 无需一直看管的步骤关键词（腌制、炖煮、烘烤、静置等）
+var passiveKeywords = []string{
+	"腌", "浸泡", "泡发", "泡软", "炖", "焖", "煲", "煨", "卤", "静置", "醒面", "醒发", "发酵", "冷藏", "冷冻",
+	"放凉", "晾凉", "晾干", "入烤箱", "烤箱", "蒸", "慢火", "小火慢", "文火",
+	"marinate", "soak", "simmer", "braise", "stew", "rest", "proof", "rise", "chill", "refrigerate",
+	"freeze", "cool", "bake", "roast", "steam", "slow cook", "let it sit", "let sit", "leave to",
+}
+
+// activeKeywords 明确需要操作的关键词，出现在被动关键词之后时判为主动
+var activeKeywords = []string{
+	"炒", "煎", "炸", "切", "翻", "搅", "拌", "揉", "stir", "fry", "chop", "slice", "whisk", "knead", "flip", "toss",
+}
+
+// ClassifyStep reports whether a step is active (needs attention) or passive
+// (marinating, simmering, baking and other hands-off waiting)
+func ClassifyStep(instruction string) models.StepMode {
+	text := strings.ToLower(instruction)
+
+	firstPassive := -1
+	for _, k := range passiveKeywords {
+		if i := strings.Index(text, k); i >= 0 && (firstPassive == -1 || i < firstPassive) {
+			firstPassive = i
+		}
+	}
+	if firstPassive == -1 {
+		return models.StepActive
+	}
+
+	// 「翻炒后焖煮」这类步骤以主动操作开头但主要时间花在等待上，按被动处理；
+	// 只有主动关键词出现在被动关键词之后（如「腌好后下锅翻炒」）才视为主动
+	for _, k := range activeKeywords {
+		if i := strings.Index(text, k); i > firstPassive {
+			return models.StepActive
+		}
+	}
+	return models.StepPassive
+}
+
+// Annotate fills the structured durations, step modes and timing summary of a recipe detail.
+// A step without an explicit duration falls back to a duration mentioned in its instruction.
+func Annotate(detail *models.NewRecipeDetail) {
+	detail.CookingTimeSeconds = 0
+	if seconds, ok := Parse(detail.CookingTime); ok {
+		detail.CookingTimeSeconds = seconds
+	}
+
+	summary := &models.RecipeTiming{TotalSeconds: detail.CookingTimeSeconds}
+	for i := range detail.Steps {
+		step := &detail.Steps[i]
+		step.Mode = ClassifyStep(step.Instruction)
+
+		seconds, ok := Parse(step.Duration)
+		if !ok {
+			seconds, ok = Parse(step.Instruction)
+		}
+		step.DurationSeconds = 0
+		if !ok {
+			continue
+		}
+		step.DurationSeconds = seconds
+		summary.StepsSeconds += seconds
+		summary.TimedSteps++
+		if step.Mode == models.StepPassive {
+			summary.PassiveSeconds += seconds
+		} else {
+			summary.ActiveSeconds += seconds
+		}
+	}
+
+	summary.Consistent = isConsistent(summary.TotalSeconds, summary.StepsSeconds)
+	detail.Timing = summary
+}
+
+// isConsistent reports whether the step durations roughly add up to the total.
+// Unknown values are treated as consistent since there is nothing to compare.
+func isConsistent(total, steps int) bool {
+	if total == 0 || steps == 0 {
+		return true
+	}
+	tolerance := math.Max(minToleranceSeconds, toleranceRatio*float64(total))
+	return math.Abs(float64(total-steps)) <= tolerance
+}
@@ -0,0 +1,34 @@
+package timing
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		seconds int
+		ok      bool
+	}{
+		{"30分钟", 1800, true},
+		{"1小时20分", 4800, true},
+		{"半小时", 1800, true},
+		{"一个半小时", 5400, true},
+		{"20-30分钟", 1800, true},
+		{"小火煮10分，再焖5分钟", 900, true},
+		{"about 1 hour", 3600, true},
+		{"1 hr 15 mins", 4500, true},
+		{"腌制一夜", overnightSeconds, true},
+		// 「分」表示程度或比例，不是分钟
+		{"煎至七分熟", 0, false},
+		{"倒入模具八分满", 0, false},
+		{"加入三分之一的水", 0, false},
+		{"选三分肥七分瘦的五花肉", 0, false},
+		{"煎至七分熟，约3分钟", 180, true},
+		{"翻炒均匀", 0, false},
+	}
+	for _, tt := range tests {
+		seconds, ok := Parse(tt.text)
+		if seconds != tt.seconds || ok != tt.ok {
+			t.Errorf("Parse(%q) = %d, %v; want %d, %v", tt.text, seconds, ok, tt.seconds, tt.ok)
+		}
+	}
+}
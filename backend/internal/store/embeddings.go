@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// RecipeEmbedding 一道菜谱的嵌入向量
+type RecipeEmbedding struct {
+	RecipeID string
+	// Lang 菜谱的语言（读取时从菜谱库获得）
+	Lang string
+	// Model 生成向量的嵌入模型，更换模型后重新生成
+	Model string
+	// Vector 为空表示菜谱无法解析，不参与相似度计算
+	Vector []float32
+	// SourceUpdatedAt 生成向量时菜谱记录的更新时间，之后的更新会重新生成
+	SourceUpdatedAt int64
+}
+
+// UnembeddedRecipes returns up to limit stored recipes that have no embedding from
+// model yet, or were updated after they were embedded
+func (s *Store) UnembeddedRecipes(model string, limit int) ([]StoredRecipe, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.lang, r.data, r.updated_at
+		FROM recipes r
+		LEFT JOIN recipe_embeddings e ON e.recipe_id = r.id
+		WHERE e.recipe_id IS NULL OR e.model != ? OR e.source_updated_at < r.updated_at
+		LIMIT ?`,
+		model, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []StoredRecipe
+	for rows.Next() {
+		var r StoredRecipe
+		if err := rows.Scan(&r.ID, &r.Lang, &r.Data, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, r)
+	}
+	return recipes, rows.Err()
+}
+
+// SaveEmbeddings adds or replaces recipe embeddings
+func (s *Store) SaveEmbeddings(embeddings []RecipeEmbedding) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, e := range embeddings {
+		if _, err := tx.Exec(
+			`INSERT INTO recipe_embeddings (recipe_id, model, dims, vector, source_updated_at) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(recipe_id) DO UPDATE SET model = excluded.model, dims = excluded.dims,
+				vector = excluded.vector, source_updated_at = excluded.source_updated_at`,
+			e.RecipeID, e.Model, len(e.Vector), encodeVector(e.Vector), e.SourceUpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListEmbeddings returns the non-empty embeddings generated by model, with the
+// language of their recipes
+func (s *Store) ListEmbeddings(model string) ([]RecipeEmbedding, error) {
+	rows, err := s.db.Query(
+		`SELECT e.recipe_id, r.lang, e.vector, e.source_updated_at
+		FROM recipe_embeddings e
+		JOIN recipes r ON r.id = e.recipe_id
+		WHERE e.model = ? AND e.dims > 0`,
+		model,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var embeddings []RecipeEmbedding
+	for rows.Next() {
+		e := RecipeEmbedding{Model: model}
+		var blob []byte
+		if err := rows.Scan(&e.RecipeID, &e.Lang, &blob, &e.SourceUpdatedAt); err != nil {
+			return nil, err
+		}
+		e.Vector = decodeVector(blob)
+		embeddings = append(embeddings, e)
+	}
+	return embeddings, rows.Err()
+}
+
+// RecipeSummaries returns the summaries of the stored recipes among ids, keyed by ID
+func (s *Store) RecipeSummaries(ids []string) (map[string]models.RecipeSummary, error) {
+	summaries := make(map[string]models.RecipeSummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	args := make([]any, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := s.db.Query(
+		"SELECT id, lang, data FROM recipes WHERE id IN (?"+strings.Repeat(", ?", len(ids)-1)+")",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, lang, data string
+		if err := rows.Scan(&id, &lang, &data); err != nil {
+			return nil, err
+		}
+		summary, err := recipeSummary(data, lang)
+		if err != nil {
+			return nil, err
+		}
+		summaries[id] = summary
+	}
+	return summaries, rows.Err()
+}
+
+// encodeVector encodes a vector as little-endian float32 values
+func encodeVector(v []float32) []byte {
+	blob := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(x))
+	}
+	return blob
+}
+
+// decodeVector decodes a vector encoded by encodeVector
+func decodeVector(blob []byte) []float32 {
+	v := make([]float32, len(blob)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+const feedbackColumns = "recipe_id, title, cuisine, ingredients, rating, cooked_at, rejected, note, created_at, updated_at"
+
+// SaveFeedback inserts or replaces the feedback of a user on a recipe
+func (s *Store) SaveFeedback(userID string, feedback *models.RecipeFeedback) error {
+	ingredients, err := json.Marshal(feedback.Ingredients)
+	if err != nil {
+		return err
+	}
+	var cookedAt int64
+	if feedback.CookedAt != nil {
+		cookedAt = feedback.CookedAt.Unix()
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.Exec(
+		`INSERT INTO recipe_feedback (user_id, `+feedbackColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, recipe_id) DO UPDATE SET title = excluded.title, cuisine = excluded.cuisine,
+			ingredients = excluded.ingredients, rating = excluded.rating, cooked_at = excluded.cooked_at,
+			rejected = excluded.rejected, note = excluded.note, updated_at = excluded.updated_at`,
+		userID, feedback.RecipeID, feedback.Title, string(feedback.Cuisine), string(ingredients), feedback.Rating,
+		cookedAt, feedback.Rejected, feedback.Note, feedback.CreatedAt.Unix(), feedback.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// GetFeedback returns the feedback of a user on a recipe
+func (s *Store) GetFeedback(userID, recipeID string) (*models.RecipeFeedback, error) {
+	row := s.db.QueryRow("SELECT "+feedbackColumns+" FROM recipe_feedback WHERE user_id = ? AND recipe_id = ?", userID, recipeID)
+	feedback, err := scanFeedback(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return feedback, err
+}
+
+// ListFeedback returns the most recently updated feedback of a user
+func (s *Store) ListFeedback(userID string, limit int) ([]models.RecipeFeedback, error) {
+	rows, err := s.db.Query(
+		"SELECT "+feedbackColumns+" FROM recipe_feedback WHERE user_id = ? ORDER BY updated_at DESC, rowid DESC LIMIT ?",
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.RecipeFeedback, 0)
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *feedback)
+	}
+	return items, rows.Err()
+}
+
+// RejectedTitles returns the titles of every dish a user rejected or rated one star,
+// most recently updated first
+func (s *Store) RejectedTitles(userID string) ([]string, error) {
+	rows, err := s.db.Query(
+		"SELECT title FROM recipe_feedback WHERE user_id = ? AND (rejected = 1 OR rating = 1) ORDER BY updated_at DESC, rowid DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	titles := make([]string, 0)
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
+
+// DeleteFeedback deletes the feedback of a user on a recipe
+func (s *Store) DeleteFeedback(userID, recipeID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM recipe_feedback WHERE user_id = ? AND recipe_id = ?", userID, recipeID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecipeRatings aggregates the ratings and cooked marks of registered users on a recipe;
+// feedback left under anonymous IDs (before submitting required login) is not counted
+func (s *Store) RecipeRatings(recipeID string) (*models.RecipeRatings, error) {
+	ratings := &models.RecipeRatings{RecipeID: recipeID}
+	rows, err := s.db.Query(
+		`SELECT rating, COUNT(*), SUM(cooked_at > 0) FROM recipe_feedback
+		WHERE recipe_id = ? AND user_id IN (SELECT id FROM users) GROUP BY rating`,
+		recipeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	total := 0
+	for rows.Next() {
+		var rating, count, cooked int
+		if err := rows.Scan(&rating, &count, &cooked); err != nil {
+			return nil, err
+		}
+		ratings.CookedCount += cooked
+		if rating >= 1 && rating <= 5 {
+			ratings.Distribution[rating-1] = count
+			ratings.RatingCount += count
+			total += rating * count
+		}
+	}
+	if ratings.RatingCount > 0 {
+		ratings.AverageRating = float64(total) / float64(ratings.RatingCount)
+	}
+	return ratings, rows.Err()
+}
+
+func scanFeedback(row rowScanner) (*models.RecipeFeedback, error) {
+	var feedback models.RecipeFeedback
+	var cuisine, ingredients string
+	var cookedAt, createdAt, updatedAt int64
+	if err := row.Scan(&feedback.RecipeID, &feedback.Title, &cuisine, &ingredients, &feedback.Rating,
+		&cookedAt, &feedback.Rejected, &feedback.Note, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(ingredients), &feedback.Ingredients); err != nil {
+		return nil, err
+	}
+	feedback.Cuisine = models.Cuisine(cuisine)
+	if cookedAt > 0 {
+		t := time.Unix(cookedAt, 0)
+		feedback.CookedAt = &t
+	}
+	feedback.CreatedAt = time.Unix(createdAt, 0)
+	feedback.UpdatedAt = time.Unix(updatedAt, 0)
+	return &feedback, nil
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// ErrVersionConflict 记录在读取后已被修改
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
+// CreateMealPlan inserts a meal plan of a user (empty when anonymous) together with
+// its unused candidate recipes; the plan starts at version 1
+func (s *Store) CreateMealPlan(userID string, plan *models.MealPlan, candidates []models.RecipeWithMatch) error {
+	data, pool, err := encodeMealPlan(plan, candidates)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.Exec(
+		`INSERT INTO meal_plans (id, user_id, data, candidates, version, created_at, updated_at) VALUES (?, ?, ?, ?, 1, ?, ?)`,
+		plan.ID, userID, data, pool, plan.CreatedAt.Unix(), time.Now().Unix(),
+	)
+	return err
+}
+
+// UpdateMealPlan replaces a user's meal plan and its unused candidate recipes if it is
+// still at the version it was read at, returning ErrVersionConflict otherwise
+func (s *Store) UpdateMealPlan(userID string, plan *models.MealPlan, candidates []models.RecipeWithMatch, version int) error {
+	data, pool, err := encodeMealPlan(plan, candidates)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec(
+		`UPDATE meal_plans SET data = ?, candidates = ?, version = version + 1, updated_at = ?
+		WHERE id = ? AND user_id = ? AND version = ?`,
+		data, pool, time.Now().Unix(), plan.ID, userID, version,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// GetMealPlan returns a user's meal plan, its unused candidate recipes and its version
+func (s *Store) GetMealPlan(userID, id string) (*models.MealPlan, []models.RecipeWithMatch, int, error) {
+	var data, pool string
+	var version int
+	err := s.db.QueryRow(
+		"SELECT data, candidates, version FROM meal_plans WHERE id = ? AND user_id = ?", id, userID,
+	).Scan(&data, &pool, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var plan models.MealPlan
+	if err := json.Unmarshal([]byte(data), &plan); err != nil {
+		return nil, nil, 0, err
+	}
+	var candidates []models.RecipeWithMatch
+	if err := json.Unmarshal([]byte(pool), &candidates); err != nil {
+		return nil, nil, 0, err
+	}
+	return &plan, candidates, version, nil
+}
+
+// DeleteMealPlan deletes a user's meal plan
+func (s *Store) DeleteMealPlan(userID, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM meal_plans WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// encodeMealPlan 序列化计划及其候选菜谱
+func encodeMealPlan(plan *models.MealPlan, candidates []models.RecipeWithMatch) (string, string, error) {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return "", "", err
+	}
+	if candidates == nil {
+		candidates = []models.RecipeWithMatch{}
+	}
+	pool, err := json.Marshal(candidates)
+	if err != nil {
+		return "", "", err
+	}
+	return string(data), string(pool), nil
+}
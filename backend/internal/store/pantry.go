@@ -0,0 +1,91 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+const pantryColumns = "id, name, quantity, expires_on, created_at, updated_at"
+
+// ListPantryItems returns the pantry items of a user, soonest expiry first
+// (items without an expiry date last)
+func (s *Store) ListPantryItems(userID string) ([]models.PantryItem, error) {
+	rows, err := s.db.Query(
+		"SELECT "+pantryColumns+" FROM pantry_items WHERE user_id = ? "+
+			"ORDER BY expires_on = '', expires_on, created_at",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]models.PantryItem, 0)
+	for rows.Next() {
+		item, err := scanPantryItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, rows.Err()
+}
+
+// GetPantryItem returns a single pantry item of a user
+func (s *Store) GetPantryItem(userID, id string) (*models.PantryItem, error) {
+	row := s.db.QueryRow("SELECT "+pantryColumns+" FROM pantry_items WHERE user_id = ? AND id = ?", userID, id)
+	item, err := scanPantryItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return item, err
+}
+
+// SavePantryItem inserts or updates a pantry item of a user
+func (s *Store) SavePantryItem(userID string, item *models.PantryItem) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(
+		`INSERT INTO pantry_items (id, user_id, name, quantity, expires_on, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, quantity = excluded.quantity,
+			expires_on = excluded.expires_on, updated_at = excluded.updated_at
+		WHERE pantry_items.user_id = excluded.user_id`,
+		item.ID, userID, item.Name, item.Quantity, item.ExpiresOn, item.CreatedAt.Unix(), item.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// DeletePantryItem deletes a pantry item of a user
+func (s *Store) DeletePantryItem(userID, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM pantry_items WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPantryItem(row rowScanner) (*models.PantryItem, error) {
+	var item models.PantryItem
+	var createdAt, updatedAt int64
+	if err := row.Scan(&item.ID, &item.Name, &item.Quantity, &item.ExpiresOn, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	item.CreatedAt = time.Unix(createdAt, 0)
+	item.UpdatedAt = time.Unix(updatedAt, 0)
+	return &item, nil
+}
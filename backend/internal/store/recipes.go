@@ -0,0 +1,220 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// SaveRecipe inserts or replaces a generated recipe detail. Saving an unchanged
+// detail (e.g. when recording a view) leaves the row and its updated_at untouched,
+// so the search index and embeddings are only rebuilt for real changes.
+func (s *Store) SaveRecipe(detail *models.NewRecipeDetail, lang string) error {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now().Unix()
+	_, err = s.db.Exec(
+		`INSERT INTO recipes (id, lang, title, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET lang = excluded.lang, title = excluded.title, data = excluded.data, updated_at = excluded.updated_at
+		WHERE recipes.data != excluded.data OR recipes.lang != excluded.lang OR recipes.title != excluded.title`,
+		detail.ID, lang, detail.Title, string(data), now, now,
+	)
+	return err
+}
+
+// GetRecipe returns a stored recipe detail and its language
+func (s *Store) GetRecipe(id string) (*models.NewRecipeDetail, string, error) {
+	var data, lang string
+	err := s.db.QueryRow("SELECT data, lang FROM recipes WHERE id = ?", id).Scan(&data, &lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var detail models.NewRecipeDetail
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		return nil, "", err
+	}
+	return &detail, lang, nil
+}
+
+// SaveFavourite adds a stored recipe to the favourites of a user, updating the note
+// if it is already a favourite
+func (s *Store) SaveFavourite(userID, recipeID, note string) (*models.FavouriteRecipe, error) {
+	s.mutex.Lock()
+	_, err := s.db.Exec(
+		`INSERT INTO favourites (user_id, recipe_id, note, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, recipe_id) DO UPDATE SET note = excluded.note`,
+		userID, recipeID, note, time.Now().Unix(),
+	)
+	s.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.GetFavourite(userID, recipeID)
+}
+
+// GetFavourite returns a favourite recipe of a user
+func (s *Store) GetFavourite(userID, recipeID string) (*models.FavouriteRecipe, error) {
+	row := s.db.QueryRow(
+		`SELECT f.note, f.created_at, r.lang, r.data FROM favourites f JOIN recipes r ON r.id = f.recipe_id
+		WHERE f.user_id = ? AND f.recipe_id = ?`,
+		userID, recipeID,
+	)
+	favourite, err := scanFavourite(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return favourite, err
+}
+
+// ListFavourites returns the favourite recipes of a user, most recently saved first
+func (s *Store) ListFavourites(userID string) ([]models.FavouriteRecipe, error) {
+	rows, err := s.db.Query(
+		`SELECT f.note, f.created_at, r.lang, r.data FROM favourites f JOIN recipes r ON r.id = f.recipe_id
+		WHERE f.user_id = ? ORDER BY f.created_at DESC, f.rowid DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favourites := make([]models.FavouriteRecipe, 0)
+	for rows.Next() {
+		favourite, err := scanFavourite(rows)
+		if err != nil {
+			return nil, err
+		}
+		favourites = append(favourites, *favourite)
+	}
+	return favourites, rows.Err()
+}
+
+// DeleteFavourite removes a recipe from the favourites of a user
+func (s *Store) DeleteFavourite(userID, recipeID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM favourites WHERE user_id = ? AND recipe_id = ?", userID, recipeID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordView records that a user viewed a stored recipe and keeps only the most
+// recent keep entries of the user's history
+func (s *Store) RecordView(userID, recipeID string, keep int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO recipe_history (user_id, recipe_id, view_count, viewed_at) VALUES (?, ?, 1, ?)
+		ON CONFLICT(user_id, recipe_id) DO UPDATE SET view_count = view_count + 1, viewed_at = excluded.viewed_at`,
+		userID, recipeID, time.Now().Unix(),
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM recipe_history WHERE user_id = ? AND recipe_id NOT IN (
+			SELECT recipe_id FROM recipe_history WHERE user_id = ? ORDER BY viewed_at DESC, rowid DESC LIMIT ?)`,
+		userID, userID, keep,
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListHistory returns the most recently viewed recipes of a user
+func (s *Store) ListHistory(userID string, limit int) ([]models.HistoryEntry, error) {
+	rows, err := s.db.Query(
+		`SELECT h.view_count, h.viewed_at, r.lang, r.data FROM recipe_history h JOIN recipes r ON r.id = h.recipe_id
+		WHERE h.user_id = ? ORDER BY h.viewed_at DESC, h.rowid DESC LIMIT ?`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.HistoryEntry, 0)
+	for rows.Next() {
+		var entry models.HistoryEntry
+		var viewedAt int64
+		var lang, data string
+		if err := rows.Scan(&entry.ViewCount, &viewedAt, &lang, &data); err != nil {
+			return nil, err
+		}
+		summary, err := recipeSummary(data, lang)
+		if err != nil {
+			return nil, err
+		}
+		entry.Recipe = summary
+		entry.ViewedAt = time.Unix(viewedAt, 0)
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+// ClearHistory deletes the viewing history of a user
+func (s *Store) ClearHistory(userID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec("DELETE FROM recipe_history WHERE user_id = ?", userID)
+	return err
+}
+
+func scanFavourite(row rowScanner) (*models.FavouriteRecipe, error) {
+	var favourite models.FavouriteRecipe
+	var createdAt int64
+	var lang, data string
+	if err := row.Scan(&favourite.Note, &createdAt, &lang, &data); err != nil {
+		return nil, err
+	}
+	summary, err := recipeSummary(data, lang)
+	if err != nil {
+		return nil, err
+	}
+	favourite.Recipe = summary
+	favourite.SavedAt = time.Unix(createdAt, 0)
+	return &favourite, nil
+}
+
+// recipeSummary decodes the summary fields of a stored recipe detail
+func recipeSummary(data, lang string) (models.RecipeSummary, error) {
+	var detail models.NewRecipeDetail
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		return models.RecipeSummary{}, err
+	}
+	return models.RecipeSummary{
+		ID:          detail.ID,
+		Title:       detail.Title,
+		Description: detail.Description,
+		CookingTime: detail.CookingTime,
+		Difficulty:  detail.Difficulty,
+		Cuisine:     detail.Cuisine,
+		Lang:        lang,
+	}, nil
+}
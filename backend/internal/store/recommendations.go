@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// SaveRecommendations inserts or replaces the contexts of recommended recipes. Contexts
+// that did not change apart from their creation time are not rewritten, so repeating a
+// cached recommendation does not write; a changed context restarts its retention.
+func (s *Store) SaveRecommendations(contexts []models.RecommendationContext) error {
+	if len(contexts) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, rc := range contexts {
+		data, err := json.Marshal(rc)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO recommendations (recipe_id, lang, data, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT(recipe_id) DO UPDATE SET lang = excluded.lang, data = excluded.data, created_at = excluded.created_at
+			WHERE recommendations.lang != excluded.lang
+			OR json_remove(recommendations.data, '$.createdAt') != json_remove(excluded.data, '$.createdAt')`,
+			rc.RecipeID, rc.Lang, string(data), rc.CreatedAt.Unix(),
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteRecommendationsBefore deletes the contexts of recipes recommended before cutoff
+// and returns how many were deleted
+func (s *Store) DeleteRecommendationsBefore(cutoff time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM recommendations WHERE created_at < ?", cutoff.Unix())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// GetRecommendation returns the context a recommended recipe was generated in
+func (s *Store) GetRecommendation(recipeID string) (*models.RecommendationContext, error) {
+	var data string
+	err := s.db.QueryRow("SELECT data FROM recommendations WHERE recipe_id = ?", recipeID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rc models.RecommendationContext
+	if err := json.Unmarshal([]byte(data), &rc); err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
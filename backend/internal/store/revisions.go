@@ -0,0 +1,206 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// AddRecipeRevisions appends revisions (numbered in order) to a user's version of a
+// recipe, creating it on the first revision, and returns the updated user recipe
+func (s *Store) AddRecipeRevisions(userID, lang, forkedFrom string, revisions ...models.RecipeRevision) (*models.UserRecipe, error) {
+	if len(revisions) == 0 {
+		return nil, errors.New("no revisions to add")
+	}
+	recipeID := revisions[0].Recipe.ID
+	data := make([]string, len(revisions))
+	for i, rev := range revisions {
+		encoded, err := json.Marshal(rev.Recipe)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = string(encoded)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	if _, err := tx.Exec(
+		`INSERT INTO user_recipes (user_id, recipe_id, lang, forked_from, revision, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?) ON CONFLICT(user_id, recipe_id) DO NOTHING`,
+		userID, recipeID, lang, forkedFrom, now, now,
+	); err != nil {
+		return nil, err
+	}
+
+	var current int
+	if err := tx.QueryRow(
+		"SELECT revision FROM user_recipes WHERE user_id = ? AND recipe_id = ?", userID, recipeID,
+	).Scan(&current); err != nil {
+		return nil, err
+	}
+	for i, rev := range revisions {
+		current++
+		if _, err := tx.Exec(
+			`INSERT INTO recipe_revisions (user_id, recipe_id, revision, data, message, restored_from, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			userID, recipeID, current, data[i], rev.Message, rev.RestoredFrom, now,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(
+		"UPDATE user_recipes SET revision = ?, lang = ?, updated_at = ? WHERE user_id = ? AND recipe_id = ?",
+		current, lang, now, userID, recipeID,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	recipe, _, err := s.GetUserRecipe(userID, recipeID)
+	return recipe, err
+}
+
+// GetUserRecipe returns a user's version of a recipe and the detail of its current revision
+func (s *Store) GetUserRecipe(userID, recipeID string) (*models.UserRecipe, *models.NewRecipeDetail, error) {
+	row := s.db.QueryRow(
+		`SELECT u.lang, u.forked_from, u.revision, u.created_at, u.updated_at, r.data
+		FROM user_recipes u JOIN recipe_revisions r
+		ON r.user_id = u.user_id AND r.recipe_id = u.recipe_id AND r.revision = u.revision
+		WHERE u.user_id = ? AND u.recipe_id = ?`,
+		userID, recipeID,
+	)
+	recipe, detail, err := scanUserRecipe(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	return recipe, detail, err
+}
+
+// ListUserRecipes returns the recipes a user edited or forked, most recently updated first
+func (s *Store) ListUserRecipes(userID string) ([]models.UserRecipe, error) {
+	rows, err := s.db.Query(
+		`SELECT u.lang, u.forked_from, u.revision, u.created_at, u.updated_at, r.data
+		FROM user_recipes u JOIN recipe_revisions r
+		ON r.user_id = u.user_id AND r.recipe_id = u.recipe_id AND r.revision = u.revision
+		WHERE u.user_id = ? ORDER BY u.updated_at DESC, u.rowid DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := make([]models.UserRecipe, 0)
+	for rows.Next() {
+		recipe, _, err := scanUserRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, *recipe)
+	}
+	return recipes, rows.Err()
+}
+
+func scanUserRecipe(row rowScanner) (*models.UserRecipe, *models.NewRecipeDetail, error) {
+	var recipe models.UserRecipe
+	var lang, data string
+	var createdAt, updatedAt int64
+	if err := row.Scan(&lang, &recipe.ForkedFrom, &recipe.Revision, &createdAt, &updatedAt, &data); err != nil {
+		return nil, nil, err
+	}
+	var detail models.NewRecipeDetail
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		return nil, nil, err
+	}
+	summary, err := recipeSummary(data, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipe.Recipe = summary
+	recipe.CreatedAt = time.Unix(createdAt, 0)
+	recipe.UpdatedAt = time.Unix(updatedAt, 0)
+	return &recipe, &detail, nil
+}
+
+// ListRecipeRevisions returns the revisions of a user's version of a recipe, newest first,
+// without their recipe details
+func (s *Store) ListRecipeRevisions(userID, recipeID string) ([]models.RecipeRevision, error) {
+	rows, err := s.db.Query(
+		`SELECT revision, json_extract(data, '$.title'), message, restored_from, created_at
+		FROM recipe_revisions WHERE user_id = ? AND recipe_id = ? ORDER BY revision DESC`,
+		userID, recipeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]models.RecipeRevision, 0)
+	for rows.Next() {
+		var rev models.RecipeRevision
+		var title sql.NullString
+		var createdAt int64
+		if err := rows.Scan(&rev.Revision, &title, &rev.Message, &rev.RestoredFrom, &createdAt); err != nil {
+			return nil, err
+		}
+		rev.Title = title.String
+		rev.CreatedAt = time.Unix(createdAt, 0)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRecipeRevision returns one revision of a user's version of a recipe with its detail
+func (s *Store) GetRecipeRevision(userID, recipeID string, revision int) (*models.RecipeRevision, error) {
+	rev := models.RecipeRevision{Revision: revision}
+	var data string
+	var createdAt int64
+	err := s.db.QueryRow(
+		`SELECT data, message, restored_from, created_at FROM recipe_revisions
+		WHERE user_id = ? AND recipe_id = ? AND revision = ?`,
+		userID, recipeID, revision,
+	).Scan(&data, &rev.Message, &rev.RestoredFrom, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var detail models.NewRecipeDetail
+	if err := json.Unmarshal([]byte(data), &detail); err != nil {
+		return nil, err
+	}
+	rev.Title = detail.Title
+	rev.Recipe = &detail
+	rev.CreatedAt = time.Unix(createdAt, 0)
+	return &rev, nil
+}
+
+// DeleteUserRecipe removes a user's version of a recipe with all its revisions
+func (s *Store) DeleteUserRecipe(userID, recipeID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM user_recipes WHERE user_id = ? AND recipe_id = ?", userID, recipeID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
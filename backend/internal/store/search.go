@@ -0,0 +1,183 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// StoredRecipe 菜谱库中的一条原始记录，用于建立全文索引
+type StoredRecipe struct {
+	ID   string
+	Lang string
+	Data string
+	// GeneratedAt 推荐该菜谱的时间，没有推荐上下文时为保存到菜谱库的时间
+	GeneratedAt time.Time
+	UpdatedAt   int64
+}
+
+// SearchDocument 全文索引中的一道菜谱，文本字段为已分词、以空格分隔的内容
+type SearchDocument struct {
+	RecipeID       string
+	Lang           string
+	Cuisine        string
+	Difficulty     string
+	CookingSeconds int
+	Season         string
+	Title          string
+	Description    string
+	Ingredients    string
+	Steps          string
+	Tags           string
+	// SourceUpdatedAt 建立索引时菜谱记录的更新时间，之后的更新会重新索引
+	SourceUpdatedAt int64
+}
+
+// SearchFilter 全文搜索条件，Match 为 FTS5 查询表达式，其余条件为空时不过滤
+type SearchFilter struct {
+	Match      string
+	Cuisine    string
+	Difficulty string
+	Season     string
+	MaxSeconds int
+	Limit      int
+	Offset     int
+}
+
+// SearchHit 一条搜索结果
+type SearchHit struct {
+	Recipe         models.RecipeSummary
+	Season         string
+	CookingSeconds int
+	// Rank bm25 相关度，越小越相关
+	Rank float64
+}
+
+// searchWeights 各列的 bm25 权重：标题、描述、食材、步骤、标签
+const searchWeights = "10.0, 2.0, 5.0, 1.0, 3.0"
+
+// UnindexedRecipes returns up to limit stored recipes that are not indexed yet or
+// were updated after they were indexed
+func (s *Store) UnindexedRecipes(limit int) ([]StoredRecipe, error) {
+	rows, err := s.db.Query(
+		`SELECT r.id, r.lang, r.data, COALESCE(rec.created_at, r.created_at), r.updated_at
+		FROM recipes r
+		LEFT JOIN recipe_index i ON i.recipe_id = r.id
+		LEFT JOIN recommendations rec ON rec.recipe_id = r.id
+		WHERE i.recipe_id IS NULL OR i.source_updated_at < r.updated_at
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []StoredRecipe
+	for rows.Next() {
+		var r StoredRecipe
+		var generatedAt int64
+		if err := rows.Scan(&r.ID, &r.Lang, &r.Data, &generatedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		r.GeneratedAt = time.Unix(generatedAt, 0)
+		recipes = append(recipes, r)
+	}
+	return recipes, rows.Err()
+}
+
+// IndexRecipes adds or replaces documents in the full-text index
+func (s *Store) IndexRecipes(docs []SearchDocument) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, doc := range docs {
+		var rowid int64
+		if err := tx.QueryRow(
+			`INSERT INTO recipe_index (recipe_id, lang, cuisine, difficulty, cooking_seconds, season, source_updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(recipe_id) DO UPDATE SET lang = excluded.lang, cuisine = excluded.cuisine,
+				difficulty = excluded.difficulty, cooking_seconds = excluded.cooking_seconds,
+				season = excluded.season, source_updated_at = excluded.source_updated_at
+			RETURNING rowid`,
+			doc.RecipeID, doc.Lang, doc.Cuisine, doc.Difficulty, doc.CookingSeconds, doc.Season, doc.SourceUpdatedAt,
+		).Scan(&rowid); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM recipe_search WHERE rowid = ?", rowid); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT INTO recipe_search (rowid, title, description, ingredients, steps, tags) VALUES (?, ?, ?, ?, ?, ?)",
+			rowid, doc.Title, doc.Description, doc.Ingredients, doc.Steps, doc.Tags,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchRecipes returns the stored recipes matching a filter, most relevant first,
+// and the total number of matches
+func (s *Store) SearchRecipes(f SearchFilter) ([]SearchHit, int, error) {
+	where := []string{"recipe_search MATCH ?"}
+	args := []any{f.Match}
+	if f.Cuisine != "" {
+		where = append(where, "i.cuisine = ?")
+		args = append(args, f.Cuisine)
+	}
+	if f.Difficulty != "" {
+		where = append(where, "i.difficulty = ?")
+		args = append(args, f.Difficulty)
+	}
+	if f.Season != "" {
+		where = append(where, "i.season = ?")
+		args = append(args, f.Season)
+	}
+	if f.MaxSeconds > 0 {
+		where = append(where, "i.cooking_seconds > 0 AND i.cooking_seconds <= ?")
+		args = append(args, f.MaxSeconds)
+	}
+	from := `FROM recipe_search
+		JOIN recipe_index i ON i.rowid = recipe_search.rowid
+		JOIN recipes r ON r.id = i.recipe_id
+		WHERE ` + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		"SELECT r.lang, r.data, i.season, i.cooking_seconds, bm25(recipe_search, "+searchWeights+") AS rank "+
+			from+" ORDER BY rank LIMIT ? OFFSET ?",
+		append(args, f.Limit, f.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	hits := make([]SearchHit, 0)
+	for rows.Next() {
+		var hit SearchHit
+		var lang, data string
+		if err := rows.Scan(&lang, &data, &hit.Season, &hit.CookingSeconds, &hit.Rank); err != nil {
+			return nil, 0, err
+		}
+		summary, err := recipeSummary(data, lang)
+		if err != nil {
+			return nil, 0, err
+		}
+		hit.Recipe = summary
+		hits = append(hits, hit)
+	}
+	return hits, total, rows.Err()
+}
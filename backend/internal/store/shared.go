@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// SaveSharedRecipe inserts a shared recipe snapshot with its image (nil if none).
+// Snapshots are immutable; ErrConflict is returned if the slug or content hash exists.
+func (s *Store) SaveSharedRecipe(shared *models.SharedRecipe, image []byte, imageType, createdBy string) error {
+	data, err := json.Marshal(shared)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.db.Exec(
+		`INSERT INTO shared_recipes (slug, content_hash, recipe_id, lang, data, image, image_type, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		shared.Slug, shared.ContentHash, shared.Recipe.ID, shared.Lang, string(data), image, imageType, createdBy,
+		shared.PublishedAt.Unix(),
+	)
+	if err != nil && isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+// GetSharedRecipe returns a shared recipe snapshot by slug
+func (s *Store) GetSharedRecipe(slug string) (*models.SharedRecipe, error) {
+	return s.getSharedRecipe("SELECT data FROM shared_recipes WHERE slug = ?", slug)
+}
+
+// GetSharedRecipeByHash returns the shared recipe snapshot with the given content hash
+func (s *Store) GetSharedRecipeByHash(contentHash string) (*models.SharedRecipe, error) {
+	return s.getSharedRecipe("SELECT data FROM shared_recipes WHERE content_hash = ?", contentHash)
+}
+
+func (s *Store) getSharedRecipe(query, arg string) (*models.SharedRecipe, error) {
+	var data string
+	err := s.db.QueryRow(query, arg).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var shared models.SharedRecipe
+	if err := json.Unmarshal([]byte(data), &shared); err != nil {
+		return nil, err
+	}
+	return &shared, nil
+}
+
+// SharedRecipeExists reports whether a slug is taken
+func (s *Store) SharedRecipeExists(slug string) (bool, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM shared_recipes WHERE slug = ?", slug).Scan(&n)
+	return n > 0, err
+}
+
+// GetSharedImage returns the image of a shared recipe snapshot and its content type
+func (s *Store) GetSharedImage(slug string) ([]byte, string, error) {
+	var image []byte
+	var imageType string
+	err := s.db.QueryRow("SELECT image, image_type FROM shared_recipes WHERE slug = ?", slug).Scan(&image, &imageType)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && len(image) == 0) {
+		return nil, "", ErrNotFound
+	}
+	return image, imageType, err
+}
@@ -0,0 +1,279 @@
+// Package store provides SQLite persistence for user data such as accounts, meal plans,
+// pantries, favourite recipes, viewing history and recipe feedback, for generated
+// recipes and the context recommendations were generated in, for shared snapshots and
+// for the recipes users edited, with their revision history, and the full-text index
+// used to search recipes.
+// Unlike the cache, data written here never expires.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// cleanupInterval 存储清理协程的运行间隔
+const cleanupInterval = time.Hour
+
+// Store SQLite 持久化存储
+type Store struct {
+	db    *sql.DB
+	mutex sync.Mutex
+}
+
+// Default 全局存储实例
+var Default *Store
+
+// migrations 按顺序执行的数据库迁移，只能追加不能修改
+var migrations = []string{
+	// 1: 膳食计划
+	`CREATE TABLE IF NOT EXISTS meal_plans (
+		id TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		candidates TEXT NOT NULL DEFAULT '[]',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	// 2: 食材库存
+	`CREATE TABLE IF NOT EXISTS pantry_items (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		quantity TEXT NOT NULL DEFAULT '',
+		expires_on TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_pantry_items_user ON pantry_items(user_id)`,
+	// 3: 推荐菜谱的生成上下文
+	`CREATE TABLE IF NOT EXISTS recommendations (
+		recipe_id TEXT PRIMARY KEY,
+		lang TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	)`,
+	// 4: 菜谱库、收藏与浏览历史
+	`CREATE TABLE IF NOT EXISTS recipes (
+		id TEXT PRIMARY KEY,
+		lang TEXT NOT NULL,
+		title TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS favourites (
+		user_id TEXT NOT NULL,
+		recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+		note TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, recipe_id)
+	);
+	CREATE TABLE IF NOT EXISTS recipe_history (
+		user_id TEXT NOT NULL,
+		recipe_id TEXT NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+		view_count INTEGER NOT NULL DEFAULT 1,
+		viewed_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, recipe_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_recipe_history_user ON recipe_history(user_id, viewed_at)`,
+	// 5: 用户账号与 API 密钥
+	`CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		password_hash TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at INTEGER NOT NULL,
+		last_used_at INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id)`,
+	// 6: 菜谱评价与反馈（保存菜谱快照，推荐后未查看详情的菜也可评价）
+	`CREATE TABLE IF NOT EXISTS recipe_feedback (
+		user_id TEXT NOT NULL,
+		recipe_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		cuisine TEXT NOT NULL DEFAULT '',
+		ingredients TEXT NOT NULL DEFAULT '[]',
+		rating INTEGER NOT NULL DEFAULT 0,
+		cooked_at INTEGER NOT NULL DEFAULT 0,
+		rejected INTEGER NOT NULL DEFAULT 0,
+		note TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, recipe_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_recipe_feedback_recipe ON recipe_feedback(recipe_id);
+	CREATE INDEX IF NOT EXISTS idx_recipe_feedback_user ON recipe_feedback(user_id, updated_at)`,
+	// 7: 分享的菜谱快照（按内容寻址，发布后不可修改）
+	`CREATE TABLE IF NOT EXISTS shared_recipes (
+		slug TEXT PRIMARY KEY,
+		content_hash TEXT NOT NULL UNIQUE,
+		recipe_id TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		data TEXT NOT NULL,
+		image BLOB,
+		image_type TEXT NOT NULL DEFAULT '',
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL
+	)`,
+	// 8: 用户编辑的菜谱（个人版本）及其修订历史
+	`CREATE TABLE IF NOT EXISTS user_recipes (
+		user_id TEXT NOT NULL,
+		recipe_id TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		forked_from TEXT NOT NULL DEFAULT '',
+		revision INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, recipe_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_user_recipes_user ON user_recipes(user_id, updated_at);
+	CREATE TABLE IF NOT EXISTS recipe_revisions (
+		user_id TEXT NOT NULL,
+		recipe_id TEXT NOT NULL,
+		revision INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		message TEXT NOT NULL DEFAULT '',
+		restored_from INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (user_id, recipe_id, revision),
+		FOREIGN KEY (user_id, recipe_id) REFERENCES user_recipes(user_id, recipe_id) ON DELETE CASCADE
+	)`,
+	// 9: 菜谱全文索引（文本在写入前分词，中文按单字和双字切分；rowid 与 recipe_index 对应）
+	`CREATE TABLE IF NOT EXISTS recipe_index (
+		recipe_id TEXT PRIMARY KEY,
+		lang TEXT NOT NULL,
+		cuisine TEXT NOT NULL DEFAULT '',
+		difficulty TEXT NOT NULL DEFAULT '',
+		cooking_seconds INTEGER NOT NULL DEFAULT 0,
+		season TEXT NOT NULL DEFAULT '',
+		source_updated_at INTEGER NOT NULL
+	);
+	CREATE VIRTUAL TABLE IF NOT EXISTS recipe_search USING fts5(
+		title, description, ingredients, steps, tags,
+		tokenize = 'porter unicode61 remove_diacritics 2'
+	)`,
+	// 10: 菜谱嵌入向量（float32 小端序），更换嵌入模型后重新生成
+	`CREATE TABLE IF NOT EXISTS recipe_embeddings (
+		recipe_id TEXT PRIMARY KEY,
+		model TEXT NOT NULL,
+		dims INTEGER NOT NULL,
+		vector BLOB NOT NULL,
+		source_updated_at INTEGER NOT NULL
+	)`,
+	// 11: 膳食计划的所属用户（匿名创建的计划为空）与版本号（替换餐时校验）
+	`ALTER TABLE meal_plans ADD COLUMN user_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE meal_plans ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+	CREATE INDEX IF NOT EXISTS idx_meal_plans_user ON meal_plans(user_id)`,
+	// 12: 按创建时间清理过期的推荐上下文
+	`CREATE INDEX IF NOT EXISTS idx_recommendations_created ON recommendations(created_at)`,
+}
+
+// Open opens (and migrates) the SQLite database at path
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 启用 WAL 模式提升并发读取性能
+	_, _ = db.Exec("PRAGMA journal_mode=WAL")
+	_, _ = db.Exec("PRAGMA synchronous=NORMAL")
+	_, _ = db.Exec("PRAGMA foreign_keys=ON")
+
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// InitDefault 初始化全局存储
+func InitDefault(path string) error {
+	s, err := Open(path)
+	if err != nil {
+		return err
+	}
+	Default = s
+	return nil
+}
+
+// migrate applies the migrations that have not been applied yet
+func (s *Store) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL DEFAULT (strftime('%s','now'))
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("执行数据库迁移 %d 失败: %w", version, err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("[Store] 已执行数据库迁移 %d", version)
+	}
+	return nil
+}
+
+// StartCleanupRoutine 启动存储清理协程，定期删除超过 recommendationTTL 的推荐上下文，直到 ctx 结束
+func (s *Store) StartCleanupRoutine(ctx context.Context, recommendationTTL time.Duration) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	log.Printf("[Store] 清理协程已启动，推荐上下文保留: %v\n", recommendationTTL)
+	for {
+		n, err := s.DeleteRecommendationsBefore(time.Now().Add(-recommendationTTL))
+		if err != nil {
+			log.Printf("[Store] 清理推荐上下文失败: %v\n", err)
+		} else if n > 0 {
+			log.Printf("[Store] 已清理 %d 条过期的推荐上下文\n", n)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			log.Println("[Store] 清理协程已停止")
+			return
+		}
+	}
+}
+
+// Close 关闭数据库连接
+func (s *Store) Close() error {
+	return s.db.Close()
+}
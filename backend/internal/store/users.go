@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/eat-only-in-season/backend/internal/models"
+)
+
+// ErrConflict 记录违反唯一约束（如用户名已被占用）
+var ErrConflict = errors.New("record already exists")
+
+// CreateUser inserts a user with its password hash
+func (s *Store) CreateUser(user *models.User, passwordHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(
+		"INSERT INTO users (id, username, password_hash, created_at) VALUES (?, ?, ?, ?)",
+		user.ID, user.Username, passwordHash, user.CreatedAt.Unix(),
+	)
+	if err != nil && isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is a SQLite unique (or primary key) constraint violation
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// GetUser returns a user by id
+func (s *Store) GetUser(id string) (*models.User, error) {
+	user, _, err := s.scanUser(s.db.QueryRow("SELECT id, username, password_hash, created_at FROM users WHERE id = ?", id))
+	return user, err
+}
+
+// GetUserByUsername returns a user (matched case-insensitively) and its password hash
+func (s *Store) GetUserByUsername(username string) (*models.User, string, error) {
+	return s.scanUser(s.db.QueryRow("SELECT id, username, password_hash, created_at FROM users WHERE username = ?", username))
+}
+
+func (s *Store) scanUser(row rowScanner) (*models.User, string, error) {
+	var user models.User
+	var passwordHash string
+	var createdAt int64
+	err := row.Scan(&user.ID, &user.Username, &passwordHash, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	user.CreatedAt = time.Unix(createdAt, 0)
+	return &user, passwordHash, nil
+}
+
+// CreateAPIKey inserts an API key of a user; only the hash of the key is stored
+func (s *Store) CreateAPIKey(userID string, key *models.APIKey, keyHash string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.db.Exec(
+		"INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		key.ID, userID, key.Name, key.Prefix, keyHash, key.CreatedAt.Unix(),
+	)
+	return err
+}
+
+// ListAPIKeys returns the API keys of a user, most recently created first
+func (s *Store) ListAPIKeys(userID string) ([]models.APIKey, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, prefix, created_at, last_used_at FROM api_keys WHERE user_id = ? ORDER BY created_at DESC, rowid DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		var key models.APIKey
+		var createdAt, lastUsedAt int64
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &createdAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		key.CreatedAt = time.Unix(createdAt, 0)
+		if lastUsedAt > 0 {
+			t := time.Unix(lastUsedAt, 0)
+			key.LastUsedAt = &t
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// UseAPIKey returns the user owning the API key with the given hash and records its use
+func (s *Store) UseAPIKey(keyHash string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var userID string
+	err := s.db.QueryRow(
+		"UPDATE api_keys SET last_used_at = ? WHERE key_hash = ? RETURNING user_id",
+		time.Now().Unix(), keyHash,
+	).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return userID, err
+}
+
+// DeleteAPIKey revokes an API key of a user
+func (s *Store) DeleteAPIKey(userID, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, err := s.db.Exec("DELETE FROM api_keys WHERE user_id = ? AND id = ?", userID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eat-only-in-season/backend/internal/models"
@@ -29,6 +30,42 @@ type Config struct {
 
 	// Cache configuration
 	Cache models.CacheConfig
+
+	// StorePath 用户数据（膳食计划等）SQLite 数据库路径
+	StorePath string
+	// RecommendationTTL 推荐菜谱的生成上下文保留时间，过期后由存储清理协程删除
+	RecommendationTTL time.Duration
+
+	// LLMConcurrency 菜谱服务同时进行的 LLM 调用数
+	LLMConcurrency int
+	// PrefetchTopN 推荐成功后在后台预取详情的菜谱数，0 表示不预取
+	PrefetchTopN int
+	// PrefetchWorkers 后台预取的并发数
+	PrefetchWorkers int
+
+	// CORSAllowedOrigins 允许跨域访问的来源，包含 "*" 时允许任意来源
+	CORSAllowedOrigins []string
+
+	// AuthSecret 会话令牌签名密钥，为空时启动时随机生成（重启后已签发的令牌失效）
+	AuthSecret string
+	// AuthTokenTTL 会话令牌有效期
+	AuthTokenTTL time.Duration
+	// AuthRequireImage 图片生成是否仅限登录用户
+	AuthRequireImage bool
+	// AuthRequirePDF PDF 导出是否仅限登录用户
+	AuthRequirePDF bool
+	// AdminUsers 管理员用户名，可使用缓存管理等管理接口
+	AdminUsers []string
+
+	// EmbeddingProvider 菜谱嵌入服务：openai、dashscope、ollama、hash（本地哈希，离线可用）、
+	// none（关闭），为空时按 OpenAI、DashScope、Ollama 的顺序选择已配置的服务，都未配置时使用 hash
+	EmbeddingProvider string
+	// EmbeddingModel 嵌入模型，为空时使用所选服务的默认模型
+	EmbeddingModel string
+	// EmbeddingDimensions hash 嵌入的向量维度
+	EmbeddingDimensions int
+	// EmbeddingDuplicateThreshold 余弦相似度达到该值的两道菜谱视为近似重复
+	EmbeddingDuplicateThreshold float64
 }
 
 // Load loads configuration from environment variables
@@ -52,6 +89,31 @@ func Load() *Config {
 
 		// Cache configuration
 		Cache: loadCacheConfig(),
+
+		// Persistent store
+		StorePath:         getEnv("STORE_SQLITE_PATH", "./data/app.db"),
+		RecommendationTTL: time.Duration(getEnvInt("RECOMMENDATION_TTL_DAYS", 30)) * 24 * time.Hour,
+
+		// LLM queue and background prefetch
+		LLMConcurrency:  getEnvInt("LLM_CONCURRENCY", 4),
+		PrefetchTopN:    getEnvInt("PREFETCH_TOP_N", 2),
+		PrefetchWorkers: getEnvInt("PREFETCH_WORKERS", 2),
+
+		// CORS
+		CORSAllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", []string{"*"}),
+
+		// Accounts and authentication
+		AuthSecret:       os.Getenv("AUTH_SECRET"),
+		AuthTokenTTL:     time.Duration(getEnvInt("AUTH_TOKEN_TTL_HOURS", 168)) * time.Hour,
+		AuthRequireImage: getEnvBool("AUTH_REQUIRE_IMAGE", false),
+		AuthRequirePDF:   getEnvBool("AUTH_REQUIRE_PDF", false),
+		AdminUsers:       getEnvList("ADMIN_USERS", nil),
+
+		// Recipe embeddings
+		EmbeddingProvider:           strings.ToLower(os.Getenv("EMBEDDING_PROVIDER")),
+		EmbeddingModel:              os.Getenv("EMBEDDING_MODEL"),
+		EmbeddingDimensions:         getEnvInt("EMBEDDING_DIMENSIONS", 256),
+		EmbeddingDuplicateThreshold: getEnvFloat("EMBEDDING_DUPLICATE_THRESHOLD", 0.92),
 	}
 }
 
@@ -73,6 +135,40 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvBool gets a boolean environment variable with a default fallback
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a floating-point environment variable with a default fallback
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma-separated environment variable with a default fallback
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
 // loadCacheConfig 加载缓存配置
 func loadCacheConfig() models.CacheConfig {
 	defaults := models.DefaultCacheConfig
@@ -80,10 +176,44 @@ func loadCacheConfig() models.CacheConfig {
 	return models.CacheConfig{
 		MemoryTTL:           time.Duration(getEnvInt("CACHE_MEMORY_TTL", int(defaults.MemoryTTL/time.Second))) * time.Second,
 		MemoryMaxItems:      getEnvInt("CACHE_MEMORY_MAX_ITEMS", defaults.MemoryMaxItems),
+		Backend:             strings.ToLower(getEnv("CACHE_BACKEND", defaults.Backend)),
 		SQLiteTTL:           time.Duration(getEnvInt("CACHE_SQLITE_TTL", int(defaults.SQLiteTTL/time.Second))) * time.Second,
 		SQLiteCleanInterval: time.Duration(getEnvInt("CACHE_SQLITE_CLEAN_INTERVAL", int(defaults.SQLiteCleanInterval/time.Second))) * time.Second,
 		SQLitePath:          getEnv("CACHE_SQLITE_PATH", defaults.SQLitePath),
+		RedisURL:            getEnv("CACHE_REDIS_URL", defaults.RedisURL),
+		RedisPrefix:         getEnv("CACHE_REDIS_PREFIX", defaults.RedisPrefix),
+		Policies:            getEnvPolicies("CACHE_TTL_POLICIES"),
+	}
+}
+
+// getEnvPolicies parses per-namespace cache policies given as a comma-separated list of
+// namespace=soft/hard in seconds (e.g. "recipes=86400/604800"); a single number sets
+// both TTLs. Invalid entries are ignored.
+func getEnvPolicies(key string) map[string]models.CachePolicy {
+	policies := make(map[string]models.CachePolicy)
+	for _, item := range getEnvList(key, nil) {
+		namespace, ttls, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		softValue, hardValue, ok := strings.Cut(ttls, "/")
+		if !ok {
+			hardValue = softValue
+		}
+		soft, err := strconv.Atoi(strings.TrimSpace(softValue))
+		if err != nil || soft <= 0 {
+			continue
+		}
+		hard, err := strconv.Atoi(strings.TrimSpace(hardValue))
+		if err != nil || hard < soft {
+			continue
+		}
+		policies[strings.TrimSpace(namespace)] = models.CachePolicy{
+			SoftTTL: time.Duration(soft) * time.Second,
+			HardTTL: time.Duration(hard) * time.Second,
+		}
 	}
+	return policies
 }
 
 // HasOpenAI returns true if OpenAI API key is configured